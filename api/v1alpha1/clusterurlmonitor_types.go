@@ -44,6 +44,39 @@ type ClusterUrlMonitorSpec struct {
 	// SkipPrometheusRule instructs the controller to skip the creation of PrometheusRule CRs.
 	// One common use-case for is for alerts that are defined separately, such as for hosted clusters.
 	SkipPrometheusRule bool `json:"skipPrometheusRule"`
+
+	// +kubebuilder:validation:Optional
+
+	// Alerting customizes the labels and annotations of the alerts generated for the SLO
+	Alerting AlertingSpec `json:"alerting,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// CertificateExpiry generates alerts on the expiry of the TLS certificates served at the URL, next to the SLO alerts
+	CertificateExpiry *CertificateExpirySpec `json:"certificateExpiry,omitempty"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+
+	// ProxyURL is set on the endpoint of the generated ServiceMonitor, so that the probes of the blackbox exporter are
+	// scraped through the given proxy
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Probe customizes the blackbox exporter module probing the URL
+	Probe ProbeSpec `json:"probe,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// ExporterRef is the name of the ProbeExporter whose blackbox exporter probes the monitored URL.
+	// When empty, the operator's blackbox exporter is used
+	ExporterRef string `json:"exporterRef,omitempty"`
+}
+
+// URL returns the URL probed for the spec within the given cluster domain
+func (s ClusterUrlMonitorSpec) URL(clusterDomain string) string {
+	return s.Prefix + clusterDomain + ":" + s.Port + s.Suffix
 }
 
 // ClusterDomainRef defines the object used determine the cluster's domain
@@ -62,9 +95,19 @@ var (
 type ClusterUrlMonitorStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// ClusterURL is the url resolved from the cluster's domain
+	ClusterURL        string         `json:"clusterURL,omitempty"`
 	ServiceMonitorRef NamespacedName `json:"serviceMonitorRef,omitempty"`
-	PrometheusRuleRef NamespacedName `json:"prometheusRuleRef,omitempty"`
-	ErrorStatus       string         `json:"errorStatus,omitempty"`
+	// ServiceMonitorType is the API group of the ServiceMonitor referenced by ServiceMonitorRef
+	ServiceMonitorType string         `json:"serviceMonitorType,omitempty"`
+	PrometheusRuleRef  NamespacedName `json:"prometheusRuleRef,omitempty"`
+	// PrometheusRuleType is the API group of the PrometheusRule referenced by PrometheusRuleRef
+	PrometheusRuleType string         `json:"prometheusRuleType,omitempty"`
+	DashboardRef       NamespacedName `json:"dashboardRef,omitempty"`
+	ErrorStatus        string         `json:"errorStatus,omitempty"`
+	// SLO is the service level observed by Prometheus, only reported when an SLO query URL is configured
+	SLO *SloStatus `json:"slo,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -1,6 +1,10 @@
 package v1alpha1
 
-import "gopkg.in/inf.v0"
+import (
+	"gopkg.in/inf.v0"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // NamespacedName contains the name of a object and its namespace
 type NamespacedName struct {
@@ -14,6 +18,202 @@ type SloSpec struct {
 	TargetAvailabilityPercent string `json:"targetAvailabilityPercent"`
 }
 
+// AlertingSpec customizes the alerts generated for a monitor's SLO
+type AlertingSpec struct {
+	// +kubebuilder:validation:Optional
+
+	// Labels are added to the generated alerts and can be used to route them.
+	// Values are Go templates which can refer to the monitor's [[ .URL ]], [[ .Namespace ]] and [[ .Name ]]
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Annotations are added to the generated alerts, overriding the operator's defaults (e.g. runbook_url, summary, description).
+	// Values are Go templates which can refer to the monitor's [[ .URL ]], [[ .Namespace ]] and [[ .Name ]].
+	// Prometheus templates such as {{ $value }} are left for Prometheus to expand
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// GuardExpressions are PromQL expressions ANDed into every generated burn rate alert, so that the alerts only fire
+	// while all of them return a result, e.g. count(console_url{url="[[ .URL | trimSuffix "/health" ]]"} == 1) > 0.
+	// They are Go templates like Labels and Annotations. Monitors named console default to the expression above
+	GuardExpressions []string `json:"guardExpressions,omitempty"`
+}
+
+// CertificateExpirySpec defines the thresholds of the alerts on the expiry of the monitored URL's TLS certificates
+// +kubebuilder:validation:XValidation:rule="self.warningDays == 0 || self.criticalDays == 0 || self.criticalDays < self.warningDays",message="criticalDays must be lower than warningDays"
+type CertificateExpirySpec struct {
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:default:=30
+
+	// WarningDays is the number of days before the earliest certificate of the chain expires at which a warning alert fires.
+	// 0 disables the warning alert
+	WarningDays int32 `json:"warningDays"`
+
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Minimum:=0
+	// +kubebuilder:default:=7
+
+	// CriticalDays is the number of days before the earliest certificate of the chain expires at which a critical alert fires.
+	// 0 disables the critical alert
+	CriticalDays int32 `json:"criticalDays"`
+}
+
+// ProbeSpec customizes how the blackbox exporter probes a monitor's URL
+type ProbeSpec struct {
+	// +kubebuilder:validation:Optional
+
+	// Auth references the credentials sent with every probe, e.g. for URLs behind an OAuth proxy or basic auth
+	Auth *ProbeAuthSpec `json:"auth,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// TLS configures how the certificate of the probed URL is verified, and the client certificate presented to it
+	TLS *ProbeTLSSpec `json:"tls,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Expect defines the responses the probes succeed on, e.g. for URLs which return 200 with an error page when their backend is down
+	Expect *ProbeExpectSpec `json:"expect,omitempty"`
+}
+
+// ProbeExpectSpec defines the assertions on the responses of a probe. Regular expressions use the RE2 syntax of Go
+type ProbeExpectSpec struct {
+	// +kubebuilder:validation:Optional
+	// +listType=set
+	// +kubebuilder:validation:items:Minimum:=100
+	// +kubebuilder:validation:items:Maximum:=599
+
+	// StatusCodes are the status codes the probes succeed with. Any 2xx status code is accepted if it is empty
+	StatusCodes []int32 `json:"statusCodes,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// BodyRegex is a regular expression the response body has to match
+	BodyRegex string `json:"bodyRegex,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Headers are regular expressions the response headers have to match
+	Headers []HeaderExpectation `json:"headers,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// JSONPath compares a field of the JSON response body with a value
+	JSONPath *JSONPathExpectation `json:"jsonPath,omitempty"`
+}
+
+// HeaderExpectation is a regular expression a response header has to match
+type HeaderExpectation struct {
+	// +kubebuilder:validation:MinLength:=1
+
+	// Name is the name of the header
+	Name string `json:"name"`
+
+	// Regex is the regular expression the value of the header has to match
+	Regex string `json:"regex"`
+
+	// +kubebuilder:validation:Optional
+
+	// AllowMissing lets probes succeed on responses without the header
+	AllowMissing bool `json:"allowMissing,omitempty"`
+}
+
+// JSONPathExpectation compares a field of the JSON response body with a value
+type JSONPathExpectation struct {
+	// +kubebuilder:validation:MinLength:=1
+
+	// Path is a JSONPath of dot-separated field names and array indices, e.g. $.status or $.checks[0].state
+	Path string `json:"path"`
+
+	// Value is the JSON value the field has to equal, e.g. "ok", true or 3. Values which are not valid JSON are compared as strings
+	Value string `json:"value"`
+}
+
+// ProbeAuthSpec references the credentials of a probe in Secrets of the monitor's namespace.
+// The blackbox exporter reads them from files, so they never appear in the ServiceMonitor or the exporter's configuration
+// +kubebuilder:validation:XValidation:rule="has(self.bearerToken) != has(self.basicAuth)",message="exactly one of bearerToken and basicAuth must be set"
+type ProbeAuthSpec struct {
+	// +kubebuilder:validation:Optional
+
+	// BearerToken references the token sent in the Authorization header of the probes
+	BearerToken *corev1.SecretKeySelector `json:"bearerToken,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// BasicAuth references the username and password sent with the probes
+	BasicAuth *BasicAuthSpec `json:"basicAuth,omitempty"`
+}
+
+// BasicAuthSpec references the username and password of a probe
+type BasicAuthSpec struct {
+	// Username references the username in a Secret of the monitor's namespace
+	Username corev1.SecretKeySelector `json:"username"`
+	// Password references the password in a Secret of the monitor's namespace
+	Password corev1.SecretKeySelector `json:"password"`
+}
+
+// ProbeTLSSpec configures the TLS connections of a probe
+type ProbeTLSSpec struct {
+	// +kubebuilder:validation:Optional
+
+	// CA references the CA bundle the certificate of the probed URL is verified with, instead of the system's trusted CAs
+	CA *CABundleSpec `json:"ca,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// ClientCertificate references the certificate and key presented to the probed URL, e.g. for mutual TLS
+	ClientCertificate *ClientCertificateSpec `json:"clientCertificate,omitempty"`
+}
+
+// CABundleSpec references a PEM encoded CA bundle
+// +kubebuilder:validation:XValidation:rule="(has(self.configMap) ? 1 : 0) + (has(self.serviceCA) && self.serviceCA ? 1 : 0) + (has(self.trustedCABundle) && self.trustedCABundle ? 1 : 0) == 1",message="exactly one of configMap, serviceCA and trustedCABundle must be set"
+type CABundleSpec struct {
+	// +kubebuilder:validation:Optional
+
+	// ConfigMap references the CA bundle in a ConfigMap of the monitor's namespace
+	ConfigMap *corev1.ConfigMapKeySelector `json:"configMap,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// ServiceCA verifies the certificate with the service CA, which signs the serving certificates of Services
+	ServiceCA bool `json:"serviceCA,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// TrustedCABundle verifies the certificate with the cluster's trusted CA bundle, which includes the additional CAs
+	// of the cluster-wide proxy configuration
+	TrustedCABundle bool `json:"trustedCABundle,omitempty"`
+}
+
+// ClientCertificateSpec references the client certificate of a probe
+type ClientCertificateSpec struct {
+	// Certificate references the PEM encoded certificate in a Secret of the monitor's namespace
+	Certificate corev1.SecretKeySelector `json:"certificate"`
+	// Key references the PEM encoded private key in a Secret of the monitor's namespace
+	Key corev1.SecretKeySelector `json:"key"`
+}
+
+// SloStatus reports the service level observed by Prometheus for the probed URL
+type SloStatus struct {
+	// Window is the period over which Availability and ErrorBudgetRemaining are calculated
+	Window string `json:"window,omitempty"`
+	// AvailabilityPercent is the percentage of successful probes over the SLO window
+	AvailabilityPercent string `json:"availabilityPercent,omitempty"`
+	// ErrorBudgetRemainingPercent is the percentage of the error budget not yet consumed over the SLO window.
+	// It is negative once the error budget has been exhausted
+	ErrorBudgetRemainingPercent string `json:"errorBudgetRemainingPercent,omitempty"`
+	// BurnRate is the rate at which the error budget was consumed over the last hour,
+	// where 1 consumes exactly the whole error budget over the SLO window
+	BurnRate string `json:"burnRate,omitempty"`
+	// LastProbeSuccess reports whether the most recent probe succeeded
+	LastProbeSuccess *bool `json:"lastProbeSuccess,omitempty"`
+	// LastUpdateTime is the last time the SLO status was queried from Prometheus
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+}
+
 func (s SloSpec) IsValid() (bool, string) {
 	if s.TargetAvailabilityPercent == "" {
 		return false, ""
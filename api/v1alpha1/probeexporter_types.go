@@ -0,0 +1,85 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProbeExporterSpec defines a blackbox exporter deployment in addition to the operator's one
+type ProbeExporterSpec struct {
+	// +kubebuilder:validation:MinLength=1
+
+	// Namespace is the existing namespace the exporter's Deployment, Service and ConfigMap are created in
+	Namespace string `json:"namespace"`
+
+	// +kubebuilder:validation:Optional
+
+	// NodeSelector constrains the exporter pods to nodes with these labels
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Tolerations allow the exporter pods to be scheduled on tainted nodes
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Affinity defines the scheduling constraints of the exporter pods
+	Affinity *corev1.Affinity `json:"affinity,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Egress restricts the traffic of the exporter pods to these rules by a NetworkPolicy.
+	// DNS lookups need to be allowed explicitly. When empty, egress traffic is not restricted
+	Egress []networkingv1.NetworkPolicyEgressRule `json:"egress,omitempty"`
+}
+
+// ProbeExporterStatus defines the observed state of ProbeExporter
+type ProbeExporterStatus struct {
+	// ServiceRef is the Service of the exporter which the ServiceMonitors of monitors selecting it scrape
+	ServiceRef  NamespacedName `json:"serviceRef,omitempty"`
+	ErrorStatus string         `json:"errorStatus,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+
+// ProbeExporter is the Schema for the probeexporters API
+type ProbeExporter struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ProbeExporterSpec   `json:"spec,omitempty"`
+	Status ProbeExporterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ProbeExporterList contains a list of ProbeExporter
+type ProbeExporterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ProbeExporter `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ProbeExporter{}, &ProbeExporterList{})
+}
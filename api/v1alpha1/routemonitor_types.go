@@ -17,6 +17,8 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"fmt"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -32,6 +34,16 @@ type RouteMonitorSpec struct {
 	// One common use-case for is for alerts that are defined separately, such as for hosted clusters.
 	SkipPrometheusRule bool `json:"skipPrometheusRule"`
 
+	// +kubebuilder:validation:Optional
+
+	// Alerting customizes the labels and annotations of the alerts generated for the SLO
+	Alerting AlertingSpec `json:"alerting,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// CertificateExpiry generates alerts on the expiry of the TLS certificates served at the URL, next to the SLO alerts
+	CertificateExpiry *CertificateExpirySpec `json:"certificateExpiry,omitempty"`
+
 	// +kubebuilder:default:false
 	// +kubebuilder:validation:Optional
 
@@ -39,12 +51,30 @@ type RouteMonitorSpec struct {
 	// should *not* use https
 	InsecureSkipTLSVerify bool `json:"insecureSkipTLSVerify"`
 
+	// +kubebuilder:validation:Optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+
+	// ProxyURL is set on the endpoint of the generated ServiceMonitor, so that the probes of the blackbox exporter are
+	// scraped through the given proxy
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// Probe customizes the blackbox exporter module probing the route
+	Probe ProbeSpec `json:"probe,omitempty"`
+
 	// +kubebuilder:validation:Optional
 	// +kubebuilder:validation:Enum=monitoring.coreos.com;monitoring.rhobs
 	// +kubebuilder:default=monitoring.coreos.com
 
 	// ServiceMonitorType dictates the type of ServiceMonitor the RouteMonitor should create
 	ServiceMonitorType string `json:"serviceMonitorType,omitempty"`
+
+	// +kubebuilder:validation:Optional
+
+	// ExporterRef is the name of the ProbeExporter whose blackbox exporter probes the monitored URL.
+	// When empty, the operator's blackbox exporter is used
+	ExporterRef string `json:"exporterRef,omitempty"`
 }
 
 const (
@@ -72,13 +102,33 @@ type RouteMonitorRouteSpec struct {
 	Suffix string `json:"suffix,omitempty"`
 }
 
+// URL returns the URL probed for the spec, given the host of the Route's first ingress and whether the Route has TLS
+func (s RouteMonitorRouteSpec) URL(host string, tls bool) string {
+	routeURL := host
+	if s.Port != 0 {
+		routeURL = fmt.Sprintf("%s:%d", routeURL, s.Port)
+	}
+	routeURL += s.Suffix
+	if tls {
+		routeURL = "https://" + routeURL
+	}
+	return routeURL
+}
+
 // RouteMonitorStatus defines the observed state of RouteMonitor
 type RouteMonitorStatus struct {
 	// RouteURL is the url extracted from the Route resource
 	RouteURL          string         `json:"routeURL,omitempty"`
 	ServiceMonitorRef NamespacedName `json:"serviceMonitorRef,omitempty"`
-	PrometheusRuleRef NamespacedName `json:"prometheusRuleRef,omitempty"`
-	ErrorStatus       string         `json:"errorStatus,omitempty"`
+	// ServiceMonitorType is the API group of the ServiceMonitor referenced by ServiceMonitorRef
+	ServiceMonitorType string         `json:"serviceMonitorType,omitempty"`
+	PrometheusRuleRef  NamespacedName `json:"prometheusRuleRef,omitempty"`
+	// PrometheusRuleType is the API group of the PrometheusRule referenced by PrometheusRuleRef
+	PrometheusRuleType string         `json:"prometheusRuleType,omitempty"`
+	DashboardRef       NamespacedName `json:"dashboardRef,omitempty"`
+	ErrorStatus        string         `json:"errorStatus,omitempty"`
+	// SLO is the service level observed by Prometheus, only reported when an SLO query URL is configured
+	SLO *SloStatus `json:"slo,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -21,16 +21,121 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AlertingSpec) DeepCopyInto(out *AlertingSpec) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.GuardExpressions != nil {
+		in, out := &in.GuardExpressions, &out.GuardExpressions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AlertingSpec.
+func (in *AlertingSpec) DeepCopy() *AlertingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AlertingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BasicAuthSpec) DeepCopyInto(out *BasicAuthSpec) {
+	*out = *in
+	in.Username.DeepCopyInto(&out.Username)
+	in.Password.DeepCopyInto(&out.Password)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BasicAuthSpec.
+func (in *BasicAuthSpec) DeepCopy() *BasicAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(BasicAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleSpec) DeepCopyInto(out *CABundleSpec) {
+	*out = *in
+	if in.ConfigMap != nil {
+		in, out := &in.ConfigMap, &out.ConfigMap
+		*out = new(v1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSpec.
+func (in *CABundleSpec) DeepCopy() *CABundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateExpirySpec) DeepCopyInto(out *CertificateExpirySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateExpirySpec.
+func (in *CertificateExpirySpec) DeepCopy() *CertificateExpirySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateExpirySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientCertificateSpec) DeepCopyInto(out *ClientCertificateSpec) {
+	*out = *in
+	in.Certificate.DeepCopyInto(&out.Certificate)
+	in.Key.DeepCopyInto(&out.Key)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientCertificateSpec.
+func (in *ClientCertificateSpec) DeepCopy() *ClientCertificateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientCertificateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterUrlMonitor) DeepCopyInto(out *ClusterUrlMonitor) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUrlMonitor.
@@ -87,6 +192,13 @@ func (in *ClusterUrlMonitorList) DeepCopyObject() runtime.Object {
 func (in *ClusterUrlMonitorSpec) DeepCopyInto(out *ClusterUrlMonitorSpec) {
 	*out = *in
 	out.Slo = in.Slo
+	in.Alerting.DeepCopyInto(&out.Alerting)
+	if in.CertificateExpiry != nil {
+		in, out := &in.CertificateExpiry, &out.CertificateExpiry
+		*out = new(CertificateExpirySpec)
+		**out = **in
+	}
+	in.Probe.DeepCopyInto(&out.Probe)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUrlMonitorSpec.
@@ -104,6 +216,12 @@ func (in *ClusterUrlMonitorStatus) DeepCopyInto(out *ClusterUrlMonitorStatus) {
 	*out = *in
 	out.ServiceMonitorRef = in.ServiceMonitorRef
 	out.PrometheusRuleRef = in.PrometheusRuleRef
+	out.DashboardRef = in.DashboardRef
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(SloStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterUrlMonitorStatus.
@@ -116,6 +234,36 @@ func (in *ClusterUrlMonitorStatus) DeepCopy() *ClusterUrlMonitorStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HeaderExpectation) DeepCopyInto(out *HeaderExpectation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HeaderExpectation.
+func (in *HeaderExpectation) DeepCopy() *HeaderExpectation {
+	if in == nil {
+		return nil
+	}
+	out := new(HeaderExpectation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JSONPathExpectation) DeepCopyInto(out *JSONPathExpectation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JSONPathExpectation.
+func (in *JSONPathExpectation) DeepCopy() *JSONPathExpectation {
+	if in == nil {
+		return nil
+	}
+	out := new(JSONPathExpectation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NamespacedName) DeepCopyInto(out *NamespacedName) {
 	*out = *in
@@ -132,14 +280,240 @@ func (in *NamespacedName) DeepCopy() *NamespacedName {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RouteMonitor) DeepCopyInto(out *RouteMonitor) {
+func (in *ProbeAuthSpec) DeepCopyInto(out *ProbeAuthSpec) {
+	*out = *in
+	if in.BearerToken != nil {
+		in, out := &in.BearerToken, &out.BearerToken
+		*out = new(v1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(BasicAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeAuthSpec.
+func (in *ProbeAuthSpec) DeepCopy() *ProbeAuthSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeAuthSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeExpectSpec) DeepCopyInto(out *ProbeExpectSpec) {
+	*out = *in
+	if in.StatusCodes != nil {
+		in, out := &in.StatusCodes, &out.StatusCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make([]HeaderExpectation, len(*in))
+		copy(*out, *in)
+	}
+	if in.JSONPath != nil {
+		in, out := &in.JSONPath, &out.JSONPath
+		*out = new(JSONPathExpectation)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeExpectSpec.
+func (in *ProbeExpectSpec) DeepCopy() *ProbeExpectSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeExpectSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeExporter) DeepCopyInto(out *ProbeExporter) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeExporter.
+func (in *ProbeExporter) DeepCopy() *ProbeExporter {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeExporter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProbeExporter) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeExporterList) DeepCopyInto(out *ProbeExporterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ProbeExporter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeExporterList.
+func (in *ProbeExporterList) DeepCopy() *ProbeExporterList {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeExporterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ProbeExporterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeExporterSpec) DeepCopyInto(out *ProbeExporterSpec) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(v1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Egress != nil {
+		in, out := &in.Egress, &out.Egress
+		*out = make([]networkingv1.NetworkPolicyEgressRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeExporterSpec.
+func (in *ProbeExporterSpec) DeepCopy() *ProbeExporterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeExporterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeExporterStatus) DeepCopyInto(out *ProbeExporterStatus) {
+	*out = *in
+	out.ServiceRef = in.ServiceRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeExporterStatus.
+func (in *ProbeExporterStatus) DeepCopy() *ProbeExporterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeExporterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeSpec) DeepCopyInto(out *ProbeSpec) {
+	*out = *in
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(ProbeAuthSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ProbeTLSSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Expect != nil {
+		in, out := &in.Expect, &out.Expect
+		*out = new(ProbeExpectSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeSpec.
+func (in *ProbeSpec) DeepCopy() *ProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeTLSSpec) DeepCopyInto(out *ProbeTLSSpec) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(CABundleSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ClientCertificate != nil {
+		in, out := &in.ClientCertificate, &out.ClientCertificate
+		*out = new(ClientCertificateSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeTLSSpec.
+func (in *ProbeTLSSpec) DeepCopy() *ProbeTLSSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeTLSSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RouteMonitor) DeepCopyInto(out *RouteMonitor) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMonitor.
 func (in *RouteMonitor) DeepCopy() *RouteMonitor {
 	if in == nil {
@@ -210,6 +584,13 @@ func (in *RouteMonitorSpec) DeepCopyInto(out *RouteMonitorSpec) {
 	*out = *in
 	out.Route = in.Route
 	out.Slo = in.Slo
+	in.Alerting.DeepCopyInto(&out.Alerting)
+	if in.CertificateExpiry != nil {
+		in, out := &in.CertificateExpiry, &out.CertificateExpiry
+		*out = new(CertificateExpirySpec)
+		**out = **in
+	}
+	in.Probe.DeepCopyInto(&out.Probe)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMonitorSpec.
@@ -227,6 +608,12 @@ func (in *RouteMonitorStatus) DeepCopyInto(out *RouteMonitorStatus) {
 	*out = *in
 	out.ServiceMonitorRef = in.ServiceMonitorRef
 	out.PrometheusRuleRef = in.PrometheusRuleRef
+	out.DashboardRef = in.DashboardRef
+	if in.SLO != nil {
+		in, out := &in.SLO, &out.SLO
+		*out = new(SloStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RouteMonitorStatus.
@@ -253,3 +640,24 @@ func (in *SloSpec) DeepCopy() *SloSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SloStatus) DeepCopyInto(out *SloStatus) {
+	*out = *in
+	if in.LastProbeSuccess != nil {
+		in, out := &in.LastProbeSuccess, &out.LastProbeSuccess
+		*out = new(bool)
+		**out = **in
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SloStatus.
+func (in *SloStatus) DeepCopy() *SloStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SloStatus)
+	in.DeepCopyInto(out)
+	return out
+}
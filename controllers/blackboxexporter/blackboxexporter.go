@@ -0,0 +1,159 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blackboxexporter
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+	consts "github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
+	"github.com/openshift/route-monitor-operator/pkg/util"
+	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
+)
+
+// controllerName identifies the BlackBoxExporterReconciler in logs and metrics
+const controllerName = "BlackBoxExporter"
+
+// credentialsResyncInterval is the wait period between reconciles while monitors probe with credentials.
+// The Secrets and ConfigMaps referenced by the monitors are not watched, so rotated credentials and CA bundles are
+// only copied on resync
+const credentialsResyncInterval = 10 * time.Minute
+
+// BlackBoxExporterReconciler deploys the operator's blackbox exporter while RouteMonitors or ClusterUrlMonitors
+// use it, and removes it once the last of them is deleted. All events are mapped to a single request, so that
+// the exporter is never created and deleted concurrently
+type BlackBoxExporterReconciler struct {
+	Client           client.Client
+	Ctx              context.Context
+	Log              logr.Logger
+	Scheme           *runtime.Scheme
+	BlackBoxExporter *blackboxexporter.BlackBoxExporter
+}
+
+func NewReconciler(mgr manager.Manager, blackboxExporterImage, blackboxExporterNamespace string) *BlackBoxExporterReconciler {
+	log := ctrl.Log.WithName("controllers").WithName(controllerName)
+	client := mgr.GetClient()
+	ctx := context.Background()
+	exporter := blackboxexporter.New(client, log, ctx, blackboxExporterImage, blackboxExporterNamespace)
+	// the Secrets and ConfigMaps of the monitors are read without the manager's cache, which is restricted to the operator's namespace
+	// on non management clusters
+	exporter.References = mgr.GetAPIReader()
+	return &BlackBoxExporterReconciler{
+		Client:           client,
+		Ctx:              ctx,
+		Log:              log,
+		Scheme:           mgr.GetScheme(),
+		BlackBoxExporter: exporter,
+	}
+}
+
+// The exporter's Service, ConfigMaps, Deployment and credentials Secret are only written to its configurable namespace.
+// The ClusterRole grants this cluster-wide as the ProbeExporter controller writes them to any namespace.
+// +kubebuilder:rbac:groups=monitoring.openshift.io,resources=routemonitors,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.openshift.io,resources=clusterurlmonitors,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
+
+func (r *BlackBoxExporterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.Ctx = ctx
+	r.BlackBoxExporter.Ctx = ctx
+	log := r.Log.WithName("Reconcile").WithValues("name", req.Name, "namespace", req.Namespace)
+
+	log.V(2).Info("Entering CountDependentMonitors")
+	count, err := r.BlackBoxExporter.CountDependentMonitors()
+	if err != nil {
+		log.Error(err, "Failed to count the monitors using the BlackBoxExporter. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "CountDependentMonitors")
+		return utilreconcile.RequeueWith(err)
+	}
+
+	if count == 0 {
+		log.V(2).Info("Entering EnsureBlackBoxExporterResourcesAbsent")
+		if err := r.BlackBoxExporter.EnsureBlackBoxExporterResourcesAbsent(); err != nil {
+			log.Error(err, "Failed to delete BlackBoxExporter. Requeueing...")
+			metrics.RecordReconcileStepFailure(controllerName, "EnsureBlackBoxExporterResourcesAbsent")
+			return utilreconcile.RequeueWith(err)
+		}
+		log.Info("No monitors use the BlackBoxExporter anymore. Finished reconcile.")
+		return utilreconcile.Stop()
+	}
+
+	log.V(2).Info("Entering EnsureBlackBoxExporterResourcesExist")
+	if err := r.BlackBoxExporter.EnsureBlackBoxExporterResourcesExist(); err != nil {
+		log.Error(err, "Failed to create BlackBoxExporter. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureBlackBoxExporterResourcesExist")
+		return utilreconcile.RequeueWith(err)
+	}
+	log.Info("BlackBoxExporter is in place.", "monitors", count)
+	if r.BlackBoxExporter.HasCredentials() {
+		return utilreconcile.RequeueAfter(credentialsResyncInterval), nil
+	}
+	return utilreconcile.Stop()
+}
+
+func (r *BlackBoxExporterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := blackboxexporter.IndexExporterRef(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+
+	exporter := r.BlackBoxExporter.NamespacedName
+	enqueueExporter := handler.EnqueueRequestsFromMapFunc(func(context.Context, client.Object) []reconcile.Request {
+		return []reconcile.Request{{NamespacedName: exporter}}
+	})
+	// only the deletion of the exporter's resources or changes to their spec need to be reverted
+	isExporterResource := predicate.And(
+		predicate.NewPredicateFuncs(func(o client.Object) bool {
+			return types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()} == exporter
+		}),
+		predicate.GenerationChangedPredicate{},
+	)
+	credentials := types.NamespacedName{Name: consts.CredentialsResourceName(exporter.Name), Namespace: exporter.Namespace}
+	isExporterCredentials := predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return types.NamespacedName{Name: o.GetName(), Namespace: o.GetNamespace()} == credentials
+	})
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(controllerName).
+		Watches(&v1alpha1.RouteMonitor{}, enqueueExporter, builder.WithPredicates(blackboxexporter.MonitorExporterChanged)).
+		Watches(&v1alpha1.ClusterUrlMonitor{}, enqueueExporter, builder.WithPredicates(blackboxexporter.MonitorExporterChanged)).
+		Watches(&appsv1.Deployment{}, enqueueExporter, builder.WithPredicates(isExporterResource)).
+		Watches(&corev1.Service{}, enqueueExporter, builder.WithPredicates(isExporterResource)).
+		Watches(&corev1.ConfigMap{}, enqueueExporter, builder.WithPredicates(isExporterResource)).
+		Watches(&corev1.Secret{}, enqueueExporter, builder.WithPredicates(isExporterCredentials)).
+		// the exporter is rolled out with the new proxy settings
+		Watches(&configv1.Proxy{}, enqueueExporter, builder.WithPredicates(util.ClusterProxyChanged)).
+		Complete(r)
+}
@@ -0,0 +1,107 @@
+package blackboxexporter_test
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	operatorv1 "github.com/openshift/api/operator/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	controller "github.com/openshift/route-monitor-operator/controllers/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+)
+
+// builderIndexer registers indexes with a fake client
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (i builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	i.builder.WithIndex(obj, field, extractValue)
+	return nil
+}
+
+var _ = Describe("BlackBoxExporterReconciler", func() {
+	var (
+		c          client.Client
+		reconciler *controller.BlackBoxExporterReconciler
+		objects    []client.Object
+		exporter   = types.NamespacedName{Name: "blackbox-exporter", Namespace: "openshift-route-monitor-operator"}
+		err        error
+	)
+	BeforeEach(func() {
+		objects = []client.Object{&operatorv1.IngressController{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "openshift-ingress-operator"},
+			Status: operatorv1.IngressControllerStatus{
+				EndpointPublishingStrategy: &operatorv1.EndpointPublishingStrategy{
+					LoadBalancer: &operatorv1.LoadBalancerStrategy{
+						ProviderParameters: &operatorv1.ProviderLoadBalancerParameters{
+							AWS: &operatorv1.AWSLoadBalancerParameters{},
+						},
+					},
+				},
+			},
+		}}
+	})
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(operatorv1.AddToScheme(scheme)).To(Succeed())
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...)
+		Expect(blackboxexporter.IndexExporterRef(context.Background(), builderIndexer{builder})).To(Succeed())
+		c = builder.Build()
+		reconciler = &controller.BlackBoxExporterReconciler{
+			Client:           c,
+			Ctx:              context.Background(),
+			Log:              logr.Discard(),
+			Scheme:           scheme,
+			BlackBoxExporter: blackboxexporter.New(c, logr.Discard(), context.Background(), "test-image", exporter.Namespace),
+		}
+		_, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: exporter})
+	})
+
+	When("a monitor uses the operator's blackbox exporter", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.RouteMonitor{ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"}})
+		})
+		It("should deploy the blackbox exporter", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(context.Background(), exporter, &appsv1.Deployment{})).To(Succeed())
+		})
+	})
+
+	When("the last monitor using the operator's blackbox exporter is being deleted", func() {
+		BeforeEach(func() {
+			objects = append(objects,
+				&v1alpha1.ClusterUrlMonitor{ObjectMeta: metav1.ObjectMeta{
+					Name: "api", Namespace: "openshift-route-monitor-operator", DeletionTimestamp: &metav1.Time{Time: time.Unix(0, 0)}, Finalizers: []string{"test"},
+				}},
+				&v1alpha1.RouteMonitor{
+					ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"},
+					Spec:       v1alpha1.RouteMonitorSpec{ExporterRef: "zone-a"},
+				},
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: exporter.Name, Namespace: exporter.Namespace}},
+			)
+		})
+		It("should delete the blackbox exporter", func() {
+			Expect(err).NotTo(HaveOccurred())
+			err := c.Get(context.Background(), exporter, &appsv1.Deployment{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+})
@@ -20,21 +20,37 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
 	monitoringv1alpha1 "github.com/openshift/route-monitor-operator/api/v1alpha1"
 	"github.com/openshift/route-monitor-operator/controllers"
 	"github.com/openshift/route-monitor-operator/pkg/alert"
 	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/dashboard"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
 	reconcileCommon "github.com/openshift/route-monitor-operator/pkg/reconcile"
 	"github.com/openshift/route-monitor-operator/pkg/servicemonitor"
 	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// controllerName identifies the ClusterUrlMonitorReconciler in logs and metrics
+const controllerName = "ClusterUrlMonitor"
+
+// eventRecorderName is the source of the Events emitted on ClusterUrlMonitors
+const eventRecorderName = "clusterurlmonitor-controller"
+
 // ClusterUrlMonitorReconciler reconciles a ClusterUrlMonitor object
 type ClusterUrlMonitorReconciler struct {
 	Client client.Client
@@ -45,22 +61,38 @@ type ClusterUrlMonitorReconciler struct {
 	BlackBoxExporter controllers.BlackBoxExporterHandler
 	ServiceMonitor   controllers.ServiceMonitorHandler
 	Prom             controllers.PrometheusRuleHandler
+	Dashboard        controllers.DashboardHandler
 	Common           controllers.MonitorResourceHandler
+	Recorder         record.EventRecorder
+	SLO              controllers.SLOStatusHandler
+	AlertDefaults    alert.Defaults
+
+	// EnableHCP watches the HostedClusters and HostedControlPlanes the domains of HCP ClusterUrlMonitors are
+	// resolved from. Their CRDs only exist on management clusters
+	EnableHCP bool
 }
 
-func NewReconciler(mgr manager.Manager, blackboxExporterImage, blackboxExporterNamespace string, enablehypershift bool, probeAPIURL string) *ClusterUrlMonitorReconciler {
-	log := ctrl.Log.WithName("controllers").WithName("ClusterUrlMonitor")
+func NewReconciler(mgr manager.Manager, blackboxExporterImage, blackboxExporterNamespace string, enablehypershift bool, probeAPIURL string, sloStatus controllers.SLOStatusHandler, dashboardConfig dashboard.Config, alertDefaults alert.Defaults) *ClusterUrlMonitorReconciler {
+	log := ctrl.Log.WithName("controllers").WithName(controllerName)
 	client := mgr.GetClient()
 	ctx := context.Background()
+	exporter := blackboxexporter.New(client, log, ctx, blackboxExporterImage, blackboxExporterNamespace)
+	// the Secrets and ConfigMaps referenced by probe settings are read without the manager's cache, like the BlackBoxExporter
+	// controller does
+	exporter.References = mgr.GetAPIReader()
 	return &ClusterUrlMonitorReconciler{
 		Client:           client,
 		Ctx:              ctx,
 		Log:              log,
 		Scheme:           mgr.GetScheme(),
-		BlackBoxExporter: blackboxexporter.New(client, log, ctx, blackboxExporterImage, blackboxExporterNamespace),
+		BlackBoxExporter: exporter,
 		ServiceMonitor:   servicemonitor.NewServiceMonitor(ctx, client),
-		Prom:             alert.NewPrometheusRule(ctx, client),
+		Prom:             alert.NewPrometheusRule(ctx, client, mgr.GetAPIReader()),
+		Dashboard:        dashboard.NewDashboard(ctx, client, dashboardConfig),
 		Common:           reconcileCommon.NewMonitorResourceCommon(ctx, client),
+		Recorder:         mgr.GetEventRecorderFor(eventRecorderName),
+		SLO:              sloStatus,
+		AlertDefaults:    alertDefaults,
 	}
 }
 
@@ -71,9 +103,12 @@ const (
 )
 
 // +kubebuilder:rbac:groups=monitoring.openshift.io,resources=clusterurlmonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.openshift.io,resources=clusterurlmonitors/finalizers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.openshift.io,resources=clusterurlmonitors/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=config.openshift.io,resources=dnses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboards,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups=monitoring.rhobs,resources=prometheusrules,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get;list;watch
 // +kubebuilder:rbac:groups=operator.openshift.io,resources=ingresscontrollers,verbs=get;list;watch
@@ -97,6 +132,7 @@ func (r *ClusterUrlMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	res, err = r.EnsureMonitorAndDependenciesAbsent(clusterUrlMonitor)
 	if err != nil {
 		log.Error(err, "Failed to delete ClusterUrlMontior. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureMonitorAndDependenciesAbsent")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -108,6 +144,7 @@ func (r *ClusterUrlMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Re
 	res, err = r.EnsureFinalizerSet(clusterUrlMonitor)
 	if err != nil {
 		log.Error(err, "Failed to set ClusterUrlMonitor's Finalizer. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureFinalizerSet")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -115,17 +152,23 @@ func (r *ClusterUrlMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return utilreconcile.Stop()
 	}
 
-	log.V(2).Info("Entering EnsureBlackBoxExporterResourcesExist")
-	err = r.BlackBoxExporter.EnsureBlackBoxExporterResourcesExist()
+	log.V(2).Info("Entering EnsureClusterURLExists")
+	res, err = r.EnsureClusterURLExists(clusterUrlMonitor)
 	if err != nil {
-		log.Error(err, "Failed to create BlackBoxExporter. Requeueing...")
+		log.Error(err, "Failed to resolve the ClusterURL of ClusterUrlMonitor. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureClusterURLExists")
 		return utilreconcile.RequeueWith(err)
 	}
+	if res.ShouldStop() {
+		log.Info("Successfully patched ClusterUrlMonitor with ClusterURL. Stopping...")
+		return utilreconcile.Stop()
+	}
 
 	log.V(2).Info("Entering EnsureServiceMonitorExists")
 	res, err = r.EnsureServiceMonitorExists(clusterUrlMonitor)
 	if err != nil {
 		log.Error(err, "Failed to set ServiceMonitor. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureServiceMonitorExists")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -133,10 +176,23 @@ func (r *ClusterUrlMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return utilreconcile.Stop()
 	}
 
+	log.V(2).Info("Entering EnsureDashboardExists")
+	res, err = r.EnsureDashboardExists(clusterUrlMonitor)
+	if err != nil {
+		log.Error(err, "Failed to set dashboard. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureDashboardExists")
+		return utilreconcile.RequeueWith(err)
+	}
+	if res.ShouldStop() {
+		log.Info("Successfully patched ClusterUrlMonitor with DashboardRef. Stopping...")
+		return utilreconcile.Stop()
+	}
+
 	log.V(2).Info("Entering EnsurePrometheusRuleResourceExists")
 	res, err = r.EnsurePrometheusRuleExists(clusterUrlMonitor)
 	if err != nil {
 		log.Error(err, "Failed to set PrometheusRule. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsurePrometheusRuleExists")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -144,16 +200,171 @@ func (r *ClusterUrlMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		return utilreconcile.Stop()
 	}
 
+	log.V(2).Info("Entering EnsureSLOStatus")
+	res, err = r.EnsureSLOStatus(clusterUrlMonitor)
+	if err != nil {
+		log.Error(err, "Failed to update SLO status. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureSLOStatus")
+		return utilreconcile.RequeueWith(err)
+	}
+	if res.ShouldStop() {
+		log.Info("Successfully patched ClusterUrlMonitor with SLO status. Stopping...")
+		return utilreconcile.Stop()
+	}
+
 	log.Info("All operations for ClusterUrlMonitor completed. Finished Reconcile.")
+	if r.SLO != nil {
+		// periodically resync to keep the SLO status current
+		return utilreconcile.RequeueAfter(r.SLO.ResyncInterval()), nil
+	}
 	return utilreconcile.Stop()
 }
 
+// DomainRefIndex is the cache index of ClusterUrlMonitors by the kind of object their cluster domain is resolved from
+const DomainRefIndex = "spec.domainRef"
+
+// HostedClusterIndex is the cache index of HostedControlPlanes by the namespace/name of their HostedCluster
+const HostedClusterIndex = "hostedCluster"
+
+// IndexDomainRef registers the DomainRefIndex for ClusterUrlMonitors
+func IndexDomainRef(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &monitoringv1alpha1.ClusterUrlMonitor{}, DomainRefIndex, func(o client.Object) []string {
+		if o.(*monitoringv1alpha1.ClusterUrlMonitor).Spec.DomainRef == monitoringv1alpha1.ClusterDomainRefHCP {
+			return []string{string(monitoringv1alpha1.ClusterDomainRefHCP)}
+		}
+		return []string{string(monitoringv1alpha1.ClusterDomainRefInfra)}
+	})
+}
+
+// IndexHostedCluster registers the HostedClusterIndex for HostedControlPlanes
+func IndexHostedCluster(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &hypershiftv1beta1.HostedControlPlane{}, HostedClusterIndex, func(o client.Object) []string {
+		hostedCluster, ok := o.GetAnnotations()[hcpClusterAnnotation]
+		if !ok {
+			return nil
+		}
+		return []string{hostedCluster}
+	})
+}
+
+// ClusterUrlMonitorsForInfrastructure returns a request for each ClusterUrlMonitor resolving its domain from the Infrastructure
+func (r *ClusterUrlMonitorReconciler) ClusterUrlMonitorsForInfrastructure(ctx context.Context, infrastructure client.Object) []reconcile.Request {
+	if infrastructure.GetName() != "cluster" {
+		return nil
+	}
+	return r.clusterUrlMonitorRequests(ctx, client.MatchingFields{DomainRefIndex: string(monitoringv1alpha1.ClusterDomainRefInfra)})
+}
+
+// ClusterUrlMonitorsForHostedControlPlane returns a request for each ClusterUrlMonitor resolving its domain from the HostedControlPlane
+func (r *ClusterUrlMonitorReconciler) ClusterUrlMonitorsForHostedControlPlane(ctx context.Context, hcp client.Object) []reconcile.Request {
+	return r.clusterUrlMonitorRequests(ctx, client.InNamespace(hcp.GetNamespace()), client.MatchingFields{DomainRefIndex: string(monitoringv1alpha1.ClusterDomainRefHCP)})
+}
+
+// ClusterUrlMonitorsForHostedCluster returns a request for each ClusterUrlMonitor resolving its domain from the HostedCluster
+func (r *ClusterUrlMonitorReconciler) ClusterUrlMonitorsForHostedCluster(ctx context.Context, hostedCluster client.Object) []reconcile.Request {
+	hcpList := &hypershiftv1beta1.HostedControlPlaneList{}
+	if err := r.Client.List(ctx, hcpList, client.MatchingFields{HostedClusterIndex: hostedCluster.GetNamespace() + "/" + hostedCluster.GetName()}); err != nil {
+		r.Log.Error(err, "Failed to list the HostedControlPlanes of HostedCluster", "name", hostedCluster.GetName(), "namespace", hostedCluster.GetNamespace())
+		return nil
+	}
+	requests := []reconcile.Request{}
+	for i := range hcpList.Items {
+		requests = append(requests, r.ClusterUrlMonitorsForHostedControlPlane(ctx, &hcpList.Items[i])...)
+	}
+	return requests
+}
+
+func (r *ClusterUrlMonitorReconciler) clusterUrlMonitorRequests(ctx context.Context, opts ...client.ListOption) []reconcile.Request {
+	clusterUrlMonitors := &monitoringv1alpha1.ClusterUrlMonitorList{}
+	if err := r.Client.List(ctx, clusterUrlMonitors, opts...); err != nil {
+		r.Log.Error(err, "Failed to list ClusterUrlMonitors")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(clusterUrlMonitors.Items))
+	for _, clusterUrlMonitor := range clusterUrlMonitors.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: clusterUrlMonitor.Name, Namespace: clusterUrlMonitor.Namespace}})
+	}
+	return requests
+}
+
+// apiServerURLChanged filters the Infrastructure updates which can change the cluster domain
+var apiServerURLChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldInfra, ok := e.ObjectOld.(*configv1.Infrastructure)
+		if !ok {
+			return true
+		}
+		newInfra, ok := e.ObjectNew.(*configv1.Infrastructure)
+		if !ok {
+			return true
+		}
+		return oldInfra.Status.APIServerURL != newInfra.Status.APIServerURL
+	},
+}
+
+// baseDomainChanged filters the HostedCluster updates which can change the cluster domain
+var baseDomainChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldHostedCluster, ok := e.ObjectOld.(*hypershiftv1beta1.HostedCluster)
+		if !ok {
+			return true
+		}
+		newHostedCluster, ok := e.ObjectNew.(*hypershiftv1beta1.HostedCluster)
+		if !ok {
+			return true
+		}
+		return oldHostedCluster.Spec.DNS.BaseDomain != newHostedCluster.Spec.DNS.BaseDomain
+	},
+}
+
+// hostedControlPlaneChanged filters the HostedControlPlane updates which can change the probes of its ClusterUrlMonitors
+var hostedControlPlaneChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldHCP, ok := e.ObjectOld.(*hypershiftv1beta1.HostedControlPlane)
+		if !ok {
+			return true
+		}
+		newHCP, ok := e.ObjectNew.(*hypershiftv1beta1.HostedControlPlane)
+		if !ok {
+			return true
+		}
+		return oldHCP.Annotations[hcpClusterAnnotation] != newHCP.Annotations[hcpClusterAnnotation] ||
+			oldHCP.Spec.ClusterID != newHCP.Spec.ClusterID ||
+			(isClusterVersionAvailable(*oldHCP) == nil) != (isClusterVersionAvailable(*newHCP) == nil)
+	},
+}
+
 func (r *ClusterUrlMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
+	ctx := context.Background()
+	if err := IndexDomainRef(ctx, mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
+	b := ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringv1alpha1.ClusterUrlMonitor{}).
 		Watches(
 			&monitoringv1.ServiceMonitor{},
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &monitoringv1alpha1.ClusterUrlMonitor{}, handler.OnlyControllerOwner()),
 		).
-		Complete(r)
+		Watches(
+			&configv1.Infrastructure{},
+			handler.EnqueueRequestsFromMapFunc(r.ClusterUrlMonitorsForInfrastructure),
+			builder.WithPredicates(apiServerURLChanged),
+		)
+	if r.EnableHCP {
+		if err := IndexHostedCluster(ctx, mgr.GetFieldIndexer()); err != nil {
+			return err
+		}
+		b = b.
+			Watches(
+				&hypershiftv1beta1.HostedCluster{},
+				handler.EnqueueRequestsFromMapFunc(r.ClusterUrlMonitorsForHostedCluster),
+				builder.WithPredicates(baseDomainChanged),
+			).
+			Watches(
+				&hypershiftv1beta1.HostedControlPlane{},
+				handler.EnqueueRequestsFromMapFunc(r.ClusterUrlMonitorsForHostedControlPlane),
+				builder.WithPredicates(hostedControlPlaneChanged),
+			)
+	}
+	return b.Complete(r)
 }
@@ -10,8 +10,13 @@ import (
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
 	"github.com/openshift/route-monitor-operator/api/v1alpha1"
 	"github.com/openshift/route-monitor-operator/pkg/alert"
-	blackboxexporterconsts "github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/consts"
+	"github.com/openshift/route-monitor-operator/pkg/dashboard"
+	reconcileCommon "github.com/openshift/route-monitor-operator/pkg/reconcile"
 	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -25,13 +30,20 @@ const (
 
 // Takes care that right PrometheusRules for the defined ClusterURLMonitor are in place
 func (s *ClusterUrlMonitorReconciler) EnsurePrometheusRuleExists(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
+	// HCP ClusterUrlMonitors are scraped by the RHOBS monitoring stack, which also evaluates their alerts
+	isHCP := clusterUrlMonitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP
+
 	// If .spec.skipPrometheusRule is true, ensure that the PrometheusRule does NOT exist
 	if clusterUrlMonitor.Spec.SkipPrometheusRule {
 		// Cleanup any existing PrometheusRules and update the status
-		if err := s.Prom.DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef); err != nil {
+		if err := deleteByType(s.Prom.DeletePrometheusRuleDeployment, clusterUrlMonitor.Status.PrometheusRuleRef, clusterUrlMonitor.Status.PrometheusRuleType); err != nil {
 			return utilreconcile.RequeueReconcileWith(err)
 		}
-		updated, _ := s.Common.SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, types.NamespacedName{})
+		updated := s.setPrometheusRuleReference(&clusterUrlMonitor, types.NamespacedName{}, "")
+		// without a PrometheusRule, nothing else clears the error of a fixed probe
+		if s.Common.SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, nil) {
+			updated = true
+		}
 		if updated {
 			return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
 		}
@@ -39,49 +51,164 @@ func (s *ClusterUrlMonitorReconciler) EnsurePrometheusRuleExists(clusterUrlMonit
 		return utilreconcile.ContinueReconcile()
 	}
 
-	// We shouldn't create prometheusrules for HCP clusterUrlMonitors, since alerting is implemented in the upstream RHOBS tenant
-	if clusterUrlMonitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP {
-		return utilreconcile.ContinueReconcile()
-	}
-
-	clusterDomain, err := s.GetClusterDomain(clusterUrlMonitor)
+	clusterUrl, err := s.getClusterURL(clusterUrlMonitor)
 	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
-
-	spec := clusterUrlMonitor.Spec
-	clusterUrl := spec.Prefix + clusterDomain + ":" + spec.Port + spec.Suffix
 	parsedSlo, err := s.Common.ParseMonitorSLOSpecs(clusterUrl, clusterUrlMonitor.Spec.Slo)
+	namespacedName := types.NamespacedName{Namespace: clusterUrlMonitor.Namespace, Name: clusterUrlMonitor.Name}
+	var template monitoringv1.PrometheusRule
+	invalidAlerting := false
+	if err == nil && parsedSlo != "" {
+		template, err = alert.TemplateForPrometheusRuleResource(clusterUrl, parsedSlo, namespacedName, s.AlertDefaults.Apply(clusterUrlMonitor.Spec.Alerting), clusterUrlMonitor.Spec.CertificateExpiry)
+		invalidAlerting = err != nil
+	}
 
 	if s.Common.SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, err) {
+		switch {
+		case invalidAlerting:
+			reconcileCommon.RecordInvalidAlertingEvent(s.Recorder, &clusterUrlMonitor, err)
+		case err != nil:
+			reconcileCommon.RecordInvalidSLOEvent(s.Recorder, &clusterUrlMonitor, err)
+		}
 		return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
 	}
+	if invalidAlerting {
+		// the error is already reported in the status, wait for the spec to be fixed
+		return utilreconcile.StopReconcile()
+	}
 	if parsedSlo == "" {
-		err = s.Prom.DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef)
+		err = deleteByType(s.Prom.DeletePrometheusRuleDeployment, clusterUrlMonitor.Status.PrometheusRuleRef, clusterUrlMonitor.Status.PrometheusRuleType)
 		if err != nil {
 			return utilreconcile.RequeueReconcileWith(err)
 		}
-		updated, _ := s.Common.SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, types.NamespacedName{})
+		updated := s.setPrometheusRuleReference(&clusterUrlMonitor, types.NamespacedName{}, "")
 		if updated {
 			return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
 		}
 		return utilreconcile.StopReconcile()
 	}
 
-	namespacedName := types.NamespacedName{Namespace: clusterUrlMonitor.Namespace, Name: clusterUrlMonitor.Name}
-	template := alert.TemplateForPrometheusRuleResource(clusterUrl, parsedSlo, namespacedName)
-	err = s.Prom.UpdatePrometheusRuleDeployment(template)
+	result, err := s.Prom.UpdatePrometheusRuleDeployment(template, isHCP)
 	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
+	reconcileCommon.RecordPrometheusRuleEvent(s.Recorder, &clusterUrlMonitor, result, namespacedName)
 
+	// Delete the PrometheusRule of a previous reference or type, now that its replacement exists
+	prometheusRuleType := monitorType(isHCP)
+	if err := deletePrevious(s.Prom.DeletePrometheusRuleDeployment, clusterUrlMonitor.Status.PrometheusRuleRef, clusterUrlMonitor.Status.PrometheusRuleType, namespacedName, prometheusRuleType); err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
 	// Update PrometheusRuleReference in ClusterUrlMonitor if necessary
-	updated, _ := s.Common.SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, namespacedName)
+	updated := s.setPrometheusRuleReference(&clusterUrlMonitor, namespacedName, prometheusRuleType)
+	if updated {
+		return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
+	}
+	return utilreconcile.ContinueReconcile()
+}
+
+// Ensures that the Grafana dashboard of the ClusterURLMonitor is in place when dashboards are enabled
+func (s *ClusterUrlMonitorReconciler) EnsureDashboardExists(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
+	isValid, parsedSlo := clusterUrlMonitor.Spec.Slo.IsValid()
+	// HCP probe results are only available in the upstream RHOBS tenant
+	if !s.Dashboard.Enabled() || !isValid || clusterUrlMonitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP {
+		// Delete existing dashboards if required
+		if err := s.Dashboard.DeleteDashboardDeployment(clusterUrlMonitor.Status.DashboardRef); err != nil {
+			return utilreconcile.RequeueReconcileWith(err)
+		}
+		updated := s.Common.SetResourceReference(&clusterUrlMonitor.Status.DashboardRef, types.NamespacedName{})
+		if updated {
+			return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
+		}
+		return utilreconcile.ContinueReconcile()
+	}
+
+	clusterUrl, err := s.getClusterURL(clusterUrlMonitor)
+	if err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+
+	namespacedName := types.NamespacedName{Namespace: clusterUrlMonitor.Namespace, Name: clusterUrlMonitor.Name}
+	owner := metav1.NewControllerRef(&clusterUrlMonitor.ObjectMeta, clusterUrlMonitor.GroupVersionKind())
+	result, err := s.Dashboard.TemplateAndUpdateDashboardDeployment(clusterUrl, parsedSlo, namespacedName, owner)
+	if err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+	dashboardName := dashboard.NamespacedNameFor(namespacedName)
+	reconcileCommon.RecordDashboardEvent(s.Recorder, &clusterUrlMonitor, result, dashboardName)
+
+	// Delete the dashboard of a previous reference, now that its replacement exists
+	if reconcileCommon.ReferenceChanged(clusterUrlMonitor.Status.DashboardRef, dashboardName) {
+		if err := s.Dashboard.DeleteDashboardDeployment(clusterUrlMonitor.Status.DashboardRef); err != nil {
+			return utilreconcile.RequeueReconcileWith(err)
+		}
+	}
+	// Update DashboardRef in ClusterUrlMonitor if necessary
+	updated := s.Common.SetResourceReference(&clusterUrlMonitor.Status.DashboardRef, dashboardName)
 	if updated {
 		return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
 	}
 	return utilreconcile.ContinueReconcile()
 }
+
+// Ensures that the SLO status of the ClusterUrlMonitor reflects the service level observed by Prometheus
+func (s *ClusterUrlMonitorReconciler) EnsureSLOStatus(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
+	// HCP probe results are only available in the upstream RHOBS tenant
+	if s.SLO == nil || clusterUrlMonitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP {
+		return utilreconcile.ContinueReconcile()
+	}
+
+	isValid, target := clusterUrlMonitor.Spec.Slo.IsValid()
+	if !isValid {
+		if clusterUrlMonitor.Status.SLO != nil {
+			clusterUrlMonitor.Status.SLO = nil
+			return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
+		}
+		return utilreconcile.ContinueReconcile()
+	}
+	if !s.SLO.ShouldResync(clusterUrlMonitor.Status.SLO) {
+		return utilreconcile.ContinueReconcile()
+	}
+
+	clusterUrl, err := s.getClusterURL(clusterUrlMonitor)
+	if err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+
+	sloStatus, err := s.SLO.GetSLOStatus(s.Ctx, clusterUrl, target)
+	if err != nil {
+		// the SLO status is optional: keep the last one and retry with the next periodic resync
+		s.Log.Info("Failed to query the SLO status, keeping the last one", "error", err.Error())
+		reconcileCommon.RecordSLOQueryFailedEvent(s.Recorder, &clusterUrlMonitor, err)
+		return utilreconcile.ContinueReconcile()
+	}
+	if sloStatus == nil && clusterUrlMonitor.Status.SLO == nil {
+		return utilreconcile.ContinueReconcile()
+	}
+	clusterUrlMonitor.Status.SLO = sloStatus
+	return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
+}
+
+// EnsureClusterURLExists records the URL resolved from the cluster's domain in the ClusterUrlMonitor's status
+func (s *ClusterUrlMonitorReconciler) EnsureClusterURLExists(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
+	clusterUrl, err := s.getClusterURL(clusterUrlMonitor)
+	if err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+
+	currentClusterUrl := clusterUrlMonitor.Status.ClusterURL
+	if currentClusterUrl == clusterUrl {
+		return utilreconcile.ContinueReconcile()
+	}
+	if currentClusterUrl != "" {
+		s.Recorder.Eventf(&clusterUrlMonitor, corev1.EventTypeNormal, consts.EventReasonClusterURLChanged, "ClusterURL changed from %s to %s", currentClusterUrl, clusterUrl)
+	}
+
+	clusterUrlMonitor.Status.ClusterURL = clusterUrl
+	return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
+}
+
 func isClusterVersionAvailable(hcp hypershiftv1beta1.HostedControlPlane) error {
 	condition := meta.FindStatusCondition(hcp.Status.Conditions, string(hypershiftv1beta1.ClusterVersionAvailable))
 	if condition == nil || condition.Status != metav1.ConditionTrue {
@@ -92,14 +219,23 @@ func isClusterVersionAvailable(hcp hypershiftv1beta1.HostedControlPlane) error {
 
 // Takes care that right ServiceMonitor for the defined ClusterURLMonitor are in place
 func (s *ClusterUrlMonitorReconciler) EnsureServiceMonitorExists(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
-	clusterDomain, err := s.GetClusterDomain(clusterUrlMonitor)
+	if err := blackboxexporter.ValidateProbe(clusterUrlMonitor.Spec.Probe); err != nil {
+		// the error replaces errors of the later steps in the status, which are not reached until the probe is fixed
+		if clusterUrlMonitor.Status.ErrorStatus != err.Error() {
+			clusterUrlMonitor.Status.ErrorStatus = err.Error()
+			reconcileCommon.RecordInvalidProbeEvent(s.Recorder, &clusterUrlMonitor, err)
+			return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
+		}
+		// the error is already reported in the status, wait for the spec to be fixed
+		return utilreconcile.StopReconcile()
+	}
+
+	clusterUrl, err := s.getClusterURL(clusterUrlMonitor)
 	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
 
 	namespacedName := types.NamespacedName{Name: clusterUrlMonitor.Name, Namespace: clusterUrlMonitor.Namespace}
-	spec := clusterUrlMonitor.Spec
-	clusterUrl := spec.Prefix + clusterDomain + ":" + spec.Port + spec.Suffix
 	isHCP := (clusterUrlMonitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP)
 	var id string
 	if isHCP {
@@ -123,16 +259,39 @@ func (s *ClusterUrlMonitorReconciler) EnsureServiceMonitorExists(clusterUrlMonit
 		}
 	}
 
-	owner := metav1.NewControllerRef(&clusterUrlMonitor.ObjectMeta, clusterUrlMonitor.GroupVersionKind())
-	if err := s.ServiceMonitor.TemplateAndUpdateServiceMonitorDeployment(clusterUrl, s.BlackBoxExporter.GetBlackBoxExporterNamespace(), namespacedName, id, isHCP, false, owner); err != nil {
+	exporterService, err := s.BlackBoxExporter.GetExporterService(clusterUrlMonitor.Spec.ExporterRef)
+	if err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+	// the blackbox exporter has no module for a monitor with unresolved references, so its probes would fail unnoticed
+	if err := s.BlackBoxExporter.ValidateProbeReferences(clusterUrlMonitor.Namespace, clusterUrlMonitor.Spec.Probe); err != nil {
+		if clusterUrlMonitor.Status.ErrorStatus != err.Error() {
+			clusterUrlMonitor.Status.ErrorStatus = err.Error()
+			reconcileCommon.RecordInvalidProbeEvent(s.Recorder, &clusterUrlMonitor, err)
+			return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
+		}
+		// retry until the referenced Secrets and ConfigMaps exist
 		return utilreconcile.RequeueReconcileWith(err)
 	}
 
-	// Update RouteMonitor ServiceMonitorRef if required
-	updated, err := s.Common.SetResourceReference(&clusterUrlMonitor.Status.ServiceMonitorRef, namespacedName)
+	owner := metav1.NewControllerRef(&clusterUrlMonitor.ObjectMeta, clusterUrlMonitor.GroupVersionKind())
+	result, err := s.ServiceMonitor.TemplateAndUpdateServiceMonitorDeployment(clusterUrl, exporterService, namespacedName, id, isHCP, blackboxexporter.ModuleForClusterUrlMonitor(clusterUrlMonitor), clusterUrlMonitor.Spec.ProxyURL, owner)
 	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
+	reconcileCommon.RecordServiceMonitorEvent(s.Recorder, &clusterUrlMonitor, result, namespacedName)
+
+	// Delete the ServiceMonitor of a previous reference or type, now that its replacement exists
+	serviceMonitorType := monitorType(isHCP)
+	if err := deletePrevious(s.ServiceMonitor.DeleteServiceMonitorDeployment, clusterUrlMonitor.Status.ServiceMonitorRef, clusterUrlMonitor.Status.ServiceMonitorType, namespacedName, serviceMonitorType); err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+	// Update RouteMonitor ServiceMonitorRef if required
+	updated := s.Common.SetResourceReference(&clusterUrlMonitor.Status.ServiceMonitorRef, namespacedName)
+	if clusterUrlMonitor.Status.ServiceMonitorType != serviceMonitorType {
+		clusterUrlMonitor.Status.ServiceMonitorType = serviceMonitorType
+		updated = true
+	}
 	if updated {
 		return s.Common.UpdateMonitorResourceStatus(&clusterUrlMonitor)
 	}
@@ -145,24 +304,12 @@ func (s *ClusterUrlMonitorReconciler) EnsureMonitorAndDependenciesAbsent(cluster
 		return utilreconcile.ContinueReconcile()
 	}
 
-	isHCP := (clusterUrlMonitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP)
-	err := s.ServiceMonitor.DeleteServiceMonitorDeployment(clusterUrlMonitor.Status.ServiceMonitorRef, isHCP)
+	err := deleteByType(s.ServiceMonitor.DeleteServiceMonitorDeployment, clusterUrlMonitor.Status.ServiceMonitorRef, clusterUrlMonitor.Status.ServiceMonitorType)
 	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
 
-	shouldDelete, err := s.BlackBoxExporter.ShouldDeleteBlackBoxExporterResources()
-	if err != nil {
-		return utilreconcile.RequeueReconcileWith(err)
-	}
-	if shouldDelete == blackboxexporterconsts.DeleteBlackBoxExporter {
-		err := s.BlackBoxExporter.EnsureBlackBoxExporterResourcesAbsent()
-		if err != nil {
-			return utilreconcile.RequeueReconcileWith(err)
-		}
-	}
-
-	err = s.Prom.DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef)
+	err = deleteByType(s.Prom.DeletePrometheusRuleDeployment, clusterUrlMonitor.Status.PrometheusRuleRef, clusterUrlMonitor.Status.PrometheusRuleType)
 	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
@@ -176,6 +323,52 @@ func (s *ClusterUrlMonitorReconciler) EnsureMonitorAndDependenciesAbsent(cluster
 	return utilreconcile.ContinueReconcile()
 }
 
+// setPrometheusRuleReference updates the PrometheusRuleRef and PrometheusRuleType in the ClusterUrlMonitor's status,
+// returning true if either changed
+func (s *ClusterUrlMonitorReconciler) setPrometheusRuleReference(clusterUrlMonitor *v1alpha1.ClusterUrlMonitor, target types.NamespacedName, prometheusRuleType string) bool {
+	updated := s.Common.SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, target)
+	if clusterUrlMonitor.Status.PrometheusRuleType != prometheusRuleType {
+		clusterUrlMonitor.Status.PrometheusRuleType = prometheusRuleType
+		updated = true
+	}
+	return updated
+}
+
+// monitorType returns the API group of the ServiceMonitor and PrometheusRule of a ClusterUrlMonitor
+func monitorType(isHCP bool) string {
+	if isHCP {
+		return v1alpha1.ServiceMonitorTypeRHOBS
+	}
+	return v1alpha1.ServiceMonitorTypeCoreOS
+}
+
+// deleteByType deletes the referenced ServiceMonitor or PrometheusRule of the recorded type with del. ClusterUrlMonitors
+// reconciled before the type was recorded in their status may have left a resource of either type behind, so both are deleted
+func deleteByType(del func(v1alpha1.NamespacedName, bool) error, ref v1alpha1.NamespacedName, recordedType string) error {
+	switch recordedType {
+	case v1alpha1.ServiceMonitorTypeCoreOS:
+		return del(ref, false)
+	case v1alpha1.ServiceMonitorTypeRHOBS:
+		return del(ref, true)
+	}
+	if err := del(ref, false); err != nil {
+		return err
+	}
+	return del(ref, true)
+}
+
+// deletePrevious deletes the referenced ServiceMonitor or PrometheusRule with del, unless it is the one of the target's name
+// and type. When only the type changed, the resource of the new type has the same name, so only the one of the other type is deleted
+func deletePrevious(del func(v1alpha1.NamespacedName, bool) error, ref v1alpha1.NamespacedName, recordedType string, target types.NamespacedName, targetType string) error {
+	if reconcileCommon.ReferenceChanged(ref, target) {
+		return deleteByType(del, ref, recordedType)
+	}
+	if ref == (v1alpha1.NamespacedName{}) || recordedType == targetType {
+		return nil
+	}
+	return del(ref, targetType != v1alpha1.ServiceMonitorTypeRHOBS)
+}
+
 func (s *ClusterUrlMonitorReconciler) EnsureFinalizerSet(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
 	if s.Common.SetFinalizer(&clusterUrlMonitor, FinalizerKey) {
 		// ignore the output as we want to remove the PrevFinalizerKey anyways
@@ -209,6 +402,15 @@ func (s *ClusterUrlMonitorReconciler) GetClusterUrlMonitor(req ctrl.Request) (v1
 	return ClusterUrlMonitor, utilreconcile.ContinueOperation(), nil
 }
 
+// getClusterURL returns the URL probed for the ClusterUrlMonitor within the cluster's domain
+func (s *ClusterUrlMonitorReconciler) getClusterURL(monitor v1alpha1.ClusterUrlMonitor) (string, error) {
+	clusterDomain, err := s.GetClusterDomain(monitor)
+	if err != nil {
+		return "", err
+	}
+	return monitor.Spec.URL(clusterDomain), nil
+}
+
 // GetClusterDomain returns the baseDomain for a cluster, using the correct method based on it's type
 func (s *ClusterUrlMonitorReconciler) GetClusterDomain(monitor v1alpha1.ClusterUrlMonitor) (string, error) {
 	if monitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP {
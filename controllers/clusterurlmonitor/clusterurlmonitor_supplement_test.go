@@ -8,8 +8,10 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	configv1 "github.com/openshift/api/config/v1"
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
@@ -17,6 +19,7 @@ import (
 	"github.com/openshift/route-monitor-operator/controllers/clusterurlmonitor"
 	constinit "github.com/openshift/route-monitor-operator/pkg/consts/test/init"
 	reconcileCommon "github.com/openshift/route-monitor-operator/pkg/reconcile"
+	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
 )
 
 var _ = Describe("ClusterUrlMonitorSupplement", func() {
@@ -118,9 +121,115 @@ var _ = Describe("ClusterUrlMonitorSupplement", func() {
 			})
 		})
 	})
+
+	Describe("EnsureClusterURLExists()", func() {
+		var (
+			infra    configv1.Infrastructure
+			recorder *record.FakeRecorder
+		)
+		BeforeEach(func() {
+			clusterUrlMonitor.Spec = v1alpha1.ClusterUrlMonitorSpec{Prefix: "console.", Port: "443"}
+			infra = configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     configv1.InfrastructureStatus{APIServerURL: "https://api.testdomain.devshift.org:6443"},
+			}
+			testObjs = append(testObjs, &infra, &clusterUrlMonitor)
+		})
+		JustBeforeEach(func() {
+			recorder = record.NewFakeRecorder(1)
+			reconciler.Recorder = recorder
+		})
+
+		It("records the resolved URL in the status", func() {
+			res, err := reconciler.EnsureClusterURLExists(clusterUrlMonitor)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(res.ShouldStop()).To(BeTrue())
+
+			updated := v1alpha1.ClusterUrlMonitor{}
+			Expect(reconciler.Client.Get(context.TODO(), client.ObjectKeyFromObject(&clusterUrlMonitor), &updated)).To(Succeed())
+			Expect(updated.Status.ClusterURL).To(Equal("console.testdomain.devshift.org:443"))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		When("the cluster's domain changed since the ClusterURL was recorded", func() {
+			BeforeEach(func() {
+				clusterUrlMonitor.Status.ClusterURL = "console.olddomain.devshift.org:443"
+			})
+			It("emits a ClusterURLChanged event", func() {
+				_, err := reconciler.EnsureClusterURLExists(clusterUrlMonitor)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(recorder.Events).To(Receive(Equal("Normal ClusterURLChanged ClusterURL changed from console.olddomain.devshift.org:443 to console.testdomain.devshift.org:443")))
+			})
+		})
+
+		When("the ClusterURL is up to date", func() {
+			BeforeEach(func() {
+				clusterUrlMonitor.Status.ClusterURL = "console.testdomain.devshift.org:443"
+			})
+			It("continues reconciling", func() {
+				res, err := reconciler.EnsureClusterURLExists(clusterUrlMonitor)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(res).To(Equal(utilreconcile.ContinueOperation()))
+			})
+		})
+	})
+
+	Describe("ClusterUrlMonitorsFor", func() {
+		var infraMonitor, hcpMonitor v1alpha1.ClusterUrlMonitor
+		BeforeEach(func() {
+			infraMonitor = v1alpha1.ClusterUrlMonitor{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "openshift-route-monitor-operator"}}
+			hcpMonitor = v1alpha1.ClusterUrlMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "ocm-test-hc"},
+				Spec:       v1alpha1.ClusterUrlMonitorSpec{DomainRef: v1alpha1.ClusterDomainRefHCP},
+			}
+			hcp := hypershiftv1beta1.HostedControlPlane{ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-hcp",
+				Namespace:   "ocm-test-hc",
+				Annotations: map[string]string{"hypershift.openshift.io/cluster": "ocm/test-hc"},
+			}}
+			testObjs = append(testObjs, &infraMonitor, &hcpMonitor, &hcp)
+		})
+		JustBeforeEach(func() {
+			builder := fake.NewClientBuilder().WithObjects(testObjs...).WithScheme(constinit.Scheme)
+			Expect(clusterurlmonitor.IndexDomainRef(context.TODO(), builderIndexer{builder})).To(Succeed())
+			Expect(clusterurlmonitor.IndexHostedCluster(context.TODO(), builderIndexer{builder})).To(Succeed())
+			reconciler.Client = builder.Build()
+		})
+
+		It("maps the Infrastructure to the ClusterUrlMonitors using it", func() {
+			infra := configv1.Infrastructure{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+			Expect(reconciler.ClusterUrlMonitorsForInfrastructure(context.TODO(), &infra)).To(ConsistOf(requestFor(infraMonitor)))
+		})
+		It("maps the HostedControlPlane to the ClusterUrlMonitors in its namespace", func() {
+			hcp := hypershiftv1beta1.HostedControlPlane{ObjectMeta: metav1.ObjectMeta{Name: "test-hcp", Namespace: "ocm-test-hc"}}
+			Expect(reconciler.ClusterUrlMonitorsForHostedControlPlane(context.TODO(), &hcp)).To(ConsistOf(requestFor(hcpMonitor)))
+		})
+		It("maps the HostedCluster to the ClusterUrlMonitors of its HostedControlPlane", func() {
+			hc := hypershiftv1beta1.HostedCluster{ObjectMeta: metav1.ObjectMeta{Name: "test-hc", Namespace: "ocm"}}
+			Expect(reconciler.ClusterUrlMonitorsForHostedCluster(context.TODO(), &hc)).To(ConsistOf(requestFor(hcpMonitor)))
+		})
+		It("ignores other HostedClusters", func() {
+			hc := hypershiftv1beta1.HostedCluster{ObjectMeta: metav1.ObjectMeta{Name: "other-hc", Namespace: "ocm"}}
+			Expect(reconciler.ClusterUrlMonitorsForHostedCluster(context.TODO(), &hc)).To(BeEmpty())
+		})
+	})
 })
 
+func requestFor(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) reconcile.Request {
+	return reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusterUrlMonitor)}
+}
+
+// builderIndexer registers indexes with a fake client
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (i builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	i.builder.WithIndex(obj, field, extractValue)
+	return nil
+}
+
 func buildClient(objs ...client.Object) client.Client {
-	builder := fake.NewClientBuilder().WithObjects(objs...).WithScheme(constinit.Scheme).WithStatusSubresource()
+	builder := fake.NewClientBuilder().WithObjects(objs...).WithScheme(constinit.Scheme).WithStatusSubresource(&v1alpha1.ClusterUrlMonitor{})
 	return builder.Build()
 }
@@ -6,14 +6,16 @@ import (
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
 	customerrors "github.com/openshift/route-monitor-operator/pkg/util/errors"
 	"go.uber.org/mock/gomock"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/openshift/route-monitor-operator/api/v1alpha1"
 	"github.com/openshift/route-monitor-operator/controllers/clusterurlmonitor"
-	"github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
 	constinit "github.com/openshift/route-monitor-operator/pkg/consts/test/init"
 	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
 	clientmocks "github.com/openshift/route-monitor-operator/pkg/util/test/generated/mocks/client"
@@ -31,6 +33,7 @@ var _ = Describe("Clusterurlmonitor", func() {
 		mockServiceMonitor   *controllermocks.MockServiceMonitorHandler
 
 		mockCtrl *gomock.Controller
+		recorder *record.FakeRecorder
 
 		prefix string
 		port   string
@@ -47,6 +50,7 @@ var _ = Describe("Clusterurlmonitor", func() {
 		mockServiceMonitor = controllermocks.NewMockServiceMonitorHandler(mockCtrl)
 		mockPrometheusRule = controllermocks.NewMockPrometheusRuleHandler(mockCtrl)
 		mockCommon = controllermocks.NewMockMonitorResourceHandler(mockCtrl)
+		recorder = record.NewFakeRecorder(10)
 		clusterUrlMonitor = v1alpha1.ClusterUrlMonitor{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "fake-clusterurlmonitor",
@@ -67,6 +71,7 @@ var _ = Describe("Clusterurlmonitor", func() {
 			Common:           mockCommon,
 			ServiceMonitor:   mockServiceMonitor,
 			Prom:             mockPrometheusRule,
+			Recorder:         recorder,
 		}
 	})
 
@@ -86,16 +91,39 @@ var _ = Describe("Clusterurlmonitor", func() {
 		When("the ServiceMonitor doesn't exist", func() {
 			BeforeEach(func() {
 				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1) // fetching domain
-				mockServiceMonitor.EXPECT().TemplateAndUpdateServiceMonitorDeployment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
-				mockBlackBoxExporter.EXPECT().GetBlackBoxExporterNamespace().Times(1).Return("")
+				mockServiceMonitor.EXPECT().TemplateAndUpdateServiceMonitorDeployment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(controllerutil.OperationResultCreated, nil)
+				mockBlackBoxExporter.EXPECT().GetExporterService("").Times(1).Return(types.NamespacedName{}, nil)
+				mockBlackBoxExporter.EXPECT().ValidateProbeReferences(clusterUrlMonitor.Namespace, clusterUrlMonitor.Spec.Probe).Times(1).Return(nil)
 				ns := types.NamespacedName{Name: clusterUrlMonitor.Name, Namespace: clusterUrlMonitor.Namespace}
 				mockCommon.EXPECT().GetOSDClusterID().Times(1)
-				mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.ServiceMonitorRef, ns).Times(1).Return(true, nil)
-				mockCommon.EXPECT().UpdateMonitorResourceStatus(&clusterUrlMonitor).Times(1)
+				mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.ServiceMonitorRef, ns).Times(1).Return(true)
+				mockCommon.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Times(1)
 			})
 			It("creates a ServiceMonitor and updates the ServiceRef", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(res).To(Equal(utilreconcile.StopOperation()))
+				Expect(recorder.Events).To(Receive(Equal("Normal ServiceMonitorCreated Created ServiceMonitor fake-namespace/fake-clusterurlmonitor")))
+			})
+		})
+		When("the domainRef changed from hcp", func() {
+			BeforeEach(func() {
+				clusterUrlMonitor.Status.ServiceMonitorRef = v1alpha1.NamespacedName{Name: clusterUrlMonitor.Name, Namespace: clusterUrlMonitor.Namespace}
+				clusterUrlMonitor.Status.ServiceMonitorType = v1alpha1.ServiceMonitorTypeRHOBS
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1) // fetching domain
+				mockServiceMonitor.EXPECT().TemplateAndUpdateServiceMonitorDeployment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), false, gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(controllerutil.OperationResultCreated, nil)
+				mockBlackBoxExporter.EXPECT().GetExporterService("").Times(1).Return(types.NamespacedName{}, nil)
+				mockBlackBoxExporter.EXPECT().ValidateProbeReferences(clusterUrlMonitor.Namespace, clusterUrlMonitor.Spec.Probe).Times(1).Return(nil)
+				mockCommon.EXPECT().GetOSDClusterID().Times(1)
+				mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(clusterUrlMonitor.Status.ServiceMonitorRef, true).Times(1)
+				mockCommon.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Times(1).Return(false)
+				mockCommon.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).DoAndReturn(func(monitor *v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
+					Expect(monitor.Status.ServiceMonitorType).To(Equal(v1alpha1.ServiceMonitorTypeCoreOS))
+					return utilreconcile.StopOperation(), nil
+				})
+			})
+			It("deletes the RHOBS ServiceMonitor of the same name", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).To(Equal(utilreconcile.StopOperation()))
 			})
 		})
 	})
@@ -113,9 +141,10 @@ var _ = Describe("Clusterurlmonitor", func() {
 				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 				err := customerrors.ErrInvalidSLO
 				mockCommon.EXPECT().ParseMonitorSLOSpecs(gomock.Any(), clusterUrlMonitor.Spec.Slo).Times(1).Return("", err)
-				mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, err)
-				// It deletes old prometheus rule deployment if still there
-				mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(gomock.Any()).Times(1)
+				mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, err).Return(false)
+				// It deletes old prometheus rule deployment of either type if still there
+				mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(gomock.Any(), false).Times(1)
+				mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(gomock.Any(), true).Times(1)
 				mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, types.NamespacedName{}).Times(1)
 			})
 			It("sets the error in the ClusterUrlMonitor and stops processing", func() {
@@ -124,18 +153,33 @@ var _ = Describe("Clusterurlmonitor", func() {
 				Expect(res).To(Equal(utilreconcile.StopOperation()))
 			})
 		})
+		When("the ClusterUrlMonitor's slo value becomes invalid", func() {
+			BeforeEach(func() {
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+				err := customerrors.ErrInvalidSLO
+				mockCommon.EXPECT().ParseMonitorSLOSpecs(gomock.Any(), clusterUrlMonitor.Spec.Slo).Times(1).Return("", err)
+				mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, err).Return(true)
+				mockCommon.EXPECT().UpdateMonitorResourceStatus(&clusterUrlMonitor).Times(1).Return(utilreconcile.StopOperation(), nil)
+			})
+			It("emits an InvalidSLO warning", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning InvalidSLO")))
+			})
+		})
 		When("the resource Exists but not the same as the generated template", func() {
 			BeforeEach(func() {
+				clusterUrlMonitor.Status.PrometheusRuleType = v1alpha1.ServiceMonitorTypeCoreOS
 				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 				mockCommon.EXPECT().ParseMonitorSLOSpecs(gomock.Any(), clusterUrlMonitor.Spec.Slo).Times(1).Return("99.5", nil)
 				mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, nil)
-				mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any()).Times(1)
-				mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, gomock.Any()).Times(1).Return(false, nil)
+				mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any(), false).Times(1).Return(controllerutil.OperationResultUpdated, nil)
+				mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, gomock.Any()).Times(1).Return(false)
 			})
 			It("doesn't update the clusterUrlMonitor reference and continues reconciling", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(res).NotTo(BeNil())
 				Expect(res).To(Equal(utilreconcile.ContinueOperation()))
+				Expect(recorder.Events).To(Receive(HavePrefix("Normal PrometheusRuleUpdated")))
 			})
 		})
 		When("the resource doesn't exists", func() {
@@ -143,10 +187,10 @@ var _ = Describe("Clusterurlmonitor", func() {
 				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
 				mockCommon.EXPECT().ParseMonitorSLOSpecs(gomock.Any(), clusterUrlMonitor.Spec.Slo).Times(1).Return("99.5", nil)
 				mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, nil)
-				mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any()).Times(1)
+				mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any(), false).Times(1)
 				ns := types.NamespacedName{Name: clusterUrlMonitor.Name, Namespace: clusterUrlMonitor.Namespace}
-				mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, ns).Times(1).Return(true, nil)
-				mockCommon.EXPECT().UpdateMonitorResourceStatus(&clusterUrlMonitor).Times(1).Return(utilreconcile.StopOperation(), nil)
+				mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, ns).Times(1).Return(true)
+				mockCommon.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Times(1).Return(utilreconcile.StopOperation(), nil)
 			})
 
 			It("should create one and update the clusterURLMonitor", func() {
@@ -155,6 +199,70 @@ var _ = Describe("Clusterurlmonitor", func() {
 				Expect(res).To(Equal(utilreconcile.StopOperation()))
 			})
 		})
+		When("the domainRef changed from hcp", func() {
+			BeforeEach(func() {
+				clusterUrlMonitor.Status.PrometheusRuleRef = v1alpha1.NamespacedName{Name: clusterUrlMonitor.Name, Namespace: clusterUrlMonitor.Namespace}
+				clusterUrlMonitor.Status.PrometheusRuleType = v1alpha1.ServiceMonitorTypeRHOBS
+				mockClient.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+				mockCommon.EXPECT().ParseMonitorSLOSpecs(gomock.Any(), clusterUrlMonitor.Spec.Slo).Times(1).Return("99.5", nil)
+				mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, nil)
+				mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any(), false).Times(1).Return(controllerutil.OperationResultCreated, nil)
+				mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef, true).Times(1)
+				mockCommon.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Times(1).Return(false)
+				mockCommon.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).DoAndReturn(func(monitor *v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
+					Expect(monitor.Status.PrometheusRuleType).To(Equal(v1alpha1.ServiceMonitorTypeCoreOS))
+					return utilreconcile.StopOperation(), nil
+				})
+			})
+			It("deletes the RHOBS PrometheusRule of the same name", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(res).To(Equal(utilreconcile.StopOperation()))
+			})
+		})
+		When("the ClusterUrlMonitor monitors a hosted cluster", func() {
+			BeforeEach(func() {
+				clusterUrlMonitor.Spec.DomainRef = v1alpha1.ClusterDomainRefHCP
+			})
+			When("the PrometheusRule is skipped", func() {
+				BeforeEach(func() {
+					clusterUrlMonitor.Spec.SkipPrometheusRule = true
+					clusterUrlMonitor.Status.PrometheusRuleType = v1alpha1.ServiceMonitorTypeRHOBS
+					mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef, true).Times(1)
+					mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, types.NamespacedName{}).Times(1).Return(false)
+					mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, nil).Times(1).Return(false)
+					mockCommon.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Times(1).Return(utilreconcile.ContinueOperation(), nil)
+				})
+				It("removes the RHOBS PrometheusRule", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(res).To(Equal(utilreconcile.ContinueOperation()))
+				})
+			})
+			When("the resource doesn't exist", func() {
+				BeforeEach(func() {
+					hcp := hypershiftv1beta1.HostedControlPlane{ObjectMeta: metav1.ObjectMeta{
+						Name:        "fake-hcp",
+						Namespace:   clusterUrlMonitor.Namespace,
+						Annotations: map[string]string{"hypershift.openshift.io/cluster": "clusters/fake-cluster"},
+					}}
+					mockCommon.EXPECT().GetHCP(clusterUrlMonitor.Namespace).Return(hcp, nil)
+					mockClient.EXPECT().Get(gomock.Any(), types.NamespacedName{Namespace: "clusters", Name: "fake-cluster"}, gomock.Any()).Times(1)
+					mockCommon.EXPECT().ParseMonitorSLOSpecs(gomock.Any(), clusterUrlMonitor.Spec.Slo).Times(1).Return("99.5", nil)
+					mockCommon.EXPECT().SetErrorStatus(&clusterUrlMonitor.Status.ErrorStatus, nil)
+					mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any(), true).Times(1).Return(controllerutil.OperationResultCreated, nil)
+					ns := types.NamespacedName{Name: clusterUrlMonitor.Name, Namespace: clusterUrlMonitor.Namespace}
+					mockCommon.EXPECT().SetResourceReference(&clusterUrlMonitor.Status.PrometheusRuleRef, ns).Times(1).Return(true)
+					mockCommon.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).DoAndReturn(func(monitor *v1alpha1.ClusterUrlMonitor) (utilreconcile.Result, error) {
+						Expect(monitor.Status.PrometheusRuleType).To(Equal(v1alpha1.ServiceMonitorTypeRHOBS))
+						return utilreconcile.StopOperation(), nil
+					})
+				})
+				It("creates a RHOBS PrometheusRule and updates the clusterURLMonitor", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(res).To(Equal(utilreconcile.StopOperation()))
+					Expect(recorder.Events).To(Receive(HavePrefix("Normal PrometheusRuleCreated")))
+				})
+			})
+		})
 	})
 
 	Describe("EnsureDeletionProcessed", func() {
@@ -181,8 +289,10 @@ var _ = Describe("Clusterurlmonitor", func() {
 			})
 			When("the ServiceMonitor still exists", func() {
 				BeforeEach(func() {
-					mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef).Times(1)
-					mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(clusterUrlMonitor.Status.ServiceMonitorRef, gomock.Any()).Times(1)
+					clusterUrlMonitor.Status.ServiceMonitorType = v1alpha1.ServiceMonitorTypeRHOBS
+					mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef, false).Times(1)
+					mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(clusterUrlMonitor.Status.PrometheusRuleRef, true).Times(1)
+					mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(clusterUrlMonitor.Status.ServiceMonitorRef, true).Times(1)
 					gomock.InOrder(
 						mockCommon.EXPECT().DeleteFinalizer(&clusterUrlMonitor, clusterurlmonitor.FinalizerKey).Times(1).Return(true),
 						mockCommon.EXPECT().DeleteFinalizer(&clusterUrlMonitor, clusterurlmonitor.PrevFinalizerKey).Times(1),
@@ -190,25 +300,9 @@ var _ = Describe("Clusterurlmonitor", func() {
 					mockCommon.EXPECT().UpdateMonitorResource(&clusterUrlMonitor).Return(utilreconcile.StopOperation(), nil)
 
 				})
-				When("the blackboxexporter needs to be cleaned up", func() {
-					BeforeEach(func() {
-						mockBlackBoxExporter.EXPECT().ShouldDeleteBlackBoxExporterResources().Return(blackboxexporter.DeleteBlackBoxExporter, nil)
-						mockBlackBoxExporter.EXPECT().EnsureBlackBoxExporterResourcesAbsent().Times(1)
-					})
-					It("removes the servicemonitor, the blackbox exporter and cleans up the finalizer", func() {
-						Expect(err).NotTo(HaveOccurred())
-						Expect(res).To(Equal(utilreconcile.StopOperation()))
-					})
-				})
-
-				When("the blackboxexporter doesn't need to be cleaned up", func() {
-					BeforeEach(func() {
-						mockBlackBoxExporter.EXPECT().ShouldDeleteBlackBoxExporterResources().Return(blackboxexporter.KeepBlackBoxExporter, nil)
-					})
-					It("removes the servicemonitor and cleans up the finalizer", func() {
-						Expect(err).NotTo(HaveOccurred())
-						Expect(res).To(Equal(utilreconcile.StopOperation()))
-					})
+				It("removes the servicemonitor and cleans up the finalizer", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(res).To(Equal(utilreconcile.StopOperation()))
 				})
 			})
 		})
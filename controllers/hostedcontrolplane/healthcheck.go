@@ -20,157 +20,282 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"time"
 
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
 
 	corev1 "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 const (
-	// healthcheckAnnotation is the key for the annotation which stores the historical probing data for an HCP cluster
-	healthcheckAnnotation = "routemonitor.managed.openshift.io/successful-healthchecks"
+	// healthcheckConditionType is the type of the condition added to a HostedControlPlane's status, which records the healthchecking
+	// history for the cluster. While healthchecks are in progress, its LastTransitionTime is the time of the latest healthcheck
+	healthcheckConditionType = "routemonitor.managed.openshift.io/HealthCheckPassed"
 
-	// consecutiveSuccessfulHealthchecks defines the number of healthchecks in a row that must succeed before
+	// healthcheckSuccessesAnnotation is the annotation of a HostedControlPlane which records the number of consecutive successful
+	// healthchecks while the healthcheck condition exists
+	healthcheckSuccessesAnnotation = "routemonitor.managed.openshift.io/consecutive-healthchecks"
+
+	// legacyHealthcheckAnnotation is the annotation of the ConfigMap which recorded the successful healthchecks of an HCP before
+	// the healthcheck condition was introduced
+	legacyHealthcheckAnnotation = "routemonitor.managed.openshift.io/successful-healthchecks"
+
+	// healthcheckReasonPassed indicates the HCP has passed the required number of consecutive healthchecks
+	healthcheckReasonPassed = "HealthCheckPassed"
+	// healthcheckReasonInProgress indicates the HCP is still being healthchecked
+	healthcheckReasonInProgress = "HealthCheckInProgress"
+	// healthcheckReasonFailed indicates the latest healthcheck against the HCP failed, and the success count was reset
+	healthcheckReasonFailed = "HealthCheckFailed"
+
+	// defaultConsecutiveSuccessfulHealthchecks defines the number of healthchecks in a row that must succeed before
 	// an HCP is considered healthy and it is fully reconciled
-	consecutiveSuccessfulHealthchecks = 5
+	defaultConsecutiveSuccessfulHealthchecks = 5
+
+	// defaultHealthcheckInterval defines the wait period between requeues when an HCP cluster in the process of being healthchecked
+	defaultHealthcheckInterval = 30 * time.Second
 
-	// healthcheckIntervalSeconds defines the wait period between requeues when an HCP cluster in the process of being healthchecked
-	healthcheckIntervalSeconds = 30
+	// defaultHealthcheckMaxClusterAge defines the age after which an HCP cluster is assumed to be healthy, and is no longer healthchecked
+	defaultHealthcheckMaxClusterAge = time.Hour
+
+	// defaultHealthcheckEndpoint is the kube-apiserver endpoint probed when healthchecking an HCP cluster
+	defaultHealthcheckEndpoint = "/livez"
+
+	// defaultHealthcheckTimeout bounds the duration of a single healthcheck request
+	defaultHealthcheckTimeout = 10 * time.Second
 )
 
+// supportedHealthcheckEndpoints contains the kube-apiserver endpoints which may be used to healthcheck an HCP cluster
+var supportedHealthcheckEndpoints = []string{"/livez", "/readyz", "/version"}
+
+// HealthCheckConfig configures how HostedControlPlanes are healthchecked before their monitoring objects are deployed
+type HealthCheckConfig struct {
+	// ConsecutiveSuccesses is the number of healthchecks in a row that must succeed before an HCP is considered healthy.
+	// A value of 0 disables healthchecking
+	ConsecutiveSuccesses int
+
+	// Interval is the wait period between healthchecks
+	Interval time.Duration
+
+	// MaxClusterAge is the age after which an HCP is assumed to be healthy and is no longer healthchecked. A value of 0
+	// healthchecks HCPs regardless of their age
+	MaxClusterAge time.Duration
+
+	// Endpoint is the kube-apiserver endpoint probed, one of supportedHealthcheckEndpoints
+	Endpoint string
+
+	// Timeout bounds the duration of each healthcheck request
+	Timeout time.Duration
+}
+
+// DefaultHealthCheckConfig returns the HealthCheckConfig used when no configuration is provided
+func DefaultHealthCheckConfig() HealthCheckConfig {
+	return HealthCheckConfig{
+		ConsecutiveSuccesses: defaultConsecutiveSuccessfulHealthchecks,
+		Interval:             defaultHealthcheckInterval,
+		MaxClusterAge:        defaultHealthcheckMaxClusterAge,
+		Endpoint:             defaultHealthcheckEndpoint,
+		Timeout:              defaultHealthcheckTimeout,
+	}
+}
+
+// Validate returns an error if the HealthCheckConfig cannot be used to healthcheck HCPs
+func (c HealthCheckConfig) Validate() error {
+	if c.ConsecutiveSuccesses < 0 {
+		return fmt.Errorf("consecutive successes must not be negative, got %d", c.ConsecutiveSuccesses)
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive, got %s", c.Interval)
+	}
+	if c.MaxClusterAge < 0 {
+		return fmt.Errorf("max cluster age must not be negative, got %s", c.MaxClusterAge)
+	}
+	if c.Timeout <= 0 {
+		return fmt.Errorf("timeout must be positive, got %s", c.Timeout)
+	}
+	if !slices.Contains(supportedHealthcheckEndpoints, c.Endpoint) {
+		return fmt.Errorf("endpoint %q is not supported, must be one of %v", c.Endpoint, supportedHealthcheckEndpoints)
+	}
+	return nil
+}
+
 // hcpReady attempts to determine the readiness of an HCP cluster. A non-nil error return indicates the cluster should not be considered ready
 // to reconcile, with the contents of the error indicating why
 //
-// An HCP is considered ready if its kube-apiserver's /livez endpoint can be polled successfully several times in a row. Polling history is
-// stored in the annotation of a configmap object within the HCP's namespace.
+// An HCP is considered ready if its kube-apiserver's configured healthcheck endpoint can be polled successfully several times in a row.
+// Polling history is stored in the healthcheckConditionType condition on the HCP's status, and the number of consecutive successful
+// healthchecks in the healthcheckSuccessesAnnotation annotation on the HCP.
 //
-// If the configmap's annotation indicates a cluster has already been polled successfully in the past, then this function returns true. If
-// the polling history indicates that additional healthchecks are needed to determine if the cluster is ready, then the /livez endpoint will
-// be probed again, and the updated probing history will be consulted once again to determine if the cluster is ready to be reconciled.
+// If the condition indicates a cluster has already passed its healthchecks in the past, then this function returns nil. If the polling
+// history indicates that additional healthchecks are needed to determine if the cluster is ready, then the endpoint will be probed again,
+// and the condition is updated with the result.
 //
-// If healthchecking should be restarted for a cluster for some reason, the annotation can be removed from the healthcheck configmap in the
-// HCP namespace, and this process will be restarted. Additionally, should healthchecking need to be skipped for any reason, the annotation
-// "routemonitor.managed.openshift.io/successful-healthchecks" can be added-to/edited-on the configmap with a large number (ie - 999) to bypass
-// this functionality
+// Healthchecks are at least HealthCheckConfig.Interval apart: reconciles triggered earlier, e.g. by the status update of the previous
+// healthcheck, return a retryAfterError until the next healthcheck is due.
+//
+// If healthchecking should be restarted for a cluster for some reason, the condition can be removed from the HCP's status, and this process
+// will be restarted. Should healthchecking need to be skipped entirely, HealthCheckConfig.ConsecutiveSuccesses can be set to 0
 func (r *HostedControlPlaneReconciler) hcpReady(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
-	if checkClusterOver1Hour(hostedcontrolplane.CreationTimestamp) {
+	cfg := r.HealthCheckConfig
+	condition := meta.FindStatusCondition(hostedcontrolplane.Status.Conditions, healthcheckConditionType)
+	if cfg.ConsecutiveSuccesses == 0 ||
+		(cfg.MaxClusterAge > 0 && checkClusterOlderThan(hostedcontrolplane.CreationTimestamp, cfg.MaxClusterAge)) {
+		metrics.SetHCPHealthGate(hostedcontrolplane.Namespace, hostedcontrolplane.Name, healthcheckSuccesses(hostedcontrolplane, condition), true)
 		return nil
 	}
-
-	healthcheckConfigMap, err := r.getHealthCheckConfigMap(ctx, hostedcontrolplane)
-	if err != nil {
-		if !kerr.IsNotFound(err) {
-			// if error is not related to the configmap not existing, return
-			return fmt.Errorf("failed to retrieve healthcheck configmap: %w", err)
+	if condition == nil {
+		var err error
+		condition, err = r.migrateLegacyHealthcheck(ctx, hostedcontrolplane)
+		if err != nil {
+			return fmt.Errorf("failed to migrate the healthcheck configmap: %w", err)
 		}
+	}
+	if condition != nil && condition.Status == metav1.ConditionTrue {
+		metrics.SetHCPHealthGate(hostedcontrolplane.Namespace, hostedcontrolplane.Name, healthcheckSuccesses(hostedcontrolplane, condition), true)
+		return nil
+	}
+	if condition != nil {
+		if wait := time.Until(condition.LastTransitionTime.Add(cfg.Interval)); wait > 0 {
+			return &retryAfterError{err: fmt.Errorf("next healthcheck is due in %s", wait.Round(time.Second)), after: wait}
+		}
+	}
+
+	healthcheckErr := healthcheckHostedControlPlane(hostedcontrolplane, cfg)
+	successes := 0
+	if healthcheckErr == nil {
+		successes = healthcheckSuccesses(hostedcontrolplane, condition) + 1
+	}
 
-		// healthcheck configmap does not exist - create it
-		healthcheckConfigMap, err = r.createHealthcheckConfigMap(ctx, hostedcontrolplane)
+	metrics.SetHCPHealthGate(hostedcontrolplane.Namespace, hostedcontrolplane.Name, successes, successes >= cfg.ConsecutiveSuccesses)
+	newCondition := buildHealthcheckCondition(successes, cfg, healthcheckErr)
+	r.recordHealthcheckEvent(hostedcontrolplane, newCondition)
+	// the annotation is patched before the condition is replaced, as the patch refreshes the in-memory HCP from the cluster
+	err := r.setHealthcheckSuccesses(ctx, hostedcontrolplane, successes)
+	if err == nil {
+		// replace the condition, so that its LastTransitionTime records this healthcheck even if its status did not change
+		newCondition.LastTransitionTime = metav1.Now()
+		meta.RemoveStatusCondition(&hostedcontrolplane.Status.Conditions, healthcheckConditionType)
+		err = r.setStatusCondition(ctx, hostedcontrolplane, newCondition)
+	}
+	if healthcheckErr != nil {
 		if err != nil {
-			return fmt.Errorf("failed to create new healthcheck configmap: %w", err)
+			err = errors.Join(healthcheckErr, err)
+			return fmt.Errorf("failed to update healthcheck condition following healthchecking failure. Errors: %w", err)
 		}
+		return fmt.Errorf("healthcheck failed for HCP: %w", healthcheckErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update healthcheck condition: %w", err)
 	}
 
-	successes := healthcheckConfigMapSuccesses(healthcheckConfigMap)
-	if successes >= consecutiveSuccessfulHealthchecks {
+	if successes >= cfg.ConsecutiveSuccesses {
 		return nil
 	}
+	return fmt.Errorf("insufficient successful health check attempts")
+}
 
-	err = healthcheckHostedControlPlane(hostedcontrolplane)
+// migrateLegacyHealthcheck moves the successful healthchecks recorded in the HCP's legacy healthcheck ConfigMap into the healthcheck
+// condition and annotation, and deletes the ConfigMap. It returns the migrated condition, or nil if there is no ConfigMap or it recorded no successes
+func (r *HostedControlPlaneReconciler) migrateLegacyHealthcheck(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (*metav1.Condition, error) {
+	configmap := corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: legacyHealthcheckConfigMapName(hostedcontrolplane), Namespace: hostedcontrolplane.Namespace}, &configmap)
 	if err != nil {
-		_, resetErr := r.resetHealthCheckSuccesses(ctx, healthcheckConfigMap)
-		if resetErr != nil {
-			err = errors.Join(err, resetErr)
-			return fmt.Errorf("failed to update configmap healthcheck count following healthchecking failure. Errors: %w", err)
+		if kerr.IsNotFound(err) {
+			return nil, nil
 		}
-		return fmt.Errorf("healthcheck failed for HCP: %w", err)
+		return nil, err
 	}
 
-	healthcheckConfigMap, err = r.addHealthCheckSuccess(ctx, healthcheckConfigMap)
-	if err != nil {
-		return fmt.Errorf("failed to increment healthcheck success count: %w", err)
+	var condition *metav1.Condition
+	if successes, err := strconv.Atoi(configmap.Annotations[legacyHealthcheckAnnotation]); err == nil && successes > 0 {
+		if err := r.setHealthcheckSuccesses(ctx, hostedcontrolplane, successes); err != nil {
+			return nil, err
+		}
+		migrated := buildHealthcheckCondition(successes, r.HealthCheckConfig, nil)
+		migrated.LastTransitionTime = metav1.Now()
+		if err := r.setStatusCondition(ctx, hostedcontrolplane, migrated); err != nil {
+			return nil, err
+		}
+		condition = meta.FindStatusCondition(hostedcontrolplane.Status.Conditions, healthcheckConditionType)
 	}
-
-	successes = healthcheckConfigMapSuccesses(healthcheckConfigMap)
-	if successes >= consecutiveSuccessfulHealthchecks {
-		return nil
+	if err := r.Delete(ctx, &configmap); err != nil && !kerr.IsNotFound(err) {
+		return nil, err
 	}
-
-	return fmt.Errorf("insufficient successful health check attempts")
+	return condition, nil
 }
 
-// getHealthCheckConfigMap retrieves the healthcheck configmap for the provided HCP from the cluster
-func (r *HostedControlPlaneReconciler) getHealthCheckConfigMap(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (corev1.ConfigMap, error) {
-	configmap := buildHealthCheckConfigMap(hostedcontrolplane)
-	err := r.Get(ctx, types.NamespacedName{Name: configmap.Name, Namespace: configmap.Namespace}, &configmap)
-	return configmap, err
+// legacyHealthcheckConfigMapName returns the name of the ConfigMap which recorded the healthchecks of an HCP before the healthcheck condition
+func legacyHealthcheckConfigMapName(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) string {
+	return fmt.Sprintf("%s-kube-apiserver-rmo-healthcheck", hostedcontrolplane.Name)
 }
 
-// createHealthcheckConfigMap creates a new configmap to track the healthchecking history of the provided HCP, and returns the resulting object along with any error encountered
-func (r *HostedControlPlaneReconciler) createHealthcheckConfigMap(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (corev1.ConfigMap, error) {
-	configmap := buildHealthCheckConfigMap(hostedcontrolplane)
-	err := r.Create(ctx, &configmap)
-	return configmap, err
+// buildHealthcheckCondition returns the healthcheckConditionType condition reflecting the given number of consecutive successful healthchecks,
+// and the result of the most recent healthcheck
+func buildHealthcheckCondition(successes int, cfg HealthCheckConfig, healthcheckErr error) metav1.Condition {
+	condition := metav1.Condition{
+		Type:    healthcheckConditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  healthcheckReasonInProgress,
+		Message: fmt.Sprintf("%d/%d consecutive healthchecks of %s succeeded", successes, cfg.ConsecutiveSuccesses, cfg.Endpoint),
+	}
+	switch {
+	case healthcheckErr != nil:
+		condition.Reason = healthcheckReasonFailed
+		condition.Message = fmt.Sprintf("%s: %v", condition.Message, healthcheckErr)
+	case successes >= cfg.ConsecutiveSuccesses:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = healthcheckReasonPassed
+	}
+	return condition
 }
 
-// buildHealthCheckConfigMap creates an empty configmap used to track the healthcheck history for a hostedcontrolplane cluster
-func buildHealthCheckConfigMap(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) corev1.ConfigMap {
-	configmap := corev1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:            fmt.Sprintf("%s-kube-apiserver-rmo-healthcheck", hostedcontrolplane.Name),
-			Namespace:       hostedcontrolplane.Namespace,
-			OwnerReferences: buildOwnerReferences(hostedcontrolplane),
-		},
+// recordHealthcheckEvent emits an Event on the HostedControlPlane reporting the outcome of the latest healthcheck
+func (r *HostedControlPlaneReconciler) recordHealthcheckEvent(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane, condition metav1.Condition) {
+	eventType := corev1.EventTypeNormal
+	if condition.Reason == healthcheckReasonFailed {
+		eventType = corev1.EventTypeWarning
 	}
-	return configmap
+	r.Recorder.Event(hostedcontrolplane, eventType, condition.Reason, condition.Message)
 }
 
-// healthcheckConfigMapSuccesses returns the number of recorded successful healthchecks the configmap has tallied under it's healthcheckAnnotation annotation.
+// healthcheckSuccesses returns the number of consecutive successful healthchecks recorded in the HCP's healthcheckSuccessesAnnotation.
 //
-// If the proper annotation cannot be found, or does not have an integer as its key, the success count is assumed to be 0
-func healthcheckConfigMapSuccesses(configmap corev1.ConfigMap) int {
-	value, found := configmap.Annotations[healthcheckAnnotation]
-	if !found {
-		// if the annotation hasn't been added yet, then healthcheck success count is 0
+// If the healthcheck condition does not exist, healthchecking is restarted, and the success count is 0. If the annotation is missing or
+// invalid, the success count is also assumed to be 0
+func healthcheckSuccesses(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane, condition *metav1.Condition) int {
+	if condition == nil {
 		return 0
 	}
 
-	successes, err := strconv.Atoi(value)
-	if err != nil {
-		// if there's an invalid value on the annotation, just assume healthcheck success count is 0
+	successes, err := strconv.Atoi(hostedcontrolplane.Annotations[healthcheckSuccessesAnnotation])
+	if err != nil || successes < 0 {
 		return 0
 	}
 	return successes
 }
 
-// resetHealthCheckSuccesses sets the value of the healthcheck success counter to 0 on the configmap on-cluster, and returns an updated copy of the configmap
-func (r *HostedControlPlaneReconciler) resetHealthCheckSuccesses(ctx context.Context, configmap corev1.ConfigMap) (corev1.ConfigMap, error) {
-	delete(configmap.Annotations, healthcheckAnnotation)
-	err := r.Update(ctx, &configmap)
-	return configmap, err
-}
-
-// addHealthCheckSuccess increments the healthcheck success counter by 1, and updates the configmap on-cluster
-func (r *HostedControlPlaneReconciler) addHealthCheckSuccess(ctx context.Context, configmap corev1.ConfigMap) (corev1.ConfigMap, error) {
-	successes := healthcheckConfigMapSuccesses(configmap)
-	successes++
-
-	if configmap.Annotations == nil {
-		configmap.Annotations = map[string]string{}
+// setHealthcheckSuccesses records the number of consecutive successful healthchecks in the HCP's healthcheckSuccessesAnnotation on-cluster.
+// The HCP is only patched if the annotation changed
+func (r *HostedControlPlaneReconciler) setHealthcheckSuccesses(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane, successes int) error {
+	value := strconv.Itoa(successes)
+	if hostedcontrolplane.Annotations[healthcheckSuccessesAnnotation] == value {
+		return nil
 	}
-	configmap.Annotations[healthcheckAnnotation] = fmt.Sprintf("%d", successes)
-	err := r.Update(ctx, &configmap)
-	return configmap, err
+	patch := client.MergeFrom(hostedcontrolplane.DeepCopy())
+	metav1.SetMetaDataAnnotation(&hostedcontrolplane.ObjectMeta, healthcheckSuccessesAnnotation, value)
+	return r.Patch(ctx, hostedcontrolplane, patch)
 }
 
 // healthcheckHostedControlPlane performs a healthcheck against the provided HCP by checking the response from its kube-apiserver's
-// /livez endpoint
-func healthcheckHostedControlPlane(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+// configured healthcheck endpoint
+func healthcheckHostedControlPlane(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane, cfg HealthCheckConfig) error {
 	controlplaneEndpoint := hostedcontrolplane.Status.ControlPlaneEndpoint.Host
 	if controlplaneEndpoint == "" {
 		return fmt.Errorf("missing .Status.ControlPlaneEndpoint.Host")
@@ -178,23 +303,22 @@ func healthcheckHostedControlPlane(hostedcontrolplane *hypershiftv1beta1.HostedC
 
 	var url string
 	var secure bool
-	if hostedcontrolplane.Spec.Platform.AWS != nil &&
-		hostedcontrolplane.Spec.Platform.AWS.EndpointAccess == hypershiftv1beta1.Private {
-		url = fmt.Sprintf("https://kube-apiserver.%s.svc.cluster.local:6443/livez", hostedcontrolplane.Namespace)
+	if getPlatform(hostedcontrolplane).EndpointAccess() == hypershiftv1beta1.Private {
+		url = fmt.Sprintf("https://kube-apiserver.%s.svc.cluster.local:6443%s", hostedcontrolplane.Namespace, cfg.Endpoint)
 		secure = false
 	} else {
-		url = fmt.Sprintf("https://%s/livez", controlplaneEndpoint)
+		url = fmt.Sprintf("https://%s%s", controlplaneEndpoint, cfg.Endpoint)
 		secure = true
 	}
 
-	return endpointOK(url, secure)
+	return endpointOK(url, secure, cfg.Timeout)
 }
 
-// endpointOK checks the readiness of the given url, and returns an error if the GET fails, or a non-200
-// response is received
-func endpointOK(endpoint string, secure bool) error {
+// endpointOK checks the readiness of the given url, and returns an error if the GET fails, does not complete within
+// the provided timeout, or a non-200 response is received
+func endpointOK(endpoint string, secure bool, timeout time.Duration) error {
 	// Create HTTP client with appropriate TLS configuration
-	client := &http.Client{}
+	client := &http.Client{Timeout: timeout}
 	if !secure {
 		// Skip certificate verification when secure is false
 		client.Transport = &http.Transport{
@@ -213,9 +337,8 @@ func endpointOK(endpoint string, secure bool) error {
 	return nil
 }
 
-// checkClusterOver1Hour determines if the HCP cluster is over one hour old
-func checkClusterOver1Hour(creationTimestamp metav1.Time) bool {
-	now := time.Now()
-	oneHourAgo := now.Add((-1 * time.Hour))
-	return creationTimestamp.Time.Before(oneHourAgo)
+// checkClusterOlderThan determines if the HCP cluster is older than the provided age
+func checkClusterOlderThan(creationTimestamp metav1.Time, age time.Duration) bool {
+	cutoff := time.Now().Add(-1 * age)
+	return creationTimestamp.Time.Before(cutoff)
 }
@@ -0,0 +1,450 @@
+package hostedcontrolplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestHealthCheckConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *HealthCheckConfig)
+		wantErr bool
+	}{
+		{
+			name:    "default config is valid",
+			modify:  func(cfg *HealthCheckConfig) {},
+			wantErr: false,
+		},
+		{
+			name:    "readyz endpoint is valid",
+			modify:  func(cfg *HealthCheckConfig) { cfg.Endpoint = "/readyz" },
+			wantErr: false,
+		},
+		{
+			name:    "version endpoint is valid",
+			modify:  func(cfg *HealthCheckConfig) { cfg.Endpoint = "/version" },
+			wantErr: false,
+		},
+		{
+			name:    "disabling healthchecking is valid",
+			modify:  func(cfg *HealthCheckConfig) { cfg.ConsecutiveSuccesses = 0 },
+			wantErr: false,
+		},
+		{
+			name:    "unsupported endpoint",
+			modify:  func(cfg *HealthCheckConfig) { cfg.Endpoint = "/healthz" },
+			wantErr: true,
+		},
+		{
+			name:    "negative consecutive successes",
+			modify:  func(cfg *HealthCheckConfig) { cfg.ConsecutiveSuccesses = -1 },
+			wantErr: true,
+		},
+		{
+			name:    "zero interval",
+			modify:  func(cfg *HealthCheckConfig) { cfg.Interval = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "zero timeout",
+			modify:  func(cfg *HealthCheckConfig) { cfg.Timeout = 0 },
+			wantErr: true,
+		},
+		{
+			name:    "negative max cluster age",
+			modify:  func(cfg *HealthCheckConfig) { cfg.MaxClusterAge = -time.Minute },
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultHealthCheckConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestBuildHealthcheckCondition(t *testing.T) {
+	cfg := DefaultHealthCheckConfig()
+	tests := []struct {
+		name           string
+		successes      int
+		healthcheckErr error
+		wantStatus     metav1.ConditionStatus
+		wantReason     string
+	}{
+		{
+			name:       "in progress",
+			successes:  2,
+			wantStatus: metav1.ConditionFalse,
+			wantReason: healthcheckReasonInProgress,
+		},
+		{
+			name:       "passed",
+			successes:  cfg.ConsecutiveSuccesses,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: healthcheckReasonPassed,
+		},
+		{
+			name:           "failed",
+			successes:      0,
+			healthcheckErr: errors.New("boom"),
+			wantStatus:     metav1.ConditionFalse,
+			wantReason:     healthcheckReasonFailed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			condition := buildHealthcheckCondition(tt.successes, cfg, tt.healthcheckErr)
+			if condition.Status != tt.wantStatus {
+				t.Errorf("expected status %q, got %q", tt.wantStatus, condition.Status)
+			}
+			if condition.Reason != tt.wantReason {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, condition.Reason)
+			}
+			if want := fmt.Sprintf("%d/%d ", tt.successes, cfg.ConsecutiveSuccesses); !strings.HasPrefix(condition.Message, want) {
+				t.Errorf("expected the condition message to start with %q, got %q", want, condition.Message)
+			}
+		})
+	}
+}
+
+func TestHostedControlPlaneReconciler_hcpReady(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/readyz" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Trust the test server's certificate for 'secure' healthchecks
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	healthyHost := strings.TrimPrefix(server.URL, "https://")
+	cfg := DefaultHealthCheckConfig()
+	cfg.Endpoint = "/readyz"
+
+	tests := []struct {
+		name              string
+		creationTimestamp time.Time
+		host              string
+		condition         *metav1.Condition
+		successes         string
+		consecutive       int
+		wantErr           bool
+		wantReason        string
+		wantSuccesses     int
+	}{
+		{
+			name:              "healthchecking is disabled",
+			creationTimestamp: time.Now(),
+			consecutive:       0,
+			wantErr:           false,
+		},
+		{
+			name:              "clusters older than the max age are not healthchecked",
+			creationTimestamp: time.Now().Add(-2 * time.Hour),
+			consecutive:       cfg.ConsecutiveSuccesses,
+			wantErr:           false,
+		},
+		{
+			name:              "clusters which previously passed are not healthchecked again",
+			creationTimestamp: time.Now(),
+			consecutive:       cfg.ConsecutiveSuccesses,
+			condition:         &metav1.Condition{Type: healthcheckConditionType, Status: metav1.ConditionTrue, Reason: healthcheckReasonPassed},
+			wantErr:           false,
+		},
+		{
+			name:              "first successful healthcheck is recorded",
+			creationTimestamp: time.Now(),
+			host:              healthyHost,
+			consecutive:       cfg.ConsecutiveSuccesses,
+			wantErr:           true,
+			wantReason:        healthcheckReasonInProgress,
+			wantSuccesses:     1,
+		},
+		{
+			name:              "final successful healthcheck marks the cluster ready",
+			creationTimestamp: time.Now(),
+			host:              healthyHost,
+			consecutive:       cfg.ConsecutiveSuccesses,
+			condition:         &metav1.Condition{Type: healthcheckConditionType, Status: metav1.ConditionFalse, Reason: healthcheckReasonInProgress, Message: "4/5 consecutive healthchecks of /readyz succeeded"},
+			successes:         "4",
+			wantErr:           false,
+			wantReason:        healthcheckReasonPassed,
+			wantSuccesses:     5,
+		},
+		{
+			name:              "failed healthcheck resets the success count",
+			creationTimestamp: time.Now(),
+			host:              "",
+			consecutive:       cfg.ConsecutiveSuccesses,
+			condition:         &metav1.Condition{Type: healthcheckConditionType, Status: metav1.ConditionFalse, Reason: healthcheckReasonInProgress, Message: "3/5 consecutive healthchecks of /readyz succeeded"},
+			successes:         "3",
+			wantErr:           true,
+			wantReason:        healthcheckReasonFailed,
+			wantSuccesses:     0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcp := &hypershiftv1beta1.HostedControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					Namespace:         "test",
+					CreationTimestamp: metav1.Time{Time: tt.creationTimestamp},
+				},
+				Status: hypershiftv1beta1.HostedControlPlaneStatus{
+					ControlPlaneEndpoint: hypershiftv1beta1.APIEndpoint{Host: tt.host},
+				},
+			}
+			if tt.condition != nil {
+				hcp.Status.Conditions = []metav1.Condition{*tt.condition}
+			}
+			if tt.successes != "" {
+				hcp.Annotations = map[string]string{healthcheckSuccessesAnnotation: tt.successes}
+			}
+			r := newTestReconciler(t, hcp)
+			r.HealthCheckConfig = cfg
+			r.HealthCheckConfig.ConsecutiveSuccesses = tt.consecutive
+
+			err := r.hcpReady(context.Background(), hcp)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if tt.wantReason == "" {
+				return
+			}
+
+			updated := &hypershiftv1beta1.HostedControlPlane{}
+			err = r.Get(context.Background(), types.NamespacedName{Name: hcp.Name, Namespace: hcp.Namespace}, updated)
+			if err != nil {
+				t.Fatalf("failed to get HostedControlPlane: %v", err)
+			}
+			condition := meta.FindStatusCondition(updated.Status.Conditions, healthcheckConditionType)
+			if condition == nil {
+				t.Fatalf("expected healthcheck condition to be set")
+			}
+			if condition.Reason != tt.wantReason {
+				t.Errorf("expected reason %q, got %q", tt.wantReason, condition.Reason)
+			}
+			if got := healthcheckSuccesses(updated, condition); got != tt.wantSuccesses {
+				t.Errorf("expected %d successes, got %d", tt.wantSuccesses, got)
+			}
+
+			wantEventType := corev1.EventTypeNormal
+			if tt.wantReason == healthcheckReasonFailed {
+				wantEventType = corev1.EventTypeWarning
+			}
+			select {
+			case event := <-r.Recorder.(*record.FakeRecorder).Events:
+				if !strings.HasPrefix(event, wantEventType+" "+tt.wantReason) {
+					t.Errorf("expected %s %s event, got %q", wantEventType, tt.wantReason, event)
+				}
+			default:
+				t.Errorf("expected a %s event to be emitted", tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestHostedControlPlaneReconciler_hcpReady_interval(t *testing.T) {
+	checks := 0
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		checks++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = server.Client().Transport
+	t.Cleanup(func() { http.DefaultTransport = defaultTransport })
+
+	hcp := &hypershiftv1beta1.HostedControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", CreationTimestamp: metav1.Now()},
+		Status: hypershiftv1beta1.HostedControlPlaneStatus{
+			ControlPlaneEndpoint: hypershiftv1beta1.APIEndpoint{Host: strings.TrimPrefix(server.URL, "https://")},
+		},
+	}
+	r := newTestReconciler(t, hcp)
+	r.HealthCheckConfig = DefaultHealthCheckConfig()
+
+	// the status update of the first healthcheck triggers another reconcile right away
+	for i := 0; i < 2; i++ {
+		updated := &hypershiftv1beta1.HostedControlPlane{}
+		if err := r.Get(context.Background(), types.NamespacedName{Name: hcp.Name, Namespace: hcp.Namespace}, updated); err != nil {
+			t.Fatalf("failed to get HostedControlPlane: %v", err)
+		}
+		if err := r.hcpReady(context.Background(), updated); err == nil {
+			t.Fatalf("expected the HostedControlPlane not to be ready")
+		}
+		hcp = updated
+	}
+
+	if checks != 1 {
+		t.Errorf("expected a single healthcheck within the interval, got %d", checks)
+	}
+	err := r.hcpReady(context.Background(), hcp)
+	var retryErr *retryAfterError
+	if !errors.As(err, &retryErr) || retryErr.after <= 0 || retryErr.after > r.HealthCheckConfig.Interval {
+		t.Errorf("expected a retry within the interval, got %v", err)
+	}
+	if got := healthcheckSuccesses(hcp, meta.FindStatusCondition(hcp.Status.Conditions, healthcheckConditionType)); got != 1 {
+		t.Errorf("expected 1 success to be recorded, got %d", got)
+	}
+}
+
+func TestHostedControlPlaneReconciler_migrateLegacyHealthcheck(t *testing.T) {
+	hcp := &hypershiftv1beta1.HostedControlPlane{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", CreationTimestamp: metav1.Now()},
+	}
+	legacy := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-kube-apiserver-rmo-healthcheck",
+			Namespace:   "test",
+			Annotations: map[string]string{legacyHealthcheckAnnotation: "5"},
+		},
+	}
+	r := newTestReconciler(t, hcp, legacy)
+	r.HealthCheckConfig = DefaultHealthCheckConfig()
+
+	if err := r.hcpReady(context.Background(), hcp); err != nil {
+		t.Fatalf("expected the HostedControlPlane which passed its healthchecks to be ready, got %v", err)
+	}
+	updated := &hypershiftv1beta1.HostedControlPlane{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: hcp.Name, Namespace: hcp.Namespace}, updated); err != nil {
+		t.Fatalf("failed to get HostedControlPlane: %v", err)
+	}
+	if !meta.IsStatusConditionTrue(updated.Status.Conditions, healthcheckConditionType) {
+		t.Errorf("expected the healthcheck condition to be migrated, got %v", updated.Status.Conditions)
+	}
+	if got := healthcheckSuccesses(updated, meta.FindStatusCondition(updated.Status.Conditions, healthcheckConditionType)); got != 5 {
+		t.Errorf("expected 5 successes to be migrated, got %d", got)
+	}
+	err := r.Get(context.Background(), types.NamespacedName{Name: legacy.Name, Namespace: legacy.Namespace}, &corev1.ConfigMap{})
+	if !kerr.IsNotFound(err) {
+		t.Errorf("expected the legacy ConfigMap to be deleted, got %v", err)
+	}
+}
+
+func TestEndpointOK(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(200 * time.Millisecond)
+		}
+		if r.URL.Path == "/unhealthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		path    string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{
+			name:    "healthy endpoint",
+			path:    "/livez",
+			timeout: time.Second,
+			wantErr: false,
+		},
+		{
+			name:    "non 200 response",
+			path:    "/unhealthy",
+			timeout: time.Second,
+			wantErr: true,
+		},
+		{
+			name:    "request exceeds timeout",
+			path:    "/slow",
+			timeout: 50 * time.Millisecond,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := endpointOK(server.URL+tt.path, false, tt.timeout)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHostedControlPlaneReconciler_migrateLegacyHealthcheck_notGated(t *testing.T) {
+	tests := []struct {
+		name              string
+		creationTimestamp time.Time
+		consecutive       int
+	}{
+		{
+			name:              "healthchecking is disabled",
+			creationTimestamp: time.Now(),
+			consecutive:       0,
+		},
+		{
+			name:              "clusters older than the max age are not healthchecked",
+			creationTimestamp: time.Now().Add(-2 * time.Hour),
+			consecutive:       defaultConsecutiveSuccessfulHealthchecks,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcp := &hypershiftv1beta1.HostedControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test", CreationTimestamp: metav1.Time{Time: tt.creationTimestamp}},
+			}
+			legacy := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-kube-apiserver-rmo-healthcheck",
+					Namespace:   "test",
+					Annotations: map[string]string{legacyHealthcheckAnnotation: "2"},
+				},
+			}
+			r := newTestReconciler(t, hcp, legacy)
+			r.HealthCheckConfig = DefaultHealthCheckConfig()
+			r.HealthCheckConfig.ConsecutiveSuccesses = tt.consecutive
+
+			if err := r.hcpReady(context.Background(), hcp); err != nil {
+				t.Fatalf("expected the HostedControlPlane to be ready, got %v", err)
+			}
+			if len(hcp.Status.Conditions) != 0 {
+				t.Errorf("expected the legacy ConfigMap not to be migrated, got %v", hcp.Status.Conditions)
+			}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: legacy.Name, Namespace: legacy.Namespace}, &corev1.ConfigMap{}); err != nil {
+				t.Errorf("expected the legacy ConfigMap to be left alone, got %v", err)
+			}
+		})
+	}
+}
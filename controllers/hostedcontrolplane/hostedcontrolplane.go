@@ -18,6 +18,7 @@ package hostedcontrolplane
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -32,13 +33,13 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 
-	avov1alpha2 "github.com/openshift/aws-vpce-operator/api/v1alpha2"
-
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -46,7 +47,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	dynatrace "github.com/openshift/route-monitor-operator/pkg/dynatrace"
-	"github.com/openshift/route-monitor-operator/pkg/rhobs"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
 )
 
 const (
@@ -56,6 +57,11 @@ const (
 	// watchResourceLabel is a label key indicating which objects this controller should reconcile against
 	watchResourceLabel = "hostedcontrolplane.routemonitoroperator.monitoring.openshift.io/managed"
 
+	// rootCAConfigMapName is the ConfigMap in the HostedControlPlane's namespace holding the CA which signed the kube-apiserver's
+	// serving certificate, under the rootCAKey
+	rootCAConfigMapName = "root-ca"
+	rootCAKey           = "ca.crt"
+
 	//fetch dynatrace secret to get dynatrace api token and tenant url
 	dynatraceSecretNamespace = "openshift-route-monitor-operator"
 	dynatraceSecretName      = "dynatrace-token" // nolint:gosec // Not a hardcoded credential
@@ -72,7 +78,13 @@ const (
 	rhobsAPIRetryTimeout = retryTimeoutMinutes * time.Minute
 )
 
-var logger logr.Logger = ctrl.Log.WithName("controllers").WithName("HostedControlPlane")
+// controllerName identifies the HostedControlPlaneReconciler in logs and metrics
+const controllerName = "HostedControlPlane"
+
+// eventRecorderName is the source of the Events emitted on HostedControlPlanes
+const eventRecorderName = "hostedcontrolplane-controller"
+
+var logger logr.Logger = ctrl.Log.WithName("controllers").WithName(controllerName)
 
 // RHOBSConfig holds RHOBS API configuration
 type RHOBSConfig struct {
@@ -83,25 +95,50 @@ type RHOBSConfig struct {
 	OIDCIssuerURL    string
 }
 
+// DynatraceConfig holds Dynatrace API configuration
+type DynatraceConfig struct {
+	// Enabled indicates whether Dynatrace HTTP monitors should be managed for HostedControlPlanes
+	Enabled bool
+}
+
 // HostedControlPlaneReconciler reconciles a HostedControlPlane object
 type HostedControlPlaneReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
 	RHOBSConfig RHOBSConfig
+
+	// ProbeProviders contains the external synthetic-monitoring backends enabled for HostedControlPlanes
+	ProbeProviders []SyntheticProbeProvider
+
+	// HealthCheckConfig configures the healthchecks an HCP must pass before its monitoring objects are deployed
+	HealthCheckConfig HealthCheckConfig
+
+	// Recorder emits Events on HostedControlPlanes
+	Recorder record.EventRecorder
 }
 
 // NewHostedControlPlaneReconciler creates a HostedControlPlaneReconciler
-func NewHostedControlPlaneReconciler(mgr manager.Manager, rhobsConfig RHOBSConfig) *HostedControlPlaneReconciler {
+func NewHostedControlPlaneReconciler(mgr manager.Manager, rhobsConfig RHOBSConfig, dynatraceConfig DynatraceConfig, healthcheckConfig HealthCheckConfig) *HostedControlPlaneReconciler {
+	recorder := mgr.GetEventRecorderFor(eventRecorderName)
 	return &HostedControlPlaneReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		RHOBSConfig: rhobsConfig,
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		RHOBSConfig:       rhobsConfig,
+		ProbeProviders:    newProbeProviders(mgr.GetClient(), recorder, rhobsConfig, dynatraceConfig),
+		HealthCheckConfig: healthcheckConfig,
+		Recorder:          recorder,
 	}
 }
 
-//+kubebuilder:rbac:groups=openshift.io,resources=hostedcontrolplanes,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups=openshift.io,resources=hostedcontrolplanes/status,verbs=get;update;patch
-//+kubebuilder:rbac:groups=openshift.io,resources=hostedcontrolplanes/finalizers,verbs=update
+//+kubebuilder:rbac:groups=hypershift.openshift.io,resources=hostedcontrolplanes,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=hypershift.openshift.io,resources=hostedcontrolplanes/status;hostedcontrolplanes/finalizers,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=route.openshift.io,resources=routes/custom-host,verbs=create;update;patch;delete
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+//+kubebuilder:rbac:groups=avo.openshift.io,resources=vpcendpoints,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
 
 // Reconcile responds to events against watched objects
 func (r *HostedControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -121,41 +158,21 @@ func (r *HostedControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return utilreconcile.RequeueWith(err)
 	}
 
-	//Create Dynatrace API client
-	valueDynatraceApiToken, valueDynatraceTenant, err := r.getDynatraceSecrets(ctx)
-	if err != nil {
-		log.Error(err, "failed to get secret for Dynatrace API client")
-		return utilreconcile.RequeueWith(err)
-	}
-	baseURL := fmt.Sprintf("%s/v1", valueDynatraceTenant)
-	dynatraceApiClient := dynatrace.NewDynatraceApiClient(baseURL, valueDynatraceApiToken)
-
 	// If the HostedControlPlane is marked for deletion, clean up
 	shouldDelete := finalizer.WasDeleteRequested(hostedcontrolplane)
 	if shouldDelete {
-		err = deleteDynatraceHttpMonitorResources(dynatraceApiClient, log, hostedcontrolplane)
-		if err != nil {
-			log.Error(err, "failed to delete Dynatrace HTTP Monitor Resources")
-			return utilreconcile.RequeueWith(err)
+		// The finalizer is only removed once every provider has successfully cleaned up its probe
+		result, err := r.reconcileProbeProviders(log, hostedcontrolplane, "delete", func(provider SyntheticProbeProvider) error {
+			return provider.Delete(ctx, log, hostedcontrolplane)
+		})
+		if err != nil || !result.IsZero() {
+			return result, err
 		}
 
-		// Delete RHOBS probe if API URL is configured
-		if r.RHOBSConfig.ProbeAPIURL != "" {
-			log.Info("Attempting to delete RHOBS probe", "cluster_id", hostedcontrolplane.Spec.ClusterID, "probe_api_url", r.RHOBSConfig.ProbeAPIURL)
-			err = r.deleteRHOBSProbe(ctx, log, hostedcontrolplane)
-			if err != nil {
-				log.Error(err, "failed to delete RHOBS probe")
-				// Check if it's a non-200 error and requeue
-				if rhobs.IsNon200Error(err) {
-					return utilreconcile.RequeueAfter(rhobsAPIRetryTimeout), nil
-				}
-				return utilreconcile.RequeueWith(err)
-			}
-		}
-
-		err := r.finalizeHostedControlPlane(ctx, log, hostedcontrolplane)
+		err = r.finalizeHostedControlPlane(ctx, log, hostedcontrolplane)
 		if err != nil {
 			log.Error(err, "failed to finalize HostedControlPlane")
+			metrics.RecordReconcileStepFailure(controllerName, "FinalizeHostedControlPlane")
 			return utilreconcile.RequeueWith(err)
 		}
 		finalizer.Remove(hostedcontrolplane, hostedcontrolplaneFinalizer)
@@ -163,6 +180,7 @@ func (r *HostedControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 		if err != nil {
 			return utilreconcile.RequeueWith(err)
 		}
+		metrics.DeleteHCPHealthGate(hostedcontrolplane.Namespace, hostedcontrolplane.Name)
 		return utilreconcile.Stop()
 	}
 
@@ -177,81 +195,81 @@ func (r *HostedControlPlaneReconciler) Reconcile(ctx context.Context, req ctrl.R
 	err = r.hcpReady(ctx, hostedcontrolplane)
 	if err != nil {
 		log.Info(fmt.Sprintf("skipped deploying monitoring objects, HostedControlPlane not ready: %v", err))
-		return utilreconcile.RequeueAfter(healthcheckIntervalSeconds * time.Second), nil
+		var retryErr *retryAfterError
+		if errors.As(err, &retryErr) {
+			return utilreconcile.RequeueAfter(retryErr.after), nil
+		}
+		return utilreconcile.RequeueAfter(r.HealthCheckConfig.Interval), nil
 	}
 
 	log.Info("Deploying internal monitoring objects")
 	err = r.deployInternalMonitoringObjects(ctx, log, hostedcontrolplane)
 	if err != nil {
 		log.Error(err, "failed to deploy internal monitoring components")
+		metrics.RecordReconcileStepFailure(controllerName, "DeployInternalMonitoringObjects")
 		return utilreconcile.RequeueWith(err)
 	}
 
-	isVpcEndpointReady, err := r.isVpcEndpointReady(ctx, hostedcontrolplane)
+	privateEndpointReady, err := r.privateEndpointReady(ctx, log, hostedcontrolplane)
 	if err != nil {
-		log.Error(err, "VPC Endpoint check failed")
+		log.Error(err, "private endpoint readiness check failed")
+		metrics.RecordReconcileStepFailure(controllerName, "PrivateEndpointReady")
 		return utilreconcile.RequeueWith(err)
 	}
-	if !isVpcEndpointReady {
-		log.Info("VPC Endpoint is not ready, delaying HTTP Monitor deployment")
-		return utilreconcile.RequeueAfter(vpcEndpointRetryTimeout), err
+	if !privateEndpointReady {
+		log.Info("Private endpoint is not ready, delaying synthetic probe deployment")
+		return utilreconcile.RequeueAfter(vpcEndpointRetryTimeout), nil
 	}
 
-	log.Info("Deploying HTTP Monitor Resources")
-	err = r.deployDynatraceHttpMonitorResources(ctx, dynatraceApiClient, log, hostedcontrolplane)
-	if err != nil {
-		log.Error(err, "failed to deploy Dynatrace HTTP Monitor Resources")
-		return utilreconcile.RequeueWith(err)
-	}
+	return r.reconcileProbeProviders(log, hostedcontrolplane, "deploy", func(provider SyntheticProbeProvider) error {
+		return provider.Ensure(ctx, log, hostedcontrolplane)
+	})
+}
 
-	// Deploy RHOBS probe if API URL is configured
-	if r.RHOBSConfig.ProbeAPIURL != "" {
-		log.Info("Deploying RHOBS probe")
-		err = r.ensureRHOBSProbe(ctx, log, hostedcontrolplane)
-		if err != nil {
-			log.Error(err, "failed to deploy RHOBS probe")
-			// Check if it's a non-200 error and requeue
-			if rhobs.IsNon200Error(err) {
-				return utilreconcile.RequeueAfter(rhobsAPIRetryTimeout), nil
+// reconcileProbeProviders runs the given action against every enabled SyntheticProbeProvider. Providers are independent of each other:
+// a failing provider is logged, reported in a Warning Event and in the returned error, but does not prevent the remaining providers from
+// being reconciled.
+//
+// Errors marked as retryable by a provider result in a delayed requeue rather than an error, using the shortest delay requested
+func (r *HostedControlPlaneReconciler) reconcileProbeProviders(log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane, action string, fn func(provider SyntheticProbeProvider) error) (ctrl.Result, error) {
+	var errs []error
+	var requeueAfter time.Duration
+	for _, provider := range r.ProbeProviders {
+		log.Info(fmt.Sprintf("Attempting to %s synthetic probe", action), "provider", provider.Name())
+		err := fn(provider)
+		if err == nil {
+			continue
+		}
+		log.Error(err, fmt.Sprintf("failed to %s synthetic probe", action), "provider", provider.Name())
+		metrics.RecordReconcileStepFailure(controllerName, fmt.Sprintf("SyntheticProbe/%s/%s", provider.Name(), action))
+		r.Recorder.Eventf(hostedcontrolplane, corev1.EventTypeWarning, eventReasonSyntheticProbeFailed, "Failed to %s %s synthetic probe: %v", action, provider.Name(), err)
+
+		var retryErr *retryAfterError
+		if errors.As(err, &retryErr) {
+			if requeueAfter == 0 || retryErr.after < requeueAfter {
+				requeueAfter = retryErr.after
 			}
-			return utilreconcile.RequeueWith(err)
+			continue
 		}
+		errs = append(errs, fmt.Errorf("%s provider: %w", provider.Name(), err))
 	}
 
-	return ctrl.Result{}, err
-}
-
-// isVpcEndpointReady checks if the VPC Endpoint associated with the HostedControlPlane is ready.
-func (r *HostedControlPlaneReconciler) isVpcEndpointReady(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (bool, error) {
-	// Create an instance of the VpcEndpoint
-	vpcEndpoint := &avov1alpha2.VpcEndpoint{}
-
-	// Construct the name and namespace of the VpcEndpoint
-	vpcEndpointName := "private-hcp"
-	vpcEndpointNamespace := hostedcontrolplane.Namespace
-
-	// Fetch the VpcEndpoint resource
-	err := r.Get(ctx, client.ObjectKey{Name: vpcEndpointName, Namespace: vpcEndpointNamespace}, vpcEndpoint)
-	if err != nil {
-		return false, err
+	if len(errs) > 0 {
+		return utilreconcile.RequeueWith(errors.Join(errs...))
+	}
+	if requeueAfter > 0 {
+		return utilreconcile.RequeueAfter(requeueAfter), nil
 	}
+	return ctrl.Result{}, nil
+}
 
-	// Check readiness using the Status field
-	// Cases can be found here: https://github.com/openshift/aws-vpce-operator/blob/main/controllers/vpcendpoint/validation.go#L148
-	switch vpcEndpoint.Status.Status {
-	case "available":
-		// VPC Endpoint is ready
-		return true, nil
-	case "pendingAcceptance", "pending", "deleting":
-		// These states mean the VPC Endpoint is transitioning, so we return false (without an error)
-		return false, nil
-	case "rejected", "failed", "deleted":
-		// Bad states, return an error
-		return false, fmt.Errorf("VPC Endpoint %s/%s is in a bad state: %s", vpcEndpointNamespace, vpcEndpointName, vpcEndpoint.Status.Status)
-	default:
-		// Unknown state, return an error
-		return false, fmt.Errorf("VPC Endpoint %s/%s is in an unknown state: %s", vpcEndpointNamespace, vpcEndpointName, vpcEndpoint.Status.Status)
+// setStatusCondition sets the given condition on the HostedControlPlane's status on-cluster. The status is only updated if the condition changed
+func (r *HostedControlPlaneReconciler) setStatusCondition(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane, condition metav1.Condition) error {
+	condition.ObservedGeneration = hostedcontrolplane.Generation
+	if !meta.SetStatusCondition(&hostedcontrolplane.Status.Conditions, condition) {
+		return nil
 	}
+	return r.Status().Update(ctx, hostedcontrolplane)
 }
 
 // deployInternalMonitoringObjects creates or updates the objects needed to monitor the kube-apiserver using cluster-internal routes
@@ -372,8 +390,17 @@ func (r *HostedControlPlaneReconciler) buildInternalMonitoringRouteMonitor(route
 			Slo: v1alpha1.SloSpec{
 				TargetAvailabilityPercent: "99.5",
 			},
-			InsecureSkipTLSVerify: true,
-			ServiceMonitorType:    v1alpha1.ServiceMonitorTypeRHOBS,
+			Probe: v1alpha1.ProbeSpec{
+				TLS: &v1alpha1.ProbeTLSSpec{
+					CA: &v1alpha1.CABundleSpec{
+						ConfigMap: &corev1.ConfigMapKeySelector{
+							LocalObjectReference: corev1.LocalObjectReference{Name: rootCAConfigMapName},
+							Key:                  rootCAKey,
+						},
+					},
+				},
+			},
+			ServiceMonitorType: v1alpha1.ServiceMonitorTypeRHOBS,
 		},
 	}
 	return routemonitor
@@ -456,10 +483,10 @@ func (r *HostedControlPlaneReconciler) SetupWithManager(mgr ctrl.Manager) error
 
 // ------------------------------synthetic-monitoring--------------------------
 
-func (r *HostedControlPlaneReconciler) getDynatraceSecrets(ctx context.Context) (string, string, error) {
-
+// getDynatraceSecrets retrieves the Dynatrace API token and tenant URL from the operator's namespace
+func getDynatraceSecrets(ctx context.Context, c client.Client) (string, string, error) {
 	secret := &corev1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{Name: dynatraceSecretName, Namespace: dynatraceSecretNamespace}, secret)
+	err := c.Get(ctx, types.NamespacedName{Name: dynatraceSecretName, Namespace: dynatraceSecretNamespace}, secret)
 	if err != nil {
 		return "", "", fmt.Errorf("error getting Kubernetes secret: %v", err)
 	}
@@ -536,7 +563,7 @@ func GetAPIServerHostname(hostedcontrolplane *hypershiftv1beta1.HostedControlPla
 	return "", fmt.Errorf("APIServer service not found in the hostedcontrolplane")
 }
 
-func ensureHttpMonitor(dynatraceApiClient *dynatrace.DynatraceApiClient, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (bool, error) {
+func ensureHttpMonitor(dynatraceApiClient *dynatrace.DynatraceApiClient, recorder record.EventRecorder, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (bool, error) {
 	clusterId := hostedcontrolplane.Spec.ClusterID
 
 	existsHttpMonitorResponse, err := dynatraceApiClient.GetDynatraceHttpMonitors(clusterId)
@@ -559,6 +586,7 @@ func ensureHttpMonitor(dynatraceApiClient *dynatrace.DynatraceApiClient, hostedc
 				return false, fmt.Errorf("failed to delete excess monitor %s for cluster id %s: %w", monitor.EntityId, clusterId, err)
 			}
 		}
+		recorder.Eventf(hostedcontrolplane, corev1.EventTypeNormal, eventReasonDynatraceMonitorsDeduplicated, "Deleted %d duplicate Dynatrace HTTP monitor(s), keeping %s", len(monitorsToDelete), existsHttpMonitorResponse.Monitors[0].EntityId)
 		return true, nil
 	}
 
@@ -570,9 +598,9 @@ func getClusterRegion(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane)
 		return "", fmt.Errorf("hostedcontrolplane is nil %v", hostedcontrolplane)
 	}
 
-	clusterRegion := hostedcontrolplane.Spec.Platform.AWS.Region
-	if clusterRegion == "" {
-		return "", fmt.Errorf("aws region is not set in hcp %v", hostedcontrolplane)
+	clusterRegion, err := getPlatform(hostedcontrolplane).Region()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine region for hcp %s/%s: %w", hostedcontrolplane.Namespace, hostedcontrolplane.Name, err)
 	}
 
 	return clusterRegion, nil
@@ -581,7 +609,7 @@ func getClusterRegion(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane)
 func determineDynatraceClusterRegionName(clusterRegion string, monitorLocationType hypershiftv1beta1.AWSEndpointAccessType) (string, error) {
 	//public
 	switch monitorLocationType {
-	case hypershiftv1beta1.PublicAndPrivate:
+	case hypershiftv1beta1.Public, hypershiftv1beta1.PublicAndPrivate:
 		return getDynatraceEquivalentClusterRegionName(clusterRegion)
 	case hypershiftv1beta1.Private:
 		// cspell:ignore backplanei03xyz
@@ -597,7 +625,7 @@ func determineDynatraceClusterRegionName(clusterRegion string, monitorLocationTy
 	}
 }
 
-func (r *HostedControlPlaneReconciler) deployDynatraceHttpMonitorResources(ctx context.Context, dynatraceApiClient *dynatrace.DynatraceApiClient, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+func deployDynatraceHttpMonitorResources(dynatraceApiClient *dynatrace.DynatraceApiClient, recorder record.EventRecorder, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
 	//if http monitor does not exist, and hcp is not marked for deletion, and hcp is ready, then create http monitor
 	//get apiserver
 	apiServerHostname, err := GetAPIServerHostname(hostedcontrolplane)
@@ -606,7 +634,7 @@ func (r *HostedControlPlaneReconciler) deployDynatraceHttpMonitorResources(ctx c
 	}
 	monitorName := strings.Replace(apiServerHostname, "api.", "", 1)
 	// apiServerHostname := hostedcontrolplane.Spec.Services[1].ServicePublishingStrategy.Route.Hostname
-	monitorLocationType := hostedcontrolplane.Spec.Platform.AWS.EndpointAccess
+	monitorLocationType := getPlatform(hostedcontrolplane).EndpointAccess()
 
 	//in hcp, spec.services.service["APIServer"].servicePublishingStrategy.route.hostname is api.test-rs1.dgcj.i3.devshift.org // cspell:ignore dgcj, devshift
 	// apiUrl := "https://api.hb-testing.j1b6.i3.devshift.org/livez"
@@ -614,7 +642,7 @@ func (r *HostedControlPlaneReconciler) deployDynatraceHttpMonitorResources(ctx c
 	apiUrl := fmt.Sprintf("https://%s/livez", apiServerHostname)
 
 	// Ensure the HTTP monitor has been created, and there is only a single instance of the monitor
-	present, err := ensureHttpMonitor(dynatraceApiClient, hostedcontrolplane)
+	present, err := ensureHttpMonitor(dynatraceApiClient, recorder, hostedcontrolplane)
 	if err != nil {
 		return fmt.Errorf("failed to validate the http monitor: %v", err)
 	}
@@ -647,6 +675,7 @@ func (r *HostedControlPlaneReconciler) deployDynatraceHttpMonitorResources(ctx c
 	}
 
 	log.Info("Created HTTP monitor ", monitorId, clusterId)
+	recorder.Eventf(hostedcontrolplane, corev1.EventTypeNormal, eventReasonDynatraceMonitorCreated, "Created Dynatrace HTTP monitor %s for %s", monitorId, apiUrl)
 
 	return nil
 }
@@ -661,97 +690,3 @@ func deleteDynatraceHttpMonitorResources(dynatraceApiClient *dynatrace.Dynatrace
 	log.Info("Successfully deleted HTTP monitor(s)")
 	return nil
 }
-
-// ensureRHOBSProbe ensures that a RHOBS probe exists for the HostedControlPlane
-func (r *HostedControlPlaneReconciler) ensureRHOBSProbe(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
-	clusterID := hostedcontrolplane.Spec.ClusterID
-	if clusterID == "" {
-		return fmt.Errorf("cluster ID is empty")
-	}
-
-	// Get monitoring URL (API server health endpoint in this case)
-	monitoringURL, err := GetAPIServerHostname(hostedcontrolplane)
-	if err != nil {
-		return fmt.Errorf("failed to get API server hostname: %w", err)
-	}
-	monitoringURL = fmt.Sprintf("https://%s/livez", monitoringURL)
-
-	// Create RHOBS client - using "hcp" as temporary tenant name
-	client := r.createRHOBSClient(log)
-
-	// Check if probe already exists
-	existingProbe, err := client.GetProbe(ctx, clusterID)
-	if err != nil {
-		return fmt.Errorf("failed to check existing probe: %w", err)
-	}
-
-	if existingProbe != nil {
-		// Handle failed probes by deleting and recreating them
-		if existingProbe.Status == "failed" {
-			log.Info("Found probe in failed state, recreating", "cluster_id", clusterID, "probe_id", existingProbe.ID)
-			// Delete the failed probe first
-			err := client.DeleteProbe(ctx, clusterID)
-			if err != nil {
-				return fmt.Errorf("failed to delete failed probe: %w", err)
-			}
-			// Continue to create new probe below
-		} else {
-			log.V(2).Info("RHOBS probe already exists", "cluster_id", clusterID, "probe_id", existingProbe.ID, "status", existingProbe.Status)
-			return nil
-		}
-	}
-
-	// Determine if cluster is private
-	isPrivate := hostedcontrolplane.Spec.Platform.AWS != nil &&
-		hostedcontrolplane.Spec.Platform.AWS.EndpointAccess == hypershiftv1beta1.Private
-
-	// Create probe request using the convenience function
-	// Note: Additional labels like management-cluster-id can be added in the future
-	probeReq := rhobs.NewClusterProbeRequest(clusterID, monitoringURL, isPrivate)
-
-	// Create the probe
-	probe, err := client.CreateProbe(ctx, probeReq)
-	if err != nil {
-		return fmt.Errorf("failed to create RHOBS probe: %w", err)
-	}
-
-	log.Info("Successfully created RHOBS probe", "cluster_id", clusterID, "probe_id", probe.ID)
-	return nil
-}
-
-// deleteRHOBSProbe deletes the RHOBS probe for the HostedControlPlane
-func (r *HostedControlPlaneReconciler) deleteRHOBSProbe(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
-	clusterID := hostedcontrolplane.Spec.ClusterID
-	if clusterID == "" {
-		return fmt.Errorf("cluster ID is empty")
-	}
-
-	// Create RHOBS client - using "hcp" as temporary tenant name
-	client := r.createRHOBSClient(log)
-
-	// Delete the probe (sets status to terminating)
-	err := client.DeleteProbe(ctx, clusterID)
-	if err != nil {
-		return fmt.Errorf("failed to delete RHOBS probe: %w", err)
-	}
-
-	log.Info("Successfully marked RHOBS probe for termination", "cluster_id", clusterID)
-	return nil
-}
-
-// createRHOBSClient creates an RHOBS client with or without OIDC authentication based on configuration
-func (r *HostedControlPlaneReconciler) createRHOBSClient(log logr.Logger) *rhobs.Client {
-	if r.RHOBSConfig.OIDCClientID != "" && r.RHOBSConfig.OIDCClientSecret != "" && r.RHOBSConfig.OIDCIssuerURL != "" {
-		oidcConfig := rhobs.OIDCConfig{
-			ClientID:     r.RHOBSConfig.OIDCClientID,
-			ClientSecret: r.RHOBSConfig.OIDCClientSecret,
-			IssuerURL:    r.RHOBSConfig.OIDCIssuerURL,
-		}
-		log.V(2).Info("Creating RHOBS client with OIDC authentication")
-		// Use configurable tenant name in URL path, OIDC client ID is used for authentication headers
-		return rhobs.NewClientWithOIDC(r.RHOBSConfig.ProbeAPIURL, r.RHOBSConfig.Tenant, oidcConfig, log)
-	}
-
-	log.V(2).Info("Creating RHOBS client without authentication")
-	return rhobs.NewClient(r.RHOBSConfig.ProbeAPIURL, r.RHOBSConfig.Tenant, log)
-}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 
 	"testing"
 	"time"
@@ -21,6 +22,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -261,6 +263,27 @@ func TestHostedControlPlaneReconciler_buildInternalMonitoringRouteMonitor(t *tes
 				return true, ""
 			},
 		},
+		{
+			name: "routemonitor verifies the kube-apiserver with the hosted cluster's root CA",
+			args: args{
+				route:              route,
+				hostedcontrolplane: &hcp,
+				apiServerPort:      6443,
+			},
+			eval: func(routemonitor v1alpha1.RouteMonitor) (passed bool, reason string) {
+				if routemonitor.Spec.InsecureSkipTLSVerify {
+					return false, ".spec.insecureSkipTLSVerify is set"
+				}
+				tls := routemonitor.Spec.Probe.TLS
+				if tls == nil || tls.CA == nil || tls.CA.ConfigMap == nil {
+					return false, ".spec.probe.tls.ca.configMap is not set"
+				}
+				if tls.CA.ConfigMap.Name != rootCAConfigMapName || tls.CA.ConfigMap.Key != rootCAKey {
+					return false, fmt.Sprintf("incorrect CA bundle reference: %#v", *tls.CA.ConfigMap)
+				}
+				return true, ""
+			},
+		},
 		{
 			name: "routemonitor's ownerrefs are set correctly",
 			args: args{
@@ -746,11 +769,12 @@ func newTestReconciler(t *testing.T, objs ...client.Object) *HostedControlPlaneR
 		t.Errorf("unable to add avov1alpha2 scheme to test: %v", err)
 	}
 
-	client := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).Build()
+	client := fake.NewClientBuilder().WithScheme(s).WithObjects(objs...).WithStatusSubresource(&hypershiftv1beta1.HostedControlPlane{}).Build()
 
 	r := &HostedControlPlaneReconciler{
-		Client: client,
-		Scheme: s,
+		Client:   client,
+		Scheme:   s,
+		Recorder: record.NewFakeRecorder(100),
 	}
 	return r
 }
@@ -853,7 +877,7 @@ func TestHostedControlPlaneReconciler_GetDynatraceSecrets(t *testing.T) {
 			}
 
 			// Call the method to test
-			apiToken, tenantUrl, err := r.getDynatraceSecrets(ctx)
+			apiToken, tenantUrl, err := getDynatraceSecrets(ctx, r.Client)
 
 			if (err != nil) != tt.expectError {
 				t.Errorf("Expected error: %v, but got: %v", tt.expectError, err)
@@ -965,8 +989,6 @@ func TestDeployDynatraceHTTPMonitorResources(t *testing.T) {
 			mockServer := setupMockServer(createMockHandlerFunc(tt.mockServerResponse, tt.mockServerStatusCode))
 			apiClient := dynatrace.NewDynatraceApiClient(mockServer, "mockedToken")
 
-			r := newTestReconciler(t)
-
 			ctx := context.Background()
 
 			// Initialize the HostedControlPlane object
@@ -994,101 +1016,12 @@ func TestDeployDynatraceHTTPMonitorResources(t *testing.T) {
 
 			// Call the function under test
 			// nolint:errcheck // this was a placeholder test, and does not work under the covers - we need to mock multiple calls to the mocked API server
-			r.deployDynatraceHttpMonitorResources(ctx, apiClient, log, hostedControlPlane)
+			deployDynatraceHttpMonitorResources(apiClient, record.NewFakeRecorder(10), log, hostedControlPlane)
 
 		})
 	}
 }
 
-func TestIsVpcEndpointReady(t *testing.T) {
-	tests := []struct {
-		name              string
-		vpcEndpointStatus string
-		expectedResult    bool
-		expectedError     bool
-	}{
-		{
-			name:              "VpcEndpoint is available",
-			vpcEndpointStatus: "available",
-			expectedResult:    true,
-			expectedError:     false,
-		},
-		{
-			name:              "VpcEndpoint is pending",
-			vpcEndpointStatus: "pending",
-			expectedResult:    false,
-			expectedError:     false, // Pending is not an error, just not ready
-		},
-		{
-			name:              "VpcEndpoint is rejected",
-			vpcEndpointStatus: "rejected",
-			expectedResult:    false,
-			expectedError:     true,
-		},
-		{
-			name:              "VpcEndpoint is failed",
-			vpcEndpointStatus: "failed",
-			expectedResult:    false,
-			expectedError:     true,
-		},
-		{
-			name:              "VpcEndpoint not found",
-			vpcEndpointStatus: "",
-			expectedResult:    false,
-			expectedError:     true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-
-			// client := fake.NewClientBuilder().Build()
-
-			// Create a mock HostedControlPlane instance
-			hcp := &hypershiftv1beta1.HostedControlPlane{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      "test-hostedcontrolplane",
-					Namespace: "default",
-				},
-			}
-
-			r := newTestReconciler(t)
-			ctx := context.Background()
-			// r.Create(ctx, hcp)
-
-			// Mocking the VpcEndpoint
-			if tt.vpcEndpointStatus != "" {
-				vpcEndpointTest := &avov1alpha2.VpcEndpoint{
-					ObjectMeta: metav1.ObjectMeta{
-						Name:      "private-hcp",
-						Namespace: "default",
-					},
-					Status: avov1alpha2.VpcEndpointStatus{
-						Status: tt.vpcEndpointStatus,
-					},
-				}
-				err := r.Create(ctx, vpcEndpointTest)
-				if err != nil {
-					t.Fatalf("Failed to create mock VpcEndpoint resource: %v", err)
-				}
-			}
-
-			// Test the function
-			result, err := r.isVpcEndpointReady(context.Background(), hcp)
-
-			// Validate the results
-			if result != tt.expectedResult {
-				t.Errorf("expected result %v, but got %v", tt.expectedResult, result)
-			}
-
-			// Check if error status matches expectedError
-			if (err != nil) != tt.expectedError {
-				t.Errorf("expected error: %v, but got error: %v", tt.expectedError, err != nil)
-			}
-		})
-	}
-}
-
 func TestEnsureHttpMonitor(t *testing.T) {
 	tests := []struct {
 		name               string
@@ -1152,7 +1085,8 @@ func TestEnsureHttpMonitor(t *testing.T) {
 			apiClient := dynatrace.NewDynatraceApiClient(mockServerURL, "mockedToken")
 
 			// Call the function to test
-			exists, err := ensureHttpMonitor(apiClient, tt.hostedControlPlane)
+			recorder := record.NewFakeRecorder(10)
+			exists, err := ensureHttpMonitor(apiClient, recorder, tt.hostedControlPlane)
 
 			// Validate the expected values
 			if exists != tt.expectedExists {
@@ -1295,6 +1229,13 @@ func TestDetermineDynatraceClusterRegionName(t *testing.T) {
 			expectId:            "N. Virginia", // Adjust according to your mapping
 			expectError:         false,
 		},
+		{
+			name:                "Valid Public region",
+			clusterRegion:       "us-east-1",
+			monitorLocationType: hypershiftv1beta1.Public,
+			expectId:            "N. Virginia",
+			expectError:         false,
+		},
 		{
 			name:                "Valid Private region",
 			clusterRegion:       "us-west-2",
@@ -1368,6 +1309,33 @@ func TestGetClusterRegion(t *testing.T) {
 			expectRegion:       "",
 			expectError:        true,
 		},
+		{
+			name: "Valid Azure location",
+			hostedControlPlane: &hypershiftv1beta1.HostedControlPlane{
+				Spec: hypershiftv1beta1.HostedControlPlaneSpec{
+					Platform: hypershiftv1beta1.PlatformSpec{
+						Type: hypershiftv1beta1.AzurePlatform,
+						Azure: &hypershiftv1beta1.AzurePlatformSpec{
+							Location: "eastus",
+						},
+					},
+				},
+			},
+			expectRegion: "eastus",
+			expectError:  false,
+		},
+		{
+			name: "KubeVirt has no region",
+			hostedControlPlane: &hypershiftv1beta1.HostedControlPlane{
+				Spec: hypershiftv1beta1.HostedControlPlaneSpec{
+					Platform: hypershiftv1beta1.PlatformSpec{
+						Type: hypershiftv1beta1.KubevirtPlatform,
+					},
+				},
+			},
+			expectRegion: "",
+			expectError:  true,
+		},
 		{
 			name: "AWS region is empty",
 			hostedControlPlane: &hypershiftv1beta1.HostedControlPlane{
@@ -1399,3 +1367,40 @@ func TestGetClusterRegion(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureHttpMonitor_deduplicatesMonitors(t *testing.T) {
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			_, _ = w.Write([]byte(`{"monitors": [{"entityId": "first"}, {"entityId": "second"}, {"entityId": "third"}]}`))
+		case http.MethodDelete:
+			deleted = append(deleted, strings.TrimPrefix(r.URL.Path, "/synthetic/monitors/"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	apiClient := dynatrace.NewDynatraceApiClient(server.URL, "mockedToken")
+	recorder := record.NewFakeRecorder(10)
+	hcp := &hypershiftv1beta1.HostedControlPlane{Spec: hypershiftv1beta1.HostedControlPlaneSpec{ClusterID: "duplicated-cluster-id"}}
+
+	exists, err := ensureHttpMonitor(apiClient, recorder, hcp)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !exists {
+		t.Errorf("expected the first monitor to be kept")
+	}
+	if len(deleted) != 2 || deleted[0] != "second" || deleted[1] != "third" {
+		t.Errorf("expected the duplicate monitors to be deleted, got %v", deleted)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.HasPrefix(event, "Normal "+eventReasonDynatraceMonitorsDeduplicated) {
+			t.Errorf("unexpected event: %s", event)
+		}
+	default:
+		t.Errorf("expected a %s event", eventReasonDynatraceMonitorsDeduplicated)
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostedcontrolplane
+
+import (
+	"fmt"
+
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+)
+
+// gcpPlatform is the hypershift PlatformType for GCP HostedControlPlanes. It is not yet defined by the vendored hypershift API
+const gcpPlatform hypershiftv1beta1.PlatformType = "GCP"
+
+// hostedControlPlanePlatform resolves the platform-specific details of a HostedControlPlane needed to monitor it, so that
+// callers do not need to inspect the platform-specific sections of the HostedControlPlane's spec themselves
+type hostedControlPlanePlatform interface {
+	// Type returns the hypershift platform the HostedControlPlane is running on
+	Type() hypershiftv1beta1.PlatformType
+
+	// Region returns the cloud region the HostedControlPlane is running in, or an error if the platform has no notion of regions
+	Region() (string, error)
+
+	// EndpointAccess returns the publishing scope of the HostedControlPlane's kube-apiserver. Platforms which do not support
+	// private endpoints are always considered Public
+	EndpointAccess() hypershiftv1beta1.AWSEndpointAccessType
+}
+
+// getPlatform returns the hostedControlPlanePlatform for the provided HostedControlPlane. HostedControlPlanes which do not set
+// .Spec.Platform.Type are identified by which platform-specific section of their spec is populated
+func getPlatform(hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) hostedControlPlanePlatform {
+	platform := hostedcontrolplane.Spec.Platform
+	switch {
+	case platform.Type == hypershiftv1beta1.AWSPlatform, platform.Type == "" && platform.AWS != nil:
+		return &awsPlatform{spec: platform.AWS}
+	case platform.Type == hypershiftv1beta1.AzurePlatform, platform.Type == "" && platform.Azure != nil:
+		return &azurePlatform{spec: platform.Azure}
+	case platform.Type == gcpPlatform, platform.Type == hypershiftv1beta1.KubevirtPlatform, platform.Type == hypershiftv1beta1.NonePlatform:
+		return &regionlessPlatform{platformType: platform.Type}
+	case platform.Type == "":
+		return &regionlessPlatform{platformType: hypershiftv1beta1.NonePlatform}
+	default:
+		// Platforms without dedicated support (ie - Agent, PowerVS) are monitored as if they were publicly accessible
+		return &regionlessPlatform{platformType: platform.Type}
+	}
+}
+
+// ------------------------------aws-------------------------------------------
+
+type awsPlatform struct {
+	spec *hypershiftv1beta1.AWSPlatformSpec
+}
+
+func (p *awsPlatform) Type() hypershiftv1beta1.PlatformType {
+	return hypershiftv1beta1.AWSPlatform
+}
+
+func (p *awsPlatform) Region() (string, error) {
+	if p.spec == nil || p.spec.Region == "" {
+		return "", fmt.Errorf("aws region is not set")
+	}
+	return p.spec.Region, nil
+}
+
+func (p *awsPlatform) EndpointAccess() hypershiftv1beta1.AWSEndpointAccessType {
+	if p.spec == nil || p.spec.EndpointAccess == "" {
+		// Matches hypershift's default for .Spec.Platform.AWS.EndpointAccess
+		return hypershiftv1beta1.Public
+	}
+	return p.spec.EndpointAccess
+}
+
+// ------------------------------azure-----------------------------------------
+
+type azurePlatform struct {
+	spec *hypershiftv1beta1.AzurePlatformSpec
+}
+
+func (p *azurePlatform) Type() hypershiftv1beta1.PlatformType {
+	return hypershiftv1beta1.AzurePlatform
+}
+
+func (p *azurePlatform) Region() (string, error) {
+	if p.spec == nil || p.spec.Location == "" {
+		return "", fmt.Errorf("azure location is not set")
+	}
+	return p.spec.Location, nil
+}
+
+// EndpointAccess is always Public: the hypershift API has no equivalent of AWS' endpoint access for Azure, so private Azure
+// HostedControlPlanes can not be told apart and are monitored like public ones
+func (p *azurePlatform) EndpointAccess() hypershiftv1beta1.AWSEndpointAccessType {
+	return hypershiftv1beta1.Public
+}
+
+// ------------------------------regionless------------------------------------
+
+// regionlessPlatform covers platforms whose HostedControlPlane spec carries no region and only supports publicly accessible
+// kube-apiservers, ie - GCP, KubeVirt, and None
+type regionlessPlatform struct {
+	platformType hypershiftv1beta1.PlatformType
+}
+
+func (p *regionlessPlatform) Type() hypershiftv1beta1.PlatformType {
+	return p.platformType
+}
+
+func (p *regionlessPlatform) Region() (string, error) {
+	return "", fmt.Errorf("region is not available for platform %q", p.platformType)
+}
+
+func (p *regionlessPlatform) EndpointAccess() hypershiftv1beta1.AWSEndpointAccessType {
+	return hypershiftv1beta1.Public
+}
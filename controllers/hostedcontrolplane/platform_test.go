@@ -0,0 +1,123 @@
+package hostedcontrolplane
+
+import (
+	"testing"
+
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+)
+
+func TestGetPlatform(t *testing.T) {
+	tests := []struct {
+		name               string
+		platform           hypershiftv1beta1.PlatformSpec
+		wantType           hypershiftv1beta1.PlatformType
+		wantRegion         string
+		wantRegionErr      bool
+		wantEndpointAccess hypershiftv1beta1.AWSEndpointAccessType
+	}{
+		{
+			name: "AWS private",
+			platform: hypershiftv1beta1.PlatformSpec{
+				Type: hypershiftv1beta1.AWSPlatform,
+				AWS:  &hypershiftv1beta1.AWSPlatformSpec{Region: "us-east-1", EndpointAccess: hypershiftv1beta1.Private},
+			},
+			wantType:           hypershiftv1beta1.AWSPlatform,
+			wantRegion:         "us-east-1",
+			wantEndpointAccess: hypershiftv1beta1.Private,
+		},
+		{
+			name: "AWS without type set is identified by its spec",
+			platform: hypershiftv1beta1.PlatformSpec{
+				AWS: &hypershiftv1beta1.AWSPlatformSpec{Region: "us-west-2", EndpointAccess: hypershiftv1beta1.PublicAndPrivate},
+			},
+			wantType:           hypershiftv1beta1.AWSPlatform,
+			wantRegion:         "us-west-2",
+			wantEndpointAccess: hypershiftv1beta1.PublicAndPrivate,
+		},
+		{
+			name: "AWS without endpoint access defaults to Public",
+			platform: hypershiftv1beta1.PlatformSpec{
+				Type: hypershiftv1beta1.AWSPlatform,
+				AWS:  &hypershiftv1beta1.AWSPlatformSpec{Region: "us-west-2"},
+			},
+			wantType:           hypershiftv1beta1.AWSPlatform,
+			wantRegion:         "us-west-2",
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+		{
+			name:               "AWS type without AWS spec does not panic",
+			platform:           hypershiftv1beta1.PlatformSpec{Type: hypershiftv1beta1.AWSPlatform},
+			wantType:           hypershiftv1beta1.AWSPlatform,
+			wantRegionErr:      true,
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+		{
+			name: "Azure",
+			platform: hypershiftv1beta1.PlatformSpec{
+				Type:  hypershiftv1beta1.AzurePlatform,
+				Azure: &hypershiftv1beta1.AzurePlatformSpec{Location: "eastus"},
+			},
+			wantType:           hypershiftv1beta1.AzurePlatform,
+			wantRegion:         "eastus",
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+		{
+			name:               "GCP",
+			platform:           hypershiftv1beta1.PlatformSpec{Type: gcpPlatform},
+			wantType:           gcpPlatform,
+			wantRegionErr:      true,
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+		{
+			name:               "KubeVirt",
+			platform:           hypershiftv1beta1.PlatformSpec{Type: hypershiftv1beta1.KubevirtPlatform},
+			wantType:           hypershiftv1beta1.KubevirtPlatform,
+			wantRegionErr:      true,
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+		{
+			name:               "None",
+			platform:           hypershiftv1beta1.PlatformSpec{Type: hypershiftv1beta1.NonePlatform},
+			wantType:           hypershiftv1beta1.NonePlatform,
+			wantRegionErr:      true,
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+		{
+			name:               "empty platform is treated as None",
+			platform:           hypershiftv1beta1.PlatformSpec{},
+			wantType:           hypershiftv1beta1.NonePlatform,
+			wantRegionErr:      true,
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+		{
+			name:               "unsupported platforms are treated as public",
+			platform:           hypershiftv1beta1.PlatformSpec{Type: hypershiftv1beta1.AgentPlatform},
+			wantType:           hypershiftv1beta1.AgentPlatform,
+			wantRegionErr:      true,
+			wantEndpointAccess: hypershiftv1beta1.Public,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcp := &hypershiftv1beta1.HostedControlPlane{
+				Spec: hypershiftv1beta1.HostedControlPlaneSpec{Platform: tt.platform},
+			}
+			platform := getPlatform(hcp)
+
+			if platform.Type() != tt.wantType {
+				t.Errorf("expected type %q, got %q", tt.wantType, platform.Type())
+			}
+			region, err := platform.Region()
+			if (err != nil) != tt.wantRegionErr {
+				t.Errorf("expected region error: %v, got: %v", tt.wantRegionErr, err)
+			}
+			if region != tt.wantRegion {
+				t.Errorf("expected region %q, got %q", tt.wantRegion, region)
+			}
+			if platform.EndpointAccess() != tt.wantEndpointAccess {
+				t.Errorf("expected endpoint access %q, got %q", tt.wantEndpointAccess, platform.EndpointAccess())
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostedcontrolplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	avov1alpha2 "github.com/openshift/aws-vpce-operator/api/v1alpha2"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// privateEndpointConditionType is the type of the condition added to a HostedControlPlane's status, which records the
+	// readiness of the private endpoint used to probe the cluster's kube-apiserver
+	privateEndpointConditionType = "routemonitor.managed.openshift.io/PrivateEndpointReady"
+
+	// privateEndpointReasonReady indicates the private endpoint is ready to be probed through
+	privateEndpointReasonReady = "PrivateEndpointReady"
+	// privateEndpointReasonNotReady indicates the private endpoint is still being provisioned
+	privateEndpointReasonNotReady = "PrivateEndpointNotReady"
+	// privateEndpointReasonFailed indicates the private endpoint is in a bad state, or could not be checked
+	privateEndpointReasonFailed = "PrivateEndpointFailed"
+	// privateEndpointReasonNotRequired indicates the HCP's kube-apiserver is publicly accessible, so no private endpoint is needed
+	privateEndpointReasonNotRequired = "PrivateEndpointNotRequired"
+	// privateEndpointReasonCheckUnavailable indicates the API used to check the private endpoint is not installed on the cluster
+	privateEndpointReasonCheckUnavailable = "PrivateEndpointCheckUnavailable"
+
+	// awsPrivateLinkVpcEndpointName is the name of the VpcEndpoint created by the aws-vpce-operator in each private HCP's namespace
+	awsPrivateLinkVpcEndpointName = "private-hcp"
+)
+
+// privateEndpointReadinessCheck determines whether the private connectivity required to probe a HostedControlPlane's kube-apiserver
+// has been provisioned. Implementations are selected by newPrivateEndpointReadinessCheck based on the HCP's platform and endpoint access
+type privateEndpointReadinessCheck interface {
+	// Name returns a short identifier for the check, used in logs and status
+	Name() string
+
+	// Ready returns true if the private endpoint is ready. A false return with a nil error indicates the endpoint is still
+	// being provisioned, while an error indicates the endpoint is in a bad state or could not be checked
+	Ready(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (bool, error)
+}
+
+// newPrivateEndpointReadinessCheck returns the privateEndpointReadinessCheck for the provided HostedControlPlane, or nil if the HCP's
+// kube-apiserver can be probed without a private endpoint
+func newPrivateEndpointReadinessCheck(c client.Client, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) privateEndpointReadinessCheck {
+	platform := getPlatform(hostedcontrolplane)
+	if platform.EndpointAccess() != hypershiftv1beta1.Private {
+		return nil
+	}
+
+	switch platform.Type() {
+	case hypershiftv1beta1.AWSPlatform:
+		return &awsPrivateLinkReadinessCheck{Client: c}
+	default:
+		// Other platforms do not currently support private kube-apiservers (see getPlatform), so there is nothing to check.
+		// Checks for additional private connectivity mechanisms (ie - GCP Private Service Connect) belong here once supported
+		return nil
+	}
+}
+
+// privateEndpointReady runs the privateEndpointReadinessCheck applicable to the provided HostedControlPlane, and records the result
+// in the privateEndpointConditionType condition on the HCP's status.
+//
+// If the API backing the check is not installed on the cluster, the check is skipped rather than failing every reconcile
+func (r *HostedControlPlaneReconciler) privateEndpointReady(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (bool, error) {
+	check := newPrivateEndpointReadinessCheck(r.Client, hostedcontrolplane)
+	if check == nil {
+		condition := metav1.Condition{
+			Type:    privateEndpointConditionType,
+			Status:  metav1.ConditionTrue,
+			Reason:  privateEndpointReasonNotRequired,
+			Message: "kube-apiserver is publicly accessible",
+		}
+		return true, r.setStatusCondition(ctx, hostedcontrolplane, condition)
+	}
+
+	ready, err := check.Ready(ctx, hostedcontrolplane)
+	condition := metav1.Condition{
+		Type: privateEndpointConditionType,
+	}
+	switch {
+	case isAPIUnavailable(err):
+		log.Info("Private endpoint API is not installed, skipping readiness check", "check", check.Name(), "error", err.Error())
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = privateEndpointReasonCheckUnavailable
+		condition.Message = fmt.Sprintf("%s readiness could not be checked: %v", check.Name(), err)
+		ready, err = true, nil
+	case err != nil:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = privateEndpointReasonFailed
+		condition.Message = fmt.Sprintf("%s: %v", check.Name(), err)
+	case !ready:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = privateEndpointReasonNotReady
+		condition.Message = fmt.Sprintf("%s is not ready", check.Name())
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = privateEndpointReasonReady
+		condition.Message = fmt.Sprintf("%s is ready", check.Name())
+	}
+
+	updateErr := r.setStatusCondition(ctx, hostedcontrolplane, condition)
+	if updateErr != nil {
+		return false, errors.Join(err, fmt.Errorf("failed to update private endpoint condition: %w", updateErr))
+	}
+	return ready, err
+}
+
+// isAPIUnavailable returns true if the error indicates the requested kind is not served by the cluster, or is not known to the client
+func isAPIUnavailable(err error) bool {
+	return err != nil && (meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err))
+}
+
+// ------------------------------aws-privatelink-------------------------------
+
+// awsPrivateLinkReadinessCheck checks the VpcEndpoint managed by the aws-vpce-operator for the HostedControlPlane
+type awsPrivateLinkReadinessCheck struct {
+	Client client.Client
+}
+
+var _ privateEndpointReadinessCheck = &awsPrivateLinkReadinessCheck{}
+
+func (c *awsPrivateLinkReadinessCheck) Name() string {
+	return "AWS PrivateLink"
+}
+
+// Ready checks if the VPC Endpoint associated with the HostedControlPlane is ready.
+func (c *awsPrivateLinkReadinessCheck) Ready(ctx context.Context, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (bool, error) {
+	// Create an instance of the VpcEndpoint
+	vpcEndpoint := &avov1alpha2.VpcEndpoint{}
+
+	// Construct the name and namespace of the VpcEndpoint
+	vpcEndpointName := awsPrivateLinkVpcEndpointName
+	vpcEndpointNamespace := hostedcontrolplane.Namespace
+
+	// Fetch the VpcEndpoint resource
+	err := c.Client.Get(ctx, client.ObjectKey{Name: vpcEndpointName, Namespace: vpcEndpointNamespace}, vpcEndpoint)
+	if err != nil {
+		return false, err
+	}
+
+	// Check readiness using the Status field
+	// Cases can be found here: https://github.com/openshift/aws-vpce-operator/blob/main/controllers/vpcendpoint/validation.go#L148
+	switch vpcEndpoint.Status.Status {
+	case "available":
+		// VPC Endpoint is ready
+		return true, nil
+	case "pendingAcceptance", "pending", "deleting":
+		// These states mean the VPC Endpoint is transitioning, so we return false (without an error)
+		return false, nil
+	case "rejected", "failed", "deleted":
+		// Bad states, return an error
+		return false, fmt.Errorf("VPC Endpoint %s/%s is in a bad state: %s", vpcEndpointNamespace, vpcEndpointName, vpcEndpoint.Status.Status)
+	default:
+		// Unknown state, return an error
+		return false, fmt.Errorf("VPC Endpoint %s/%s is in an unknown state: %s", vpcEndpointNamespace, vpcEndpointName, vpcEndpoint.Status.Status)
+	}
+}
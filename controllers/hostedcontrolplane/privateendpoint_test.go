@@ -0,0 +1,257 @@
+package hostedcontrolplane
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/testr"
+	avov1alpha2 "github.com/openshift/aws-vpce-operator/api/v1alpha2"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAWSPrivateLinkReadinessCheck_Ready(t *testing.T) {
+	tests := []struct {
+		name              string
+		vpcEndpointStatus string
+		expectedResult    bool
+		expectedError     bool
+	}{
+		{
+			name:              "VpcEndpoint is available",
+			vpcEndpointStatus: "available",
+			expectedResult:    true,
+			expectedError:     false,
+		},
+		{
+			name:              "VpcEndpoint is pending",
+			vpcEndpointStatus: "pending",
+			expectedResult:    false,
+			expectedError:     false, // Pending is not an error, just not ready
+		},
+		{
+			name:              "VpcEndpoint is rejected",
+			vpcEndpointStatus: "rejected",
+			expectedResult:    false,
+			expectedError:     true,
+		},
+		{
+			name:              "VpcEndpoint is failed",
+			vpcEndpointStatus: "failed",
+			expectedResult:    false,
+			expectedError:     true,
+		},
+		{
+			name:              "VpcEndpoint not found",
+			vpcEndpointStatus: "",
+			expectedResult:    false,
+			expectedError:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcp := &hypershiftv1beta1.HostedControlPlane{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-hostedcontrolplane",
+					Namespace: "default",
+				},
+			}
+
+			r := newTestReconciler(t)
+			ctx := context.Background()
+
+			if tt.vpcEndpointStatus != "" {
+				vpcEndpointTest := &avov1alpha2.VpcEndpoint{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      awsPrivateLinkVpcEndpointName,
+						Namespace: "default",
+					},
+					Status: avov1alpha2.VpcEndpointStatus{
+						Status: tt.vpcEndpointStatus,
+					},
+				}
+				err := r.Create(ctx, vpcEndpointTest)
+				if err != nil {
+					t.Fatalf("Failed to create mock VpcEndpoint resource: %v", err)
+				}
+			}
+
+			check := &awsPrivateLinkReadinessCheck{Client: r.Client}
+			result, err := check.Ready(ctx, hcp)
+
+			if result != tt.expectedResult {
+				t.Errorf("expected result %v, but got %v", tt.expectedResult, result)
+			}
+			if (err != nil) != tt.expectedError {
+				t.Errorf("expected error: %v, but got error: %v", tt.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestNewPrivateEndpointReadinessCheck(t *testing.T) {
+	tests := []struct {
+		name     string
+		platform hypershiftv1beta1.PlatformSpec
+		want     string
+	}{
+		{
+			name: "private AWS clusters are checked through PrivateLink",
+			platform: hypershiftv1beta1.PlatformSpec{
+				Type: hypershiftv1beta1.AWSPlatform,
+				AWS:  &hypershiftv1beta1.AWSPlatformSpec{EndpointAccess: hypershiftv1beta1.Private},
+			},
+			want: "AWS PrivateLink",
+		},
+		{
+			name: "public AWS clusters are not checked",
+			platform: hypershiftv1beta1.PlatformSpec{
+				Type: hypershiftv1beta1.AWSPlatform,
+				AWS:  &hypershiftv1beta1.AWSPlatformSpec{EndpointAccess: hypershiftv1beta1.Public},
+			},
+		},
+		{
+			name: "public and private AWS clusters are not checked",
+			platform: hypershiftv1beta1.PlatformSpec{
+				Type: hypershiftv1beta1.AWSPlatform,
+				AWS:  &hypershiftv1beta1.AWSPlatformSpec{EndpointAccess: hypershiftv1beta1.PublicAndPrivate},
+			},
+		},
+		{
+			name:     "KubeVirt clusters are not checked",
+			platform: hypershiftv1beta1.PlatformSpec{Type: hypershiftv1beta1.KubevirtPlatform},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcp := &hypershiftv1beta1.HostedControlPlane{
+				Spec: hypershiftv1beta1.HostedControlPlaneSpec{Platform: tt.platform},
+			}
+			check := newPrivateEndpointReadinessCheck(nil, hcp)
+			if tt.want == "" {
+				if check != nil {
+					t.Errorf("expected no check, got %q", check.Name())
+				}
+				return
+			}
+			if check == nil || check.Name() != tt.want {
+				t.Errorf("expected check %q, got %v", tt.want, check)
+			}
+		})
+	}
+}
+
+func TestHostedControlPlaneReconciler_privateEndpointReady(t *testing.T) {
+	privateAWS := hypershiftv1beta1.PlatformSpec{
+		Type: hypershiftv1beta1.AWSPlatform,
+		AWS:  &hypershiftv1beta1.AWSPlatformSpec{EndpointAccess: hypershiftv1beta1.Private},
+	}
+
+	tests := []struct {
+		name              string
+		platform          hypershiftv1beta1.PlatformSpec
+		vpcEndpointStatus string
+		missingCRD        bool
+		wantReady         bool
+		wantErr           bool
+		wantStatus        metav1.ConditionStatus
+		wantReason        string
+	}{
+		{
+			name: "public clusters do not require a private endpoint",
+			platform: hypershiftv1beta1.PlatformSpec{
+				Type: hypershiftv1beta1.AWSPlatform,
+				AWS:  &hypershiftv1beta1.AWSPlatformSpec{EndpointAccess: hypershiftv1beta1.Public},
+			},
+			wantReady:  true,
+			wantStatus: metav1.ConditionTrue,
+			wantReason: privateEndpointReasonNotRequired,
+		},
+		{
+			name:              "available VpcEndpoint",
+			platform:          privateAWS,
+			vpcEndpointStatus: "available",
+			wantReady:         true,
+			wantStatus:        metav1.ConditionTrue,
+			wantReason:        privateEndpointReasonReady,
+		},
+		{
+			name:              "pending VpcEndpoint",
+			platform:          privateAWS,
+			vpcEndpointStatus: "pending",
+			wantReady:         false,
+			wantStatus:        metav1.ConditionFalse,
+			wantReason:        privateEndpointReasonNotReady,
+		},
+		{
+			name:              "failed VpcEndpoint",
+			platform:          privateAWS,
+			vpcEndpointStatus: "failed",
+			wantReady:         false,
+			wantErr:           true,
+			wantStatus:        metav1.ConditionFalse,
+			wantReason:        privateEndpointReasonFailed,
+		},
+		{
+			name:       "missing VpcEndpoint CRD skips the check",
+			platform:   privateAWS,
+			missingCRD: true,
+			wantReady:  true,
+			wantStatus: metav1.ConditionUnknown,
+			wantReason: privateEndpointReasonCheckUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hcp := &hypershiftv1beta1.HostedControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+				Spec:       hypershiftv1beta1.HostedControlPlaneSpec{Platform: tt.platform},
+			}
+			objs := []client.Object{hcp}
+			if tt.vpcEndpointStatus != "" {
+				objs = append(objs, &avov1alpha2.VpcEndpoint{
+					ObjectMeta: metav1.ObjectMeta{Name: awsPrivateLinkVpcEndpointName, Namespace: "test"},
+					Status:     avov1alpha2.VpcEndpointStatus{Status: tt.vpcEndpointStatus},
+				})
+			}
+
+			r := newTestReconciler(t, objs...)
+			if tt.missingCRD {
+				// Build a client which does not know about VpcEndpoints, as happens when the aws-vpce-operator is not installed
+				s := runtime.NewScheme()
+				if err := hypershiftv1beta1.AddToScheme(s); err != nil {
+					t.Fatalf("failed to add hypershiftv1beta1 to scheme: %v", err)
+				}
+				r.Client = fake.NewClientBuilder().WithScheme(s).WithObjects(hcp).WithStatusSubresource(hcp).Build()
+			}
+
+			ready, err := r.privateEndpointReady(context.Background(), testr.New(t), hcp)
+			if ready != tt.wantReady {
+				t.Errorf("expected ready %v, got %v", tt.wantReady, ready)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+
+			updated := &hypershiftv1beta1.HostedControlPlane{}
+			if err := r.Get(context.Background(), types.NamespacedName{Name: hcp.Name, Namespace: hcp.Namespace}, updated); err != nil {
+				t.Fatalf("failed to get HostedControlPlane: %v", err)
+			}
+			condition := meta.FindStatusCondition(updated.Status.Conditions, privateEndpointConditionType)
+			if condition == nil {
+				t.Fatalf("expected private endpoint condition to be set")
+			}
+			if condition.Status != tt.wantStatus || condition.Reason != tt.wantReason {
+				t.Errorf("expected condition %s/%s, got %s/%s", tt.wantStatus, tt.wantReason, condition.Status, condition.Reason)
+			}
+		})
+	}
+}
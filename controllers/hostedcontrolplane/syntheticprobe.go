@@ -0,0 +1,310 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hostedcontrolplane
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/route-monitor-operator/pkg/dynatrace"
+	"github.com/openshift/route-monitor-operator/pkg/rhobs"
+	"github.com/openshift/route-monitor-operator/pkg/util"
+)
+
+// Reasons for the Events emitted on HostedControlPlanes by the synthetic probe providers
+const (
+	eventReasonSyntheticProbeFailed          = "SyntheticProbeFailed"
+	eventReasonSyntheticProbeSkipped         = "SyntheticProbeSkipped"
+	eventReasonRHOBSProbeCreated             = "RHOBSProbeCreated"
+	eventReasonRHOBSProbeTerminated          = "RHOBSProbeTerminated"
+	eventReasonDynatraceMonitorCreated       = "DynatraceMonitorCreated"
+	eventReasonDynatraceMonitorsDeduplicated = "DynatraceMonitorsDeduplicated"
+)
+
+// SyntheticProbeProvider is implemented by each external synthetic-monitoring backend capable of probing a HostedControlPlane's
+// kube-apiserver. The HostedControlPlaneReconciler iterates over its enabled providers, so supporting a new backend only requires
+// a new implementation of this interface
+type SyntheticProbeProvider interface {
+	// Name returns a short identifier for the provider, used in logs and error messages
+	Name() string
+
+	// Ensure creates the provider's probe for the HostedControlPlane, if it does not already exist
+	Ensure(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error
+
+	// Delete removes the provider's probe for the HostedControlPlane. Deleting a probe which does not exist is not an error
+	Delete(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error
+
+	// Status reports the current state of the provider's probe for the HostedControlPlane
+	Status(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (ProbeStatus, error)
+}
+
+// ProbeStatus summarizes the state of a provider's probe for a single HostedControlPlane
+type ProbeStatus struct {
+	// Present indicates whether the provider currently has a probe configured for the cluster
+	Present bool
+
+	// State is the provider-specific state of the probe, ie - "active" or "terminating" for RHOBS
+	State string
+}
+
+// retryAfterError indicates a provider failed in a way that is expected to resolve itself, and that the HostedControlPlane
+// should be requeued after the given delay instead of being retried with backoff
+type retryAfterError struct {
+	err   error
+	after time.Duration
+}
+
+func (e *retryAfterError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryAfterError) Unwrap() error {
+	return e.err
+}
+
+// newProbeProviders returns the synthetic probe providers enabled by the operator's configuration. Each provider is optional:
+// Dynatrace is toggled explicitly, while RHOBS is enabled by configuring its probe API URL
+func newProbeProviders(c client.Client, recorder record.EventRecorder, rhobsConfig RHOBSConfig, dynatraceConfig DynatraceConfig) []SyntheticProbeProvider {
+	providers := []SyntheticProbeProvider{}
+	// the transport is shared by all API clients, so that their connections are reused across reconciles. Its proxy function
+	// looks up the cluster-wide Proxy for each request
+	transport := util.ProxyTransport(util.ClusterProxyFunc(c))
+	if dynatraceConfig.Enabled {
+		providers = append(providers, &dynatraceProbeProvider{Client: c, Recorder: recorder, Transport: transport})
+	}
+	if rhobsConfig.ProbeAPIURL != "" {
+		providers = append(providers, &rhobsProbeProvider{Config: rhobsConfig, Recorder: recorder, Transport: transport})
+	}
+	return providers
+}
+
+// ------------------------------dynatrace-------------------------------------
+
+// dynatraceProbeProvider manages Dynatrace HTTP monitors for HostedControlPlanes
+type dynatraceProbeProvider struct {
+	Client   client.Client
+	Recorder record.EventRecorder
+	// Transport sends the requests to the Dynatrace API. The client's default transport is used if it is nil
+	Transport http.RoundTripper
+}
+
+var _ SyntheticProbeProvider = &dynatraceProbeProvider{}
+
+func (p *dynatraceProbeProvider) Name() string {
+	return "dynatrace"
+}
+
+// apiClient builds a Dynatrace API client from the credentials stored in the operator's namespace
+func (p *dynatraceProbeProvider) apiClient(ctx context.Context) (*dynatrace.DynatraceApiClient, error) {
+	apiToken, tenant, err := getDynatraceSecrets(ctx, p.Client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get secret for Dynatrace API client: %w", err)
+	}
+	apiClient := dynatrace.NewDynatraceApiClient(fmt.Sprintf("%s/v1", tenant), apiToken)
+	if p.Transport != nil {
+		apiClient.WithTransport(p.Transport)
+	}
+	return apiClient, nil
+}
+
+func (p *dynatraceProbeProvider) Ensure(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+	// Dynatrace locations are only mapped to AWS regions, HostedControlPlanes on other platforms are not probed by Dynatrace
+	if platformType := getPlatform(hostedcontrolplane).Type(); platformType != hypershiftv1beta1.AWSPlatform {
+		log.V(2).Info("Skipping Dynatrace HTTP monitor of unsupported platform", "platform", platformType)
+		p.Recorder.Eventf(hostedcontrolplane, corev1.EventTypeNormal, eventReasonSyntheticProbeSkipped, "Skipped %s synthetic probe: platform %s is not supported", p.Name(), platformType)
+		return nil
+	}
+	dynatraceApiClient, err := p.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return deployDynatraceHttpMonitorResources(dynatraceApiClient, p.Recorder, log, hostedcontrolplane)
+}
+
+func (p *dynatraceProbeProvider) Delete(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+	dynatraceApiClient, err := p.apiClient(ctx)
+	if err != nil {
+		return err
+	}
+	return deleteDynatraceHttpMonitorResources(dynatraceApiClient, log, hostedcontrolplane)
+}
+
+func (p *dynatraceProbeProvider) Status(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (ProbeStatus, error) {
+	dynatraceApiClient, err := p.apiClient(ctx)
+	if err != nil {
+		return ProbeStatus{}, err
+	}
+	monitors, err := dynatraceApiClient.GetDynatraceHttpMonitors(hostedcontrolplane.Spec.ClusterID)
+	if err != nil {
+		return ProbeStatus{}, fmt.Errorf("failed to retrieve Dynatrace HTTP monitors: %w", err)
+	}
+	return ProbeStatus{
+		Present: len(monitors.Monitors) > 0,
+		State:   fmt.Sprintf("%d monitor(s)", len(monitors.Monitors)),
+	}, nil
+}
+
+// ------------------------------rhobs-----------------------------------------
+
+// rhobsProbeProvider manages RHOBS synthetics probes for HostedControlPlanes
+type rhobsProbeProvider struct {
+	Config   RHOBSConfig
+	Recorder record.EventRecorder
+	// Transport sends the requests to the RHOBS API. The client's default transport is used if it is nil
+	Transport http.RoundTripper
+}
+
+var _ SyntheticProbeProvider = &rhobsProbeProvider{}
+
+func (p *rhobsProbeProvider) Name() string {
+	return "rhobs"
+}
+
+// Ensure ensures that a RHOBS probe exists for the HostedControlPlane
+func (p *rhobsProbeProvider) Ensure(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+	return p.wrapError(p.ensureProbe(ctx, log, hostedcontrolplane))
+}
+
+// Delete marks the RHOBS probe for the HostedControlPlane for termination
+func (p *rhobsProbeProvider) Delete(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+	return p.wrapError(p.deleteProbe(ctx, log, hostedcontrolplane))
+}
+
+func (p *rhobsProbeProvider) Status(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) (ProbeStatus, error) {
+	probe, err := p.createClient(log).GetProbe(ctx, hostedcontrolplane.Spec.ClusterID)
+	if err != nil {
+		return ProbeStatus{}, p.wrapError(fmt.Errorf("failed to retrieve RHOBS probe: %w", err))
+	}
+	if probe == nil {
+		return ProbeStatus{}, nil
+	}
+	return ProbeStatus{Present: true, State: probe.Status}, nil
+}
+
+// wrapError marks non-200 responses from the RHOBS API as retryable after rhobsAPIRetryTimeout
+func (p *rhobsProbeProvider) wrapError(err error) error {
+	if rhobs.IsNon200Error(err) {
+		return &retryAfterError{err: err, after: rhobsAPIRetryTimeout}
+	}
+	return err
+}
+
+// ensureProbe ensures that a RHOBS probe exists for the HostedControlPlane
+func (p *rhobsProbeProvider) ensureProbe(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+	clusterID := hostedcontrolplane.Spec.ClusterID
+	if clusterID == "" {
+		return fmt.Errorf("cluster ID is empty")
+	}
+
+	// Get monitoring URL (API server health endpoint in this case)
+	monitoringURL, err := GetAPIServerHostname(hostedcontrolplane)
+	if err != nil {
+		return fmt.Errorf("failed to get API server hostname: %w", err)
+	}
+	monitoringURL = fmt.Sprintf("https://%s/livez", monitoringURL)
+
+	client := p.createClient(log)
+
+	// Check if probe already exists
+	existingProbe, err := client.GetProbe(ctx, clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to check existing probe: %w", err)
+	}
+
+	if existingProbe != nil {
+		// Handle failed probes by deleting and recreating them
+		if existingProbe.Status == "failed" {
+			log.Info("Found probe in failed state, recreating", "cluster_id", clusterID, "probe_id", existingProbe.ID)
+			// Delete the failed probe first
+			err := client.DeleteProbe(ctx, clusterID)
+			if err != nil {
+				return fmt.Errorf("failed to delete failed probe: %w", err)
+			}
+			// Continue to create new probe below
+		} else {
+			log.V(2).Info("RHOBS probe already exists", "cluster_id", clusterID, "probe_id", existingProbe.ID, "status", existingProbe.Status)
+			return nil
+		}
+	}
+
+	// Determine if cluster is private
+	isPrivate := getPlatform(hostedcontrolplane).EndpointAccess() == hypershiftv1beta1.Private
+
+	// Create probe request using the convenience function
+	// Note: Additional labels like management-cluster-id can be added in the future
+	probeReq := rhobs.NewClusterProbeRequest(clusterID, monitoringURL, isPrivate)
+
+	// Create the probe
+	probe, err := client.CreateProbe(ctx, probeReq)
+	if err != nil {
+		return fmt.Errorf("failed to create RHOBS probe: %w", err)
+	}
+
+	log.Info("Successfully created RHOBS probe", "cluster_id", clusterID, "probe_id", probe.ID)
+	p.Recorder.Eventf(hostedcontrolplane, corev1.EventTypeNormal, eventReasonRHOBSProbeCreated, "Created RHOBS probe %s for %s", probe.ID, monitoringURL)
+	return nil
+}
+
+// deleteProbe deletes the RHOBS probe for the HostedControlPlane
+func (p *rhobsProbeProvider) deleteProbe(ctx context.Context, log logr.Logger, hostedcontrolplane *hypershiftv1beta1.HostedControlPlane) error {
+	clusterID := hostedcontrolplane.Spec.ClusterID
+	if clusterID == "" {
+		return fmt.Errorf("cluster ID is empty")
+	}
+
+	// Delete the probe (sets status to terminating)
+	err := p.createClient(log).DeleteProbe(ctx, clusterID)
+	if err != nil {
+		return fmt.Errorf("failed to delete RHOBS probe: %w", err)
+	}
+
+	log.Info("Successfully marked RHOBS probe for termination", "cluster_id", clusterID)
+	p.Recorder.Event(hostedcontrolplane, corev1.EventTypeNormal, eventReasonRHOBSProbeTerminated, "Marked RHOBS probe for termination")
+	return nil
+}
+
+// createClient creates an RHOBS client sending its requests through the cluster-wide proxy
+func (p *rhobsProbeProvider) createClient(log logr.Logger) *rhobs.Client {
+	rhobsClient := p.newClient(log)
+	if p.Transport != nil {
+		rhobsClient.WithTransport(p.Transport)
+	}
+	return rhobsClient
+}
+
+// newClient creates an RHOBS client with or without OIDC authentication based on configuration
+func (p *rhobsProbeProvider) newClient(log logr.Logger) *rhobs.Client {
+	if p.Config.OIDCClientID != "" && p.Config.OIDCClientSecret != "" && p.Config.OIDCIssuerURL != "" {
+		oidcConfig := rhobs.OIDCConfig{
+			ClientID:     p.Config.OIDCClientID,
+			ClientSecret: p.Config.OIDCClientSecret,
+			IssuerURL:    p.Config.OIDCIssuerURL,
+		}
+		log.V(2).Info("Creating RHOBS client with OIDC authentication")
+		// Use configurable tenant name in URL path, OIDC client ID is used for authentication headers
+		return rhobs.NewClientWithOIDC(p.Config.ProbeAPIURL, p.Config.Tenant, oidcConfig, log)
+	}
+
+	log.V(2).Info("Creating RHOBS client without authentication")
+	return rhobs.NewClient(p.Config.ProbeAPIURL, p.Config.Tenant, log)
+}
@@ -0,0 +1,339 @@
+package hostedcontrolplane
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/testr"
+	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// fakeProbeProvider is a SyntheticProbeProvider which records the calls made against it
+type fakeProbeProvider struct {
+	name      string
+	ensureErr error
+	deleteErr error
+
+	ensured int
+	deleted int
+}
+
+func (p *fakeProbeProvider) Name() string {
+	return p.name
+}
+
+func (p *fakeProbeProvider) Ensure(_ context.Context, _ logr.Logger, _ *hypershiftv1beta1.HostedControlPlane) error {
+	p.ensured++
+	return p.ensureErr
+}
+
+func (p *fakeProbeProvider) Delete(_ context.Context, _ logr.Logger, _ *hypershiftv1beta1.HostedControlPlane) error {
+	p.deleted++
+	return p.deleteErr
+}
+
+func (p *fakeProbeProvider) Status(_ context.Context, _ logr.Logger, _ *hypershiftv1beta1.HostedControlPlane) (ProbeStatus, error) {
+	return ProbeStatus{Present: p.ensured > p.deleted}, nil
+}
+
+func TestNewProbeProviders(t *testing.T) {
+	tests := []struct {
+		name            string
+		rhobsConfig     RHOBSConfig
+		dynatraceConfig DynatraceConfig
+		want            []string
+	}{
+		{
+			name:            "RHOBS is disabled when no probe API URL is configured",
+			rhobsConfig:     RHOBSConfig{},
+			dynatraceConfig: DynatraceConfig{Enabled: true},
+			want:            []string{"dynatrace"},
+		},
+		{
+			name:            "RHOBS is enabled when a probe API URL is configured",
+			rhobsConfig:     RHOBSConfig{ProbeAPIURL: "https://rhobs.example.com"},
+			dynatraceConfig: DynatraceConfig{Enabled: true},
+			want:            []string{"dynatrace", "rhobs"},
+		},
+		{
+			name:            "Dynatrace is disabled when not enabled",
+			rhobsConfig:     RHOBSConfig{ProbeAPIURL: "https://rhobs.example.com"},
+			dynatraceConfig: DynatraceConfig{Enabled: false},
+			want:            []string{"rhobs"},
+		},
+		{
+			name:            "no providers are enabled",
+			rhobsConfig:     RHOBSConfig{},
+			dynatraceConfig: DynatraceConfig{Enabled: false},
+			want:            []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t)
+			providers := newProbeProviders(r.Client, r.Recorder, tt.rhobsConfig, tt.dynatraceConfig)
+			if len(providers) != len(tt.want) {
+				t.Fatalf("expected %d providers, got %d", len(tt.want), len(providers))
+			}
+			for i, provider := range providers {
+				if provider.Name() != tt.want[i] {
+					t.Errorf("expected provider %d to be %q, got %q", i, tt.want[i], provider.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestNewProbeProviders_sharedTransport(t *testing.T) {
+	r := newTestReconciler(t)
+	providers := newProbeProviders(r.Client, r.Recorder, RHOBSConfig{ProbeAPIURL: "https://rhobs.example.com"}, DynatraceConfig{Enabled: true})
+	dynatraceTransport := providers[0].(*dynatraceProbeProvider).Transport
+	rhobsTransport := providers[1].(*rhobsProbeProvider).Transport
+	if dynatraceTransport == nil || dynatraceTransport != rhobsTransport {
+		t.Errorf("expected the providers to share a transport, got %v and %v", dynatraceTransport, rhobsTransport)
+	}
+}
+
+func TestHostedControlPlaneReconciler_reconcileProbeProviders(t *testing.T) {
+	tests := []struct {
+		name       string
+		errs       []error
+		wantResult ctrl.Result
+		wantErr    bool
+	}{
+		{
+			name:       "all providers succeed",
+			errs:       []error{nil, nil},
+			wantResult: ctrl.Result{},
+			wantErr:    false,
+		},
+		{
+			name:       "generic errors are returned to the controller",
+			errs:       []error{errors.New("boom"), nil},
+			wantResult: ctrl.Result{},
+			wantErr:    true,
+		},
+		{
+			name:       "retryable errors are requeued after the provided delay",
+			errs:       []error{nil, &retryAfterError{err: errors.New("boom"), after: time.Minute}},
+			wantResult: ctrl.Result{RequeueAfter: time.Minute},
+			wantErr:    false,
+		},
+		{
+			name: "the shortest retry delay is used",
+			errs: []error{
+				&retryAfterError{err: errors.New("boom"), after: 5 * time.Minute},
+				&retryAfterError{err: errors.New("boom"), after: time.Minute},
+			},
+			wantResult: ctrl.Result{RequeueAfter: time.Minute},
+			wantErr:    false,
+		},
+		{
+			name:       "generic errors take precedence over retryable errors",
+			errs:       []error{&retryAfterError{err: errors.New("boom"), after: time.Minute}, errors.New("boom")},
+			wantResult: ctrl.Result{},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newTestReconciler(t)
+			providers := []*fakeProbeProvider{}
+			r.ProbeProviders = []SyntheticProbeProvider{}
+			for i, err := range tt.errs {
+				provider := &fakeProbeProvider{name: fmt.Sprintf("provider-%d", i), ensureErr: err}
+				providers = append(providers, provider)
+				r.ProbeProviders = append(r.ProbeProviders, provider)
+			}
+
+			result, err := r.reconcileProbeProviders(testr.New(t), &hypershiftv1beta1.HostedControlPlane{}, "deploy", func(provider SyntheticProbeProvider) error {
+				return provider.Ensure(context.Background(), testr.New(t), &hypershiftv1beta1.HostedControlPlane{})
+			})
+			if result != tt.wantResult {
+				t.Errorf("expected result %#v, got %#v", tt.wantResult, result)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			// A failing provider must never prevent the remaining providers from being reconciled
+			for _, provider := range providers {
+				if provider.ensured != 1 {
+					t.Errorf("expected provider %s to be reconciled once, got %d", provider.name, provider.ensured)
+				}
+			}
+		})
+	}
+}
+
+func TestRHOBSProbeProvider(t *testing.T) {
+	hcp := &hypershiftv1beta1.HostedControlPlane{
+		Spec: hypershiftv1beta1.HostedControlPlaneSpec{
+			ClusterID: "test-cluster",
+			Services: []hypershiftv1beta1.ServicePublishingStrategyMapping{
+				{
+					Service: "APIServer",
+					ServicePublishingStrategy: hypershiftv1beta1.ServicePublishingStrategy{
+						Route: &hypershiftv1beta1.RoutePublishingStrategy{
+							Hostname: "api.example.com",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	t.Run("Ensure creates a probe when none exists", func(t *testing.T) {
+		created := false
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				_, _ = w.Write([]byte(`{"probes":[]}`))
+			case http.MethodPost:
+				created = true
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(`{"id":"probe-123","status":"active"}`))
+			}
+		}))
+		defer server.Close()
+
+		provider := &rhobsProbeProvider{Config: RHOBSConfig{ProbeAPIURL: server.URL, Tenant: "hcp"}, Recorder: record.NewFakeRecorder(10)}
+		if err := provider.Ensure(context.Background(), testr.New(t), hcp); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !created {
+			t.Errorf("expected probe to be created")
+		}
+	})
+
+	t.Run("Status reports the state of an existing probe", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"probes":[{"id":"probe-123","labels":{"cluster-id":"test-cluster"},"status":"active"}]}`))
+		}))
+		defer server.Close()
+
+		provider := &rhobsProbeProvider{Config: RHOBSConfig{ProbeAPIURL: server.URL, Tenant: "hcp"}, Recorder: record.NewFakeRecorder(10)}
+		status, err := provider.Status(context.Background(), testr.New(t), hcp)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !status.Present || status.State != "active" {
+			t.Errorf("unexpected status: %#v", status)
+		}
+	})
+
+	t.Run("non-200 responses are retried after a delay", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		provider := &rhobsProbeProvider{Config: RHOBSConfig{ProbeAPIURL: server.URL, Tenant: "hcp"}, Recorder: record.NewFakeRecorder(10)}
+		err := provider.Delete(context.Background(), testr.New(t), hcp)
+		var retryErr *retryAfterError
+		if !errors.As(err, &retryErr) {
+			t.Fatalf("expected a retryAfterError, got %v", err)
+		}
+		if retryErr.after != rhobsAPIRetryTimeout {
+			t.Errorf("expected retry after %v, got %v", rhobsAPIRetryTimeout, retryErr.after)
+		}
+	})
+}
+
+func TestDynatraceProbeProvider_skipsUnsupportedPlatforms(t *testing.T) {
+	for _, platform := range []hypershiftv1beta1.PlatformSpec{
+		{Type: hypershiftv1beta1.AzurePlatform, Azure: &hypershiftv1beta1.AzurePlatformSpec{Location: "eastus"}},
+		{Type: hypershiftv1beta1.KubevirtPlatform},
+	} {
+		t.Run(string(platform.Type), func(t *testing.T) {
+			hcp := &hypershiftv1beta1.HostedControlPlane{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "test"},
+				Spec:       hypershiftv1beta1.HostedControlPlaneSpec{Platform: platform},
+			}
+			// no Dynatrace credentials exist, so any attempt to reach Dynatrace fails
+			r := newTestReconciler(t, hcp)
+			provider := &dynatraceProbeProvider{Client: r.Client, Recorder: r.Recorder}
+
+			if err := provider.Ensure(context.Background(), testr.New(t), hcp); err != nil {
+				t.Fatalf("expected unsupported platforms to be skipped, got %v", err)
+			}
+			select {
+			case event := <-r.Recorder.(*record.FakeRecorder).Events:
+				if !strings.HasPrefix(event, "Normal "+eventReasonSyntheticProbeSkipped) {
+					t.Errorf("expected a %s event, got %q", eventReasonSyntheticProbeSkipped, event)
+				}
+			default:
+				t.Errorf("expected a %s event to be emitted", eventReasonSyntheticProbeSkipped)
+			}
+		})
+	}
+}
+
+func TestHostedControlPlaneReconciler_Reconcile_deletesFromAllProviders(t *testing.T) {
+	hcp := &hypershiftv1beta1.HostedControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test",
+			Namespace:         "test",
+			Finalizers:        []string{hostedcontrolplaneFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+	}
+	r := newTestReconciler(t, hcp)
+	first := &fakeProbeProvider{name: "first"}
+	second := &fakeProbeProvider{name: "second"}
+	r.ProbeProviders = []SyntheticProbeProvider{first, second}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: hcp.Name, Namespace: hcp.Namespace}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if first.deleted != 1 || second.deleted != 1 {
+		t.Errorf("expected each provider to be deleted once, got first=%d second=%d", first.deleted, second.deleted)
+	}
+}
+
+func TestHostedControlPlaneReconciler_Reconcile_keepsFinalizerWhenProviderFails(t *testing.T) {
+	hcp := &hypershiftv1beta1.HostedControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "test",
+			Namespace:         "test",
+			Finalizers:        []string{hostedcontrolplaneFinalizer},
+			DeletionTimestamp: &metav1.Time{Time: time.Now()},
+		},
+	}
+	r := newTestReconciler(t, hcp)
+	failing := &fakeProbeProvider{name: "failing", deleteErr: errors.New("boom")}
+	healthy := &fakeProbeProvider{name: "healthy"}
+	r.ProbeProviders = []SyntheticProbeProvider{failing, healthy}
+
+	_, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: hcp.Name, Namespace: hcp.Namespace}})
+	if err == nil {
+		t.Fatalf("expected an error from the failing provider")
+	}
+	if !strings.Contains(err.Error(), "failing") {
+		t.Errorf("expected error to name the failing provider, got: %v", err)
+	}
+	if healthy.deleted != 1 {
+		t.Errorf("expected healthy provider to be deleted despite the failure, got %d", healthy.deleted)
+	}
+
+	updated := &hypershiftv1beta1.HostedControlPlane{}
+	if err := r.Get(context.Background(), types.NamespacedName{Name: hcp.Name, Namespace: hcp.Namespace}, updated); err != nil {
+		t.Fatalf("failed to get HostedControlPlane: %v", err)
+	}
+	if len(updated.Finalizers) != 1 {
+		t.Errorf("expected finalizer to be retained until all providers are cleaned up, got %v", updated.Finalizers)
+	}
+}
@@ -1,15 +1,18 @@
 package controllers
 
 import (
+	"context"
+	"time"
+
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
 	"github.com/openshift/route-monitor-operator/api/v1alpha1"
-	"github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
 	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 //go:generate mockgen -source $GOFILE -destination ../pkg/util/test/generated/mocks/$GOPACKAGE/interfaces.go -package $GOPACKAGE
@@ -29,7 +32,7 @@ type MonitorResourceHandler interface {
 	// SetResourceReference updates the ResourceRef in the Monitor Resources
 	// It receives a pointer to the ref string within the monitor resource
 	// In case the reference has been changed it returns true as a boolean
-	SetResourceReference(reference *v1alpha1.NamespacedName, target types.NamespacedName) (bool, error)
+	SetResourceReference(reference *v1alpha1.NamespacedName, target types.NamespacedName) bool
 
 	// UpdateMonitorResource updates the Spec of the ClusterURLMonitor & RouteMonitor CR
 	// Should be called after object that triggered reconcile loop has been changed
@@ -59,33 +62,64 @@ type ServiceMonitorHandler interface {
 	// UpdateServiceMonitorDeployment ensures that a ServiceMonitor deployment according
 	// to the template exists. If none exists, it will create a new one.
 	// If the template changed, it will update the existing deployment
-	UpdateServiceMonitorDeployment(template monitoringv1.ServiceMonitor) error
+	// It returns whether the ServiceMonitor was created, updated or left unchanged
+	UpdateServiceMonitorDeployment(template monitoringv1.ServiceMonitor) (controllerutil.OperationResult, error)
 
-	// TemplateAndUpdateServiceMonitorDeployment will generate a template and then
+	// TemplateAndUpdateServiceMonitorDeployment will generate a template scraping the blackbox exporter behind exporterService with the given module and then
 	// call UpdateServiceMonitorDeployment to ensure its current state matches the template.
-	TemplateAndUpdateServiceMonitorDeployment(url, blackBoxExporterNamespace string, namespacedName types.NamespacedName, clusterID string, hcp bool, useInsecure bool, owner *metav1.OwnerReference) error
+	// It returns whether the ServiceMonitor was created, updated or left unchanged
+	TemplateAndUpdateServiceMonitorDeployment(url string, exporterService types.NamespacedName, namespacedName types.NamespacedName, clusterID string, hcp bool, module, proxyURL string, owner *metav1.OwnerReference) (controllerutil.OperationResult, error)
 
 	// DeleteServiceMonitorDeployment deletes a ServiceMonitor refrenced by a namespaced name
 	DeleteServiceMonitorDeployment(serviceMonitorRef v1alpha1.NamespacedName, hcp bool) error
 
 	// HypershiftUpdateServiceMonitorDeployment is for HyperShift cluster to ensure that a ServiceMonitor deployment according
 	// to the template exists. If none exists, it will create a new one. If the template changed, it will update the existing deployment
-	HypershiftUpdateServiceMonitorDeployment(template rhobsv1.ServiceMonitor) error
+	HypershiftUpdateServiceMonitorDeployment(template rhobsv1.ServiceMonitor) (controllerutil.OperationResult, error)
 }
 
 type PrometheusRuleHandler interface {
 	// UpdatePrometheusRuleDeployment ensures that a PrometheusRule deployment according
 	// to the template exists. If none exists, it will create a new one.
 	// If the template changed, it will update the existing deployment
-	UpdatePrometheusRuleDeployment(template monitoringv1.PrometheusRule) error
+	// It returns whether the PrometheusRule was created, updated or left unchanged
+	// HCP monitors use RHOBS PrometheusRules instead
+	UpdatePrometheusRuleDeployment(template monitoringv1.PrometheusRule, isHCPMonitor bool) (controllerutil.OperationResult, error)
 
 	// DeletePrometheusRuleDeployment deletes a PrometheusRule refrenced by a namespaced name
-	DeletePrometheusRuleDeployment(prometheusRuleRef v1alpha1.NamespacedName) error
+	DeletePrometheusRuleDeployment(prometheusRuleRef v1alpha1.NamespacedName, isHCPMonitor bool) error
+}
+
+type DashboardHandler interface {
+	// Enabled returns whether Grafana dashboards are generated for monitors
+	Enabled() bool
+
+	// TemplateAndUpdateDashboardDeployment generates the Grafana dashboard for the probes of the url and ensures
+	// that it exists in its current state. It returns whether the dashboard was created, updated or left unchanged
+	TemplateAndUpdateDashboardDeployment(url, percent string, namespacedName types.NamespacedName, owner *metav1.OwnerReference) (controllerutil.OperationResult, error)
+
+	// DeleteDashboardDeployment deletes a dashboard refrenced by a namespaced name
+	DeleteDashboardDeployment(dashboardRef v1alpha1.NamespacedName) error
 }
 
 type BlackBoxExporterHandler interface {
-	EnsureBlackBoxExporterResourcesExist() error
-	EnsureBlackBoxExporterResourcesAbsent() error
-	ShouldDeleteBlackBoxExporterResources() (blackboxexporter.ShouldDeleteBlackBoxExporter, error)
-	GetBlackBoxExporterNamespace() string
+	// GetExporterService returns the Service of the blackbox exporter selected by a monitor's exporterRef,
+	// which is the operator's blackbox exporter if the exporterRef is empty
+	GetExporterService(exporterRef string) (types.NamespacedName, error)
+
+	// ValidateProbeReferences returns an error if the Secrets and ConfigMaps referenced by a monitor's probe settings
+	// can not be resolved in the monitor's namespace
+	ValidateProbeReferences(namespace string, probe v1alpha1.ProbeSpec) error
+}
+
+type SLOStatusHandler interface {
+	// GetSLOStatus queries the service level observed for the url against the target availability ratio.
+	// It returns nil if no probe results were found for the url
+	GetSLOStatus(ctx context.Context, url, target string) (*v1alpha1.SloStatus, error)
+
+	// ShouldResync returns whether the recorded SLO status is due to be queried again
+	ShouldResync(status *v1alpha1.SloStatus) bool
+
+	// ResyncInterval returns the wait period between queries for the same monitor
+	ResyncInterval() time.Duration
 }
@@ -0,0 +1,198 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probeexporter
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	configv1 "github.com/openshift/api/config/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
+	"github.com/openshift/route-monitor-operator/pkg/util"
+	"github.com/openshift/route-monitor-operator/pkg/util/finalizer"
+	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
+)
+
+// controllerName identifies the ProbeExporterReconciler in logs and metrics
+const controllerName = "ProbeExporter"
+
+// resyncInterval is the wait period between reconciles of a ProbeExporter. The resources of its blackbox
+// exporter reside in namespaces the operator does not watch, so changes to them are only noticed on resync
+const resyncInterval = 10 * time.Minute
+
+// ProbeExporterReconciler deploys the blackbox exporters defined by ProbeExporters
+type ProbeExporterReconciler struct {
+	Client client.Client
+	// ExporterClient reads and writes the resources of the blackbox exporters without going through the
+	// manager's cache, which is restricted to the operator's namespace on non management clusters
+	ExporterClient client.Client
+	Ctx            context.Context
+	Log            logr.Logger
+	Scheme         *runtime.Scheme
+	Image          string
+}
+
+func NewReconciler(mgr manager.Manager, blackboxExporterImage string) (*ProbeExporterReconciler, error) {
+	exporterClient, err := client.New(mgr.GetConfig(), client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	return &ProbeExporterReconciler{
+		Client:         mgr.GetClient(),
+		ExporterClient: exporterClient,
+		Ctx:            context.Background(),
+		Log:            ctrl.Log.WithName("controllers").WithName(controllerName),
+		Scheme:         mgr.GetScheme(),
+		Image:          blackboxExporterImage,
+	}, nil
+}
+
+// The resources of a ProbeExporter's blackbox exporter are written to its spec.namespace, which can be any namespace,
+// hence the cluster-wide write access to Services, ConfigMaps, Deployments, NetworkPolicies and Secrets.
+// +kubebuilder:rbac:groups=monitoring.openshift.io,resources=probeexporters,verbs=get;list;watch
+// +kubebuilder:rbac:groups=monitoring.openshift.io,resources=probeexporters/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=networkpolicies,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;delete
+// +kubebuilder:rbac:groups=config.openshift.io,resources=proxies,verbs=get;list;watch
+
+func (r *ProbeExporterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	r.Ctx = ctx
+	log := r.Log.WithName("Reconcile").WithValues("name", req.Name)
+
+	log.V(2).Info("Entering GetProbeExporter")
+	probeExporter := v1alpha1.ProbeExporter{}
+	if err := r.Client.Get(ctx, req.NamespacedName, &probeExporter); err != nil {
+		if k8serrors.IsNotFound(err) {
+			log.V(2).Info("ProbeExporter not found, stopping reconcile")
+			return utilreconcile.Stop()
+		}
+		log.Error(err, "Failed to retrieve ProbeExporter. Requeueing...")
+		return utilreconcile.RequeueWith(err)
+	}
+
+	// The resources of the blackbox exporter are owned by the ProbeExporter and garbage collected with it
+	if finalizer.WasDeleteRequested(&probeExporter) {
+		log.Info("ProbeExporter is being deleted. Finished reconcile.")
+		return utilreconcile.Stop()
+	}
+
+	log.V(2).Info("Entering EnsureBlackBoxExporterResourcesExist")
+	exporter := blackboxexporter.NewForProbeExporter(r.ExporterClient, log, ctx, r.Image, &probeExporter)
+	// the monitors selecting the ProbeExporter are listed from the manager's cache, which indexes them
+	exporter.Monitors = r.Client
+	exporterErr := exporter.EnsureBlackBoxExporterResourcesExist()
+	if exporterErr != nil {
+		log.Error(exporterErr, "Failed to create BlackBoxExporter. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureBlackBoxExporterResourcesExist")
+	}
+
+	log.V(2).Info("Entering UpdateProbeExporterStatus")
+	if err := r.UpdateProbeExporterStatus(probeExporter, exporter, exporterErr); err != nil {
+		log.Error(err, "Failed to update ProbeExporter status. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "UpdateProbeExporterStatus")
+		return utilreconcile.RequeueWith(err)
+	}
+	if exporterErr != nil {
+		return utilreconcile.RequeueWith(exporterErr)
+	}
+
+	log.Info("All operations for ProbeExporter completed. Finished Reconcile.")
+	return utilreconcile.RequeueAfter(resyncInterval), nil
+}
+
+// UpdateProbeExporterStatus records the Service of the ProbeExporter's blackbox exporter and the error of deploying it
+func (r *ProbeExporterReconciler) UpdateProbeExporterStatus(probeExporter v1alpha1.ProbeExporter, exporter *blackboxexporter.BlackBoxExporter, exporterErr error) error {
+	status := v1alpha1.ProbeExporterStatus{
+		ServiceRef: v1alpha1.NamespacedName{Name: exporter.NamespacedName.Name, Namespace: exporter.NamespacedName.Namespace},
+	}
+	if exporterErr != nil {
+		status.ErrorStatus = exporterErr.Error()
+	}
+	if probeExporter.Status == status {
+		return nil
+	}
+	probeExporter.Status = status
+	return r.Client.Status().Update(r.Ctx, &probeExporter)
+}
+
+func (r *ProbeExporterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1alpha1.ProbeExporter{}).
+		Watches(&v1alpha1.RouteMonitor{}, EnqueueProbeExportersForMonitor, builder.WithPredicates(blackboxexporter.MonitorExporterChanged)).
+		Watches(&v1alpha1.ClusterUrlMonitor{}, EnqueueProbeExportersForMonitor, builder.WithPredicates(blackboxexporter.MonitorExporterChanged)).
+		// the blackbox exporters of all ProbeExporters are rolled out with the new proxy settings
+		Watches(&configv1.Proxy{}, handler.EnqueueRequestsFromMapFunc(r.allProbeExporters), builder.WithPredicates(util.ClusterProxyChanged)).
+		Complete(r)
+}
+
+// allProbeExporters maps an object to all ProbeExporters
+func (r *ProbeExporterReconciler) allProbeExporters(ctx context.Context, _ client.Object) []reconcile.Request {
+	probeExporters := v1alpha1.ProbeExporterList{}
+	if err := r.Client.List(ctx, &probeExporters); err != nil {
+		r.Log.Error(err, "Failed to list ProbeExporters")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(probeExporters.Items))
+	for _, probeExporter := range probeExporters.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: probeExporter.Name}})
+	}
+	return requests
+}
+
+// EnqueueProbeExportersForMonitor enqueues the ProbeExporter a RouteMonitor or ClusterUrlMonitor selects, so that the
+// modules of the ProbeExporter's blackbox exporter are updated. On updates the ProbeExporter selected before is enqueued
+// as well, so that it drops the monitor's module and credentials right away
+var EnqueueProbeExportersForMonitor = handler.Funcs{
+	CreateFunc: func(_ context.Context, e event.CreateEvent, q workqueue.RateLimitingInterface) {
+		enqueueProbeExporter(q, e.Object)
+	},
+	UpdateFunc: func(_ context.Context, e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+		enqueueProbeExporter(q, e.ObjectOld)
+		enqueueProbeExporter(q, e.ObjectNew)
+	},
+	DeleteFunc: func(_ context.Context, e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+		enqueueProbeExporter(q, e.Object)
+	},
+	GenericFunc: func(_ context.Context, e event.GenericEvent, q workqueue.RateLimitingInterface) {
+		enqueueProbeExporter(q, e.Object)
+	},
+}
+
+// enqueueProbeExporter enqueues the ProbeExporter referenced by the exporterRef of a monitor, if any
+func enqueueProbeExporter(q workqueue.RateLimitingInterface, monitor client.Object) {
+	if exporterRef := blackboxexporter.ExporterRef(monitor); exporterRef != "" {
+		q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Name: exporterRef}})
+	}
+}
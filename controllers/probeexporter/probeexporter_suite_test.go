@@ -0,0 +1,13 @@
+package probeexporter_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestProbeexporter(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Probeexporter Suite")
+}
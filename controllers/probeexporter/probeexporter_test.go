@@ -0,0 +1,168 @@
+package probeexporter_test
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	configv1 "github.com/openshift/api/config/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/controllers/probeexporter"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+)
+
+var _ = Describe("ProbeExporterReconciler", func() {
+	var (
+		c          client.Client
+		reconciler *probeexporter.ProbeExporterReconciler
+		objects    []client.Object
+		res        ctrl.Result
+		err        error
+	)
+	BeforeEach(func() {
+		objects = []client.Object{}
+	})
+	JustBeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+		Expect(configv1.AddToScheme(scheme)).To(Succeed())
+		builder := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).WithStatusSubresource(&v1alpha1.ProbeExporter{})
+		Expect(blackboxexporter.IndexExporterRef(context.Background(), builderIndexer{builder})).To(Succeed())
+		c = builder.Build()
+		reconciler = &probeexporter.ProbeExporterReconciler{
+			Client:         c,
+			ExporterClient: c,
+			Ctx:            context.Background(),
+			Log:            logr.Discard(),
+			Scheme:         scheme,
+			Image:          "test-image",
+		}
+		res, err = reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: "zone-a"}})
+	})
+
+	When("the ProbeExporter does not exist", func() {
+		It("should stop", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res).To(Equal(ctrl.Result{}))
+		})
+	})
+
+	When("the ProbeExporter exists", func() {
+		BeforeEach(func() {
+			objects = append(objects, &v1alpha1.ProbeExporter{
+				ObjectMeta: metav1.ObjectMeta{Name: "zone-a"},
+				Spec:       v1alpha1.ProbeExporterSpec{Namespace: "zone-a-probes"},
+			})
+		})
+		It("should deploy its blackbox exporter and record its Service", func() {
+			Expect(err).NotTo(HaveOccurred())
+			Expect(res.RequeueAfter).NotTo(BeZero())
+
+			deployment := appsv1.Deployment{}
+			Expect(c.Get(context.Background(), types.NamespacedName{Name: "blackbox-exporter-zone-a", Namespace: "zone-a-probes"}, &deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Spec.Containers[0].Image).To(Equal("test-image"))
+
+			probeExporter := v1alpha1.ProbeExporter{}
+			Expect(c.Get(context.Background(), types.NamespacedName{Name: "zone-a"}, &probeExporter)).To(Succeed())
+			Expect(probeExporter.Status.ServiceRef).To(Equal(v1alpha1.NamespacedName{Name: "blackbox-exporter-zone-a", Namespace: "zone-a-probes"}))
+			Expect(probeExporter.Status.ErrorStatus).To(BeEmpty())
+		})
+
+		When("a monitor selecting it probes with a bearer token", func() {
+			BeforeEach(func() {
+				objects = append(objects,
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "probe-token", Namespace: "app"},
+						Data:       map[string][]byte{"token": []byte("s3cr3t")},
+					},
+					&v1alpha1.RouteMonitor{
+						ObjectMeta: metav1.ObjectMeta{Name: "protected", Namespace: "app"},
+						Spec: v1alpha1.RouteMonitorSpec{
+							ExporterRef: "zone-a",
+							Route:       v1alpha1.RouteMonitorRouteSpec{Name: "protected", Namespace: "app"},
+							Probe: v1alpha1.ProbeSpec{Auth: &v1alpha1.ProbeAuthSpec{
+								BearerToken: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "probe-token"}, Key: "token"},
+							}},
+						},
+					},
+				)
+			})
+			It("should copy the token next to the blackbox exporter and generate a module reading it", func() {
+				Expect(err).NotTo(HaveOccurred())
+
+				credentials := corev1.Secret{}
+				Expect(c.Get(context.Background(), types.NamespacedName{Name: "blackbox-exporter-zone-a-credentials", Namespace: "zone-a-probes"}, &credentials)).To(Succeed())
+				Expect(credentials.Data).To(Equal(map[string][]byte{"routemonitor_app_protected_bearer_token": []byte("s3cr3t")}))
+
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), types.NamespacedName{Name: "blackbox-exporter-zone-a", Namespace: "zone-a-probes"}, &configMap)).To(Succeed())
+				Expect(configMap.Data["blackbox.yaml"]).To(ContainSubstring("routemonitor_app_protected:"))
+				Expect(configMap.Data["blackbox.yaml"]).To(ContainSubstring("credentials_file: /credentials/routemonitor_app_protected_bearer_token"))
+				Expect(configMap.Data["blackbox.yaml"]).NotTo(ContainSubstring("s3cr3t"))
+			})
+		})
+	})
+})
+
+var _ = Describe("EnqueueProbeExportersForMonitor", func() {
+	var queue workqueue.RateLimitingInterface
+	monitorSelecting := func(exporterRef string) *v1alpha1.RouteMonitor {
+		return &v1alpha1.RouteMonitor{
+			ObjectMeta: metav1.ObjectMeta{Name: "protected", Namespace: "app"},
+			Spec:       v1alpha1.RouteMonitorSpec{ExporterRef: exporterRef},
+		}
+	}
+	queued := func() []string {
+		names := []string{}
+		for queue.Len() > 0 {
+			item, _ := queue.Get()
+			names = append(names, item.(reconcile.Request).Name)
+			queue.Done(item)
+		}
+		return names
+	}
+	BeforeEach(func() {
+		queue = workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+	})
+	AfterEach(func() {
+		queue.ShutDown()
+	})
+
+	It("should enqueue the previous and the new ProbeExporter when a monitor switches exporters", func() {
+		probeexporter.EnqueueProbeExportersForMonitor.Update(context.Background(), event.UpdateEvent{ObjectOld: monitorSelecting("zone-a"), ObjectNew: monitorSelecting("zone-b")}, queue)
+		Expect(queued()).To(ConsistOf("zone-a", "zone-b"))
+	})
+	It("should enqueue the previous ProbeExporter when a monitor clears its exporterRef", func() {
+		probeexporter.EnqueueProbeExportersForMonitor.Update(context.Background(), event.UpdateEvent{ObjectOld: monitorSelecting("zone-a"), ObjectNew: monitorSelecting("")}, queue)
+		Expect(queued()).To(ConsistOf("zone-a"))
+	})
+	It("should enqueue the last ProbeExporter of a deleted monitor", func() {
+		probeexporter.EnqueueProbeExportersForMonitor.Delete(context.Background(), event.DeleteEvent{Object: monitorSelecting("zone-a")}, queue)
+		Expect(queued()).To(ConsistOf("zone-a"))
+	})
+})
+
+// builderIndexer registers indexes with a fake client
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (i builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	i.builder.WithIndex(obj, field, extractValue)
+	return nil
+}
@@ -20,22 +20,38 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	routev1 "github.com/openshift/api/route/v1"
 	monitoringv1alpha1 "github.com/openshift/route-monitor-operator/api/v1alpha1"
 	"github.com/openshift/route-monitor-operator/controllers"
 	"github.com/openshift/route-monitor-operator/pkg/alert"
 	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/dashboard"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
 	reconcileCommon "github.com/openshift/route-monitor-operator/pkg/reconcile"
 	"github.com/openshift/route-monitor-operator/pkg/servicemonitor"
 	"github.com/openshift/route-monitor-operator/pkg/util/finalizer"
 	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
+// controllerName identifies the RouteMonitorReconciler in logs and metrics
+const controllerName = "RouteMonitor"
+
+// eventRecorderName is the source of the Events emitted on RouteMonitors
+const eventRecorderName = "routemonitor-controller"
+
 // RouteMonitorReconciler reconciles a RouteMonitor object
 type RouteMonitorReconciler struct {
 	Client           client.Client
@@ -45,37 +61,55 @@ type RouteMonitorReconciler struct {
 	BlackBoxExporter controllers.BlackBoxExporterHandler
 	ServiceMonitor   controllers.ServiceMonitorHandler
 	Prom             controllers.PrometheusRuleHandler
+	Dashboard        controllers.DashboardHandler
 	Common           controllers.MonitorResourceHandler
+	Recorder         record.EventRecorder
+	SLO              controllers.SLOStatusHandler
+	AlertDefaults    alert.Defaults
 }
 
-func NewReconciler(mgr manager.Manager, blackboxExporterImage, blackboxExporterNamespace string, enablehypershift bool, probeAPIURL string) *RouteMonitorReconciler {
-	log := ctrl.Log.WithName("controllers").WithName("RouteMonitor")
+func NewReconciler(mgr manager.Manager, blackboxExporterImage, blackboxExporterNamespace string, enablehypershift bool, probeAPIURL string, sloStatus controllers.SLOStatusHandler, dashboardConfig dashboard.Config, alertDefaults alert.Defaults) *RouteMonitorReconciler {
+	log := ctrl.Log.WithName("controllers").WithName(controllerName)
 	client := mgr.GetClient()
 	ctx := context.Background()
+	exporter := blackboxexporter.New(client, log, ctx, blackboxExporterImage, blackboxExporterNamespace)
+	// the Secrets and ConfigMaps referenced by probe settings are read without the manager's cache, like the BlackBoxExporter
+	// controller does
+	exporter.References = mgr.GetAPIReader()
 	return &RouteMonitorReconciler{
 		Client:           client,
 		Ctx:              ctx,
 		Log:              log,
 		Scheme:           mgr.GetScheme(),
-		BlackBoxExporter: blackboxexporter.New(client, log, ctx, blackboxExporterImage, blackboxExporterNamespace),
+		BlackBoxExporter: exporter,
 		ServiceMonitor:   servicemonitor.NewServiceMonitor(ctx, client),
-		Prom:             alert.NewPrometheusRule(ctx, client),
+		Prom:             alert.NewPrometheusRule(ctx, client, mgr.GetAPIReader()),
+		Dashboard:        dashboard.NewDashboard(ctx, client, dashboardConfig),
 		Common:           reconcileCommon.NewMonitorResourceCommon(ctx, client),
+		Recorder:         mgr.GetEventRecorderFor(eventRecorderName),
+		SLO:              sloStatus,
+		AlertDefaults:    alertDefaults,
 	}
 }
 
-// +kubebuilder:rbac:groups=*,resources=services,verbs=get;list;watch;create;delete
-// +kubebuilder:rbac:groups=*,resources=configmaps,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=services,verbs=get;list;watch;create;delete
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors,verbs=get;list;watch;create;delete;update
 // +kubebuilder:rbac:groups=monitoring.rhobs,resources=servicemonitors,verbs=get;list;watch;create;delete;update
+// +kubebuilder:rbac:groups=monitoring.coreos.com,resources=servicemonitors/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.rhobs,resources=servicemonitors/finalizers,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=grafana.integreatly.org,resources=grafanadashboards,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=monitoring.coreos.com,resources=prometheusrules,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.rhobs,resources=prometheusrules,verbs=get;list;watch;create;update;delete
 // +kubebuilder:rbac:groups=monitoring.openshift.io,resources=routemonitors,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=monitoring.openshift.io,resources=routemonitors/finalizers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=monitoring.openshift.io,resources=routemonitors/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch
 // +kubebuilder:rbac:groups=operator.openshift.io,resources=ingresscontrollers,verbs=get;list;watch
 // +kubebuilder:rbac:groups=config.openshift.io,resources=clusterversions,verbs=get;list;watch
 // +kubebuilder:rbac:groups=config.openshift.io,resources=infrastructures,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	r.Ctx = ctx
@@ -99,6 +133,7 @@ func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		_, err := r.EnsureMonitorAndDependenciesAbsent(routeMonitor)
 		if err != nil {
 			log.Error(err, "Failed to delete RouteMonitor. Requeueing...")
+			metrics.RecordReconcileStepFailure(controllerName, "EnsureMonitorAndDependenciesAbsent")
 			return utilreconcile.RequeueWith(err)
 		}
 		log.Info("Successfully deleted RouteMonitor. Finished reconcile.")
@@ -109,6 +144,7 @@ func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	res, err = r.EnsureFinalizerSet(routeMonitor)
 	if err != nil {
 		log.Error(err, "Failed to set RouteMonitor's finalizer. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureFinalizerSet")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -116,18 +152,11 @@ func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return utilreconcile.Stop()
 	}
 
-	log.V(2).Info("Entering EnsureBlackBoxExporterResourcesExist")
-	// Should happen once but cannot input in main.go
-	err = r.BlackBoxExporter.EnsureBlackBoxExporterResourcesExist()
-	if err != nil {
-		log.Error(err, "Failed to create BlackBoxExporter. Requeueing...")
-		return utilreconcile.RequeueWith(err)
-	}
-
 	log.V(2).Info("Entering GetRoute")
 	route, err := r.GetRoute(routeMonitor)
 	if err != nil {
 		log.Error(err, "Failed to get Route. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "GetRoute")
 		return utilreconcile.RequeueWith(err)
 	}
 
@@ -135,6 +164,7 @@ func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	res, err = r.EnsureRouteURLExists(route, routeMonitor)
 	if err != nil {
 		log.Error(err, "Failed to get RouteURL for RouteMonitor. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureRouteURLExists")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -146,6 +176,7 @@ func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 	res, err = r.EnsureServiceMonitorExists(routeMonitor)
 	if err != nil {
 		log.Error(err, "Failed to set ServiceMonitor. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureServiceMonitorExists")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -153,11 +184,24 @@ func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return utilreconcile.Stop()
 	}
 
+	log.V(2).Info("Entering EnsureDashboardExists")
+	res, err = r.EnsureDashboardExists(routeMonitor)
+	if err != nil {
+		log.Error(err, "Failed to set dashboard. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureDashboardExists")
+		return utilreconcile.RequeueWith(err)
+	}
+	if res.ShouldStop() {
+		log.Info("Successfully patched RouteMonitor with DashboardRef. Stopping...")
+		return utilreconcile.Stop()
+	}
+
 	log.V(2).Info("Entering EnsurePrometheusRuleResourceExists")
 	// result is silenced as it's the end of the function, if this moves add it back
 	res, err = r.EnsurePrometheusRuleExists(routeMonitor)
 	if err != nil {
 		log.Error(err, "Failed to set PrometheusRule. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsurePrometheusRuleExists")
 		return utilreconcile.RequeueWith(err)
 	}
 	if res.ShouldStop() {
@@ -165,16 +209,85 @@ func (r *RouteMonitorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return utilreconcile.Stop()
 	}
 
+	log.V(2).Info("Entering EnsureSLOStatus")
+	res, err = r.EnsureSLOStatus(routeMonitor)
+	if err != nil {
+		log.Error(err, "Failed to update SLO status. Requeueing...")
+		metrics.RecordReconcileStepFailure(controllerName, "EnsureSLOStatus")
+		return utilreconcile.RequeueWith(err)
+	}
+	if res.ShouldStop() {
+		log.Info("Successfully patched RouteMonitor with SLO status. Stopping...")
+		return utilreconcile.Stop()
+	}
+
 	log.Info("All operations for RouteMonitor completed. Finished Reconcile.")
+	if r.SLO != nil {
+		// periodically resync to keep the SLO status current
+		return utilreconcile.RequeueAfter(r.SLO.ResyncInterval()), nil
+	}
 	return utilreconcile.Stop()
 }
 
+// RouteIndex is the cache index of RouteMonitors by the namespace/name of the Route they monitor
+const RouteIndex = "spec.route"
+
+// IndexRoute registers the RouteIndex for RouteMonitors
+func IndexRoute(ctx context.Context, indexer client.FieldIndexer) error {
+	return indexer.IndexField(ctx, &monitoringv1alpha1.RouteMonitor{}, RouteIndex, func(o client.Object) []string {
+		route := o.(*monitoringv1alpha1.RouteMonitor).Spec.Route
+		if route.Name == "" || route.Namespace == "" {
+			return nil
+		}
+		return []string{route.Namespace + "/" + route.Name}
+	})
+}
+
+// RouteMonitorsForRoute returns a request for each RouteMonitor of the Route, looked up by the RouteIndex
+func (r *RouteMonitorReconciler) RouteMonitorsForRoute(ctx context.Context, route client.Object) []reconcile.Request {
+	routeMonitors := &monitoringv1alpha1.RouteMonitorList{}
+	if err := r.Client.List(ctx, routeMonitors, client.MatchingFields{RouteIndex: route.GetNamespace() + "/" + route.GetName()}); err != nil {
+		r.Log.Error(err, "Failed to list the RouteMonitors of Route", "name", route.GetName(), "namespace", route.GetNamespace())
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(routeMonitors.Items))
+	for _, routeMonitor := range routeMonitors.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: routeMonitor.Name, Namespace: routeMonitor.Namespace}})
+	}
+	return requests
+}
+
+// routeURLChanged filters the Route updates which can change the RouteURL of its RouteMonitors
+var routeURLChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		oldRoute, ok := e.ObjectOld.(*routev1.Route)
+		if !ok {
+			return true
+		}
+		newRoute, ok := e.ObjectNew.(*routev1.Route)
+		if !ok {
+			return true
+		}
+		return oldRoute.Spec.Host != newRoute.Spec.Host ||
+			!equality.Semantic.DeepEqual(oldRoute.Spec.TLS, newRoute.Spec.TLS) ||
+			!equality.Semantic.DeepEqual(oldRoute.Status.Ingress, newRoute.Status.Ingress)
+	},
+}
+
 func (r *RouteMonitorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := IndexRoute(context.Background(), mgr.GetFieldIndexer()); err != nil {
+		return err
+	}
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&monitoringv1alpha1.RouteMonitor{}).
 		Watches(
 			&monitoringv1.ServiceMonitor{},
 			handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(), &monitoringv1alpha1.RouteMonitor{}, handler.OnlyControllerOwner()),
 		).
+		Watches(
+			&routev1.Route{},
+			handler.EnqueueRequestsFromMapFunc(r.RouteMonitorsForRoute),
+			builder.WithPredicates(routeURLChanged),
+		).
 		Complete(r)
 }
@@ -10,11 +10,16 @@ import (
 	"github.com/openshift/route-monitor-operator/api/v1alpha1"
 
 	"github.com/openshift/route-monitor-operator/pkg/alert"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
 	"github.com/openshift/route-monitor-operator/pkg/consts"
+	"github.com/openshift/route-monitor-operator/pkg/dashboard"
+	reconcileCommon "github.com/openshift/route-monitor-operator/pkg/reconcile"
 	customerrors "github.com/openshift/route-monitor-operator/pkg/util/errors"
 	utilreconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
 
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -27,10 +32,14 @@ func (r *RouteMonitorReconciler) EnsurePrometheusRuleExists(routeMonitor v1alpha
 	// If .spec.skipPrometheusRule is true, ensure that the PrometheusRule does NOT exist
 	if routeMonitor.Spec.SkipPrometheusRule {
 		// Cleanup any existing PrometheusRules and update the status
-		if err := r.Prom.DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef); err != nil {
+		if err := r.Prom.DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef, routeMonitor.Status.PrometheusRuleType == v1alpha1.ServiceMonitorTypeRHOBS); err != nil {
 			return utilreconcile.RequeueReconcileWith(err)
 		}
-		updated, _ := r.Common.SetResourceReference(&routeMonitor.Status.PrometheusRuleRef, types.NamespacedName{})
+		updated := r.setPrometheusRuleReference(&routeMonitor, types.NamespacedName{}, "")
+		// without a PrometheusRule, nothing else clears the error of a fixed probe
+		if r.Common.SetErrorStatus(&routeMonitor.Status.ErrorStatus, nil) {
+			updated = true
+		}
 		if updated {
 			return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
 		}
@@ -39,44 +48,167 @@ func (r *RouteMonitorReconciler) EnsurePrometheusRuleExists(routeMonitor v1alpha
 	}
 
 	parsedSlo, err := r.Common.ParseMonitorSLOSpecs(routeMonitor.Status.RouteURL, routeMonitor.Spec.Slo)
+	namespacedName := types.NamespacedName{Namespace: routeMonitor.Namespace, Name: routeMonitor.Name}
+	var template monitoringv1.PrometheusRule
+	invalidAlerting := false
+	if err == nil && parsedSlo != "" {
+		template, err = alert.TemplateForPrometheusRuleResource(routeMonitor.Status.RouteURL, parsedSlo, namespacedName, r.AlertDefaults.Apply(routeMonitor.Spec.Alerting), routeMonitor.Spec.CertificateExpiry)
+		invalidAlerting = err != nil
+	}
 	if r.Common.SetErrorStatus(&routeMonitor.Status.ErrorStatus, err) {
+		switch {
+		case invalidAlerting:
+			reconcileCommon.RecordInvalidAlertingEvent(r.Recorder, &routeMonitor, err)
+		case err != nil:
+			reconcileCommon.RecordInvalidSLOEvent(r.Recorder, &routeMonitor, err)
+		}
 		return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
 	}
+	if invalidAlerting {
+		// the error is already reported in the status, wait for the spec to be fixed
+		return utilreconcile.StopReconcile()
+	}
 	if parsedSlo == "" {
 		// Delete existing PrometheusRules if required
-		err = r.Prom.DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef)
+		err = r.Prom.DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef, routeMonitor.Status.PrometheusRuleType == v1alpha1.ServiceMonitorTypeRHOBS)
 		if err != nil {
 			return utilreconcile.RequeueReconcileWith(err)
 		}
-		updated, _ := r.Common.SetResourceReference(&routeMonitor.Status.PrometheusRuleRef, types.NamespacedName{})
-		if updated {
+		if r.setPrometheusRuleReference(&routeMonitor, types.NamespacedName{}, "") {
 			return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
 		}
 		return utilreconcile.StopReconcile()
 	}
 
-	// Update PrometheusRule from templates
-	namespacedName := types.NamespacedName{Namespace: routeMonitor.Namespace, Name: routeMonitor.Name}
-	template := alert.TemplateForPrometheusRuleResource(routeMonitor.Status.RouteURL, parsedSlo, namespacedName)
-	err = r.Prom.UpdatePrometheusRuleDeployment(template)
+	// Update PrometheusRule from templates, alongside the ServiceMonitor in the monitoring stack scraping it
+	useRHOBS := routeMonitor.Spec.ServiceMonitorType == v1alpha1.ServiceMonitorTypeRHOBS
+	prometheusRuleType := v1alpha1.ServiceMonitorTypeCoreOS
+	if useRHOBS {
+		prometheusRuleType = v1alpha1.ServiceMonitorTypeRHOBS
+	}
+	result, err := r.Prom.UpdatePrometheusRuleDeployment(template, useRHOBS)
 	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
+	reconcileCommon.RecordPrometheusRuleEvent(r.Recorder, &routeMonitor, result, namespacedName)
 
+	// Delete the PrometheusRule of a previous reference or type, now that its replacement exists
+	previousType := routeMonitor.Status.PrometheusRuleType
+	if previousType == "" {
+		// PrometheusRules were only created as CoreOS PrometheusRules before their type was recorded
+		previousType = v1alpha1.ServiceMonitorTypeCoreOS
+	}
+	if reconcileCommon.ReferenceChanged(routeMonitor.Status.PrometheusRuleRef, namespacedName) || previousType != prometheusRuleType {
+		if err := r.Prom.DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef, previousType == v1alpha1.ServiceMonitorTypeRHOBS); err != nil {
+			return utilreconcile.RequeueReconcileWith(err)
+		}
+	}
 	// Update PrometheusRuleReference in RouteMonitor if necessary
-	updated, _ := r.Common.SetResourceReference(&routeMonitor.Status.PrometheusRuleRef, namespacedName)
+	if r.setPrometheusRuleReference(&routeMonitor, namespacedName, prometheusRuleType) {
+		return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
+	}
+	return utilreconcile.ContinueReconcile()
+}
+
+// setPrometheusRuleReference updates the PrometheusRuleRef and PrometheusRuleType in the RouteMonitor's status,
+// returning true if either changed
+func (r *RouteMonitorReconciler) setPrometheusRuleReference(routeMonitor *v1alpha1.RouteMonitor, target types.NamespacedName, prometheusRuleType string) bool {
+	updated := r.Common.SetResourceReference(&routeMonitor.Status.PrometheusRuleRef, target)
+	if routeMonitor.Status.PrometheusRuleType != prometheusRuleType {
+		routeMonitor.Status.PrometheusRuleType = prometheusRuleType
+		updated = true
+	}
+	return updated
+}
+
+// Ensures that the Grafana dashboard of the RouteMonitor is in place when dashboards are enabled
+func (r *RouteMonitorReconciler) EnsureDashboardExists(routeMonitor v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
+	isValid, parsedSlo := routeMonitor.Spec.Slo.IsValid()
+	// RHOBS probe results are not available to the cluster's Grafana
+	if !r.Dashboard.Enabled() || !isValid || routeMonitor.Status.RouteURL == "" || routeMonitor.Spec.ServiceMonitorType == v1alpha1.ServiceMonitorTypeRHOBS {
+		// Delete existing dashboards if required
+		if err := r.Dashboard.DeleteDashboardDeployment(routeMonitor.Status.DashboardRef); err != nil {
+			return utilreconcile.RequeueReconcileWith(err)
+		}
+		updated := r.Common.SetResourceReference(&routeMonitor.Status.DashboardRef, types.NamespacedName{})
+		if updated {
+			return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
+		}
+		return utilreconcile.ContinueReconcile()
+	}
+
+	namespacedName := types.NamespacedName{Namespace: routeMonitor.Namespace, Name: routeMonitor.Name}
+	owner := metav1.NewControllerRef(&routeMonitor.ObjectMeta, routeMonitor.GroupVersionKind())
+	result, err := r.Dashboard.TemplateAndUpdateDashboardDeployment(routeMonitor.Status.RouteURL, parsedSlo, namespacedName, owner)
+	if err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+	dashboardName := dashboard.NamespacedNameFor(namespacedName)
+	reconcileCommon.RecordDashboardEvent(r.Recorder, &routeMonitor, result, dashboardName)
+
+	// Delete the dashboard of a previous reference, now that its replacement exists
+	if reconcileCommon.ReferenceChanged(routeMonitor.Status.DashboardRef, dashboardName) {
+		if err := r.Dashboard.DeleteDashboardDeployment(routeMonitor.Status.DashboardRef); err != nil {
+			return utilreconcile.RequeueReconcileWith(err)
+		}
+	}
+	// Update DashboardRef in RouteMonitor if necessary
+	updated := r.Common.SetResourceReference(&routeMonitor.Status.DashboardRef, dashboardName)
 	if updated {
 		return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
 	}
 	return utilreconcile.ContinueReconcile()
 }
 
+// Ensures that the SLO status of the RouteMonitor reflects the service level observed by Prometheus
+func (r *RouteMonitorReconciler) EnsureSLOStatus(routeMonitor v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
+	// RHOBS probe results are not available to the operator's Prometheus
+	if r.SLO == nil || routeMonitor.Spec.ServiceMonitorType == v1alpha1.ServiceMonitorTypeRHOBS {
+		return utilreconcile.ContinueReconcile()
+	}
+
+	isValid, target := routeMonitor.Spec.Slo.IsValid()
+	if !isValid || routeMonitor.Status.RouteURL == "" {
+		if routeMonitor.Status.SLO != nil {
+			routeMonitor.Status.SLO = nil
+			return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
+		}
+		return utilreconcile.ContinueReconcile()
+	}
+	if !r.SLO.ShouldResync(routeMonitor.Status.SLO) {
+		return utilreconcile.ContinueReconcile()
+	}
+
+	sloStatus, err := r.SLO.GetSLOStatus(r.Ctx, routeMonitor.Status.RouteURL, target)
+	if err != nil {
+		// the SLO status is optional: keep the last one and retry with the next periodic resync
+		r.Log.Info("Failed to query the SLO status, keeping the last one", "error", err.Error())
+		reconcileCommon.RecordSLOQueryFailedEvent(r.Recorder, &routeMonitor, err)
+		return utilreconcile.ContinueReconcile()
+	}
+	if sloStatus == nil && routeMonitor.Status.SLO == nil {
+		return utilreconcile.ContinueReconcile()
+	}
+	routeMonitor.Status.SLO = sloStatus
+	return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
+}
+
 // Ensures that a ServiceMonitor is created from the RouteMonitor CR
 func (r *RouteMonitorReconciler) EnsureServiceMonitorExists(routeMonitor v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
 	// Was the RouteURL populated by a previous step?
 	if routeMonitor.Status.RouteURL == "" {
 		return utilreconcile.RequeueReconcileWith(customerrors.ErrNoHost)
 	}
+	if err := blackboxexporter.ValidateRouteMonitorProbe(routeMonitor); err != nil {
+		// the error replaces errors of the later steps in the status, which are not reached until the probe is fixed
+		if routeMonitor.Status.ErrorStatus != err.Error() {
+			routeMonitor.Status.ErrorStatus = err.Error()
+			reconcileCommon.RecordInvalidProbeEvent(r.Recorder, &routeMonitor, err)
+			return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
+		}
+		// the error is already reported in the status, wait for the spec to be fixed
+		return utilreconcile.StopReconcile()
+	}
 
 	var id string
 	var err error
@@ -95,50 +227,89 @@ func (r *RouteMonitorReconciler) EnsureServiceMonitorExists(routeMonitor v1alpha
 		}
 	}
 
+	exporterService, err := r.BlackBoxExporter.GetExporterService(routeMonitor.Spec.ExporterRef)
+	if err != nil {
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+	// the blackbox exporter has no module for a monitor with unresolved references, so its probes would fail unnoticed
+	if err := r.BlackBoxExporter.ValidateProbeReferences(routeMonitor.Namespace, routeMonitor.Spec.Probe); err != nil {
+		if routeMonitor.Status.ErrorStatus != err.Error() {
+			routeMonitor.Status.ErrorStatus = err.Error()
+			reconcileCommon.RecordInvalidProbeEvent(r.Recorder, &routeMonitor, err)
+			return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
+		}
+		// retry until the referenced Secrets and ConfigMaps exist
+		return utilreconcile.RequeueReconcileWith(err)
+	}
+
 	// update ServiceMonitor if requiredctrl
 	namespacedName := types.NamespacedName{Name: routeMonitor.Name, Namespace: routeMonitor.Namespace}
 	owner := metav1.NewControllerRef(&routeMonitor.ObjectMeta, routeMonitor.GroupVersionKind())
-	if err := r.ServiceMonitor.TemplateAndUpdateServiceMonitorDeployment(routeMonitor.Status.RouteURL, r.BlackBoxExporter.GetBlackBoxExporterNamespace(), namespacedName, id, useRHOBS, routeMonitor.Spec.InsecureSkipTLSVerify, owner); err != nil {
+	result, err := r.ServiceMonitor.TemplateAndUpdateServiceMonitorDeployment(routeMonitor.Status.RouteURL, exporterService, namespacedName, id, useRHOBS, blackboxexporter.ModuleForRouteMonitor(routeMonitor), routeMonitor.Spec.ProxyURL, owner)
+	if err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
-	// update ServiceMonitorRef if required
-	updated, err := r.Common.SetResourceReference(&routeMonitor.Status.ServiceMonitorRef, namespacedName)
-	if err != nil {
+	reconcileCommon.RecordServiceMonitorEvent(r.Recorder, &routeMonitor, result, namespacedName)
+	// Delete the ServiceMonitor of a previous reference or type, now that its replacement exists
+	serviceMonitorType := v1alpha1.ServiceMonitorTypeCoreOS
+	if useRHOBS {
+		serviceMonitorType = v1alpha1.ServiceMonitorTypeRHOBS
+	}
+	if err := r.EnsurePreviousServiceMonitorAbsent(routeMonitor, namespacedName, serviceMonitorType); err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
+	// update ServiceMonitorRef if required
+	updated := r.Common.SetResourceReference(&routeMonitor.Status.ServiceMonitorRef, namespacedName)
+	if routeMonitor.Status.ServiceMonitorType != serviceMonitorType {
+		routeMonitor.Status.ServiceMonitorType = serviceMonitorType
+		updated = true
+	}
 	if updated {
 		return r.Common.UpdateMonitorResourceStatus(&routeMonitor)
 	}
 	return utilreconcile.ContinueReconcile()
 }
 
-// Ensures that all dependencies related to a RouteMonitor are deleted
-func (r *RouteMonitorReconciler) EnsureMonitorAndDependenciesAbsent(routeMonitor v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
-	log := r.Log.WithName("Delete")
-
-	shouldDeleteBlackBoxResources, err := r.BlackBoxExporter.ShouldDeleteBlackBoxExporterResources()
-	if err != nil {
-		return utilreconcile.RequeueReconcileWith(err)
+// EnsurePreviousServiceMonitorAbsent deletes the ServiceMonitor referenced by the RouteMonitor's status, unless it is the
+// one of the target's name and type. When only the type changed, the ServiceMonitor of the new type has the same name,
+// so only the one of the other type is deleted
+func (r *RouteMonitorReconciler) EnsurePreviousServiceMonitorAbsent(routeMonitor v1alpha1.RouteMonitor, target types.NamespacedName, serviceMonitorType string) error {
+	serviceMonitorRef := routeMonitor.Status.ServiceMonitorRef
+	if reconcileCommon.ReferenceChanged(serviceMonitorRef, target) {
+		return r.deleteServiceMonitor(serviceMonitorRef, routeMonitor.Status.ServiceMonitorType)
 	}
-	log.V(2).Info("Response of ShouldDeleteBlackBoxExporterResources", "shouldDeleteBlackBoxResources", shouldDeleteBlackBoxResources)
+	if serviceMonitorRef == (v1alpha1.NamespacedName{}) || routeMonitor.Status.ServiceMonitorType == serviceMonitorType {
+		return nil
+	}
+	return r.ServiceMonitor.DeleteServiceMonitorDeployment(serviceMonitorRef, serviceMonitorType != v1alpha1.ServiceMonitorTypeRHOBS)
+}
 
-	if shouldDeleteBlackBoxResources {
-		log.V(2).Info("Entering ensureBlackBoxExporterResourcesAbsent")
-		err := r.BlackBoxExporter.EnsureBlackBoxExporterResourcesAbsent()
-		if err != nil {
-			return utilreconcile.RequeueReconcileWith(err)
-		}
+// deleteServiceMonitor deletes the referenced ServiceMonitor of the recorded type. RouteMonitors reconciled before
+// the type was recorded in their status may have left a ServiceMonitor of either type behind, so both are deleted
+func (r *RouteMonitorReconciler) deleteServiceMonitor(serviceMonitorRef v1alpha1.NamespacedName, serviceMonitorType string) error {
+	switch serviceMonitorType {
+	case v1alpha1.ServiceMonitorTypeCoreOS:
+		return r.ServiceMonitor.DeleteServiceMonitorDeployment(serviceMonitorRef, false)
+	case v1alpha1.ServiceMonitorTypeRHOBS:
+		return r.ServiceMonitor.DeleteServiceMonitorDeployment(serviceMonitorRef, true)
+	}
+	if err := r.ServiceMonitor.DeleteServiceMonitorDeployment(serviceMonitorRef, false); err != nil {
+		return err
 	}
+	return r.ServiceMonitor.DeleteServiceMonitorDeployment(serviceMonitorRef, true)
+}
+
+// Ensures that all dependencies related to a RouteMonitor are deleted
+func (r *RouteMonitorReconciler) EnsureMonitorAndDependenciesAbsent(routeMonitor v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
+	log := r.Log.WithName("Delete")
 
 	log.V(2).Info("Entering ensureServiceMonitorResourceAbsent")
-	isHCP := false
-	if err = r.ServiceMonitor.DeleteServiceMonitorDeployment(routeMonitor.Status.ServiceMonitorRef, isHCP); err != nil {
+	if err := r.deleteServiceMonitor(routeMonitor.Status.ServiceMonitorRef, routeMonitor.Status.ServiceMonitorType); err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
 
 	log.V(2).Info("Entering ensurePrometheusRuleResourceAbsent")
-	err = r.Prom.DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef)
-	if err != nil {
+	if err := r.Prom.DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef, routeMonitor.Status.PrometheusRuleType == v1alpha1.ServiceMonitorTypeRHOBS); err != nil {
 		return utilreconcile.RequeueReconcileWith(err)
 	}
 
@@ -215,18 +386,11 @@ func (r *RouteMonitorReconciler) EnsureRouteURLExists(route routev1.Route, route
 		return utilreconcile.RequeueReconcileWith(customerrors.ErrNoHost)
 	}
 
-	if routeMonitor.Spec.Route.Port != 0 {
-		extractedRouteURL = fmt.Sprintf("%s:%d", extractedRouteURL, routeMonitor.Spec.Route.Port)
-	}
-	if routeMonitor.Spec.Route.Suffix != "" {
-		extractedRouteURL = fmt.Sprintf("%s%s", extractedRouteURL, routeMonitor.Spec.Route.Suffix)
-	}
-
 	currentRouteURL := routeMonitor.Status.RouteURL
 	if route.Spec.TLS != nil {
 		r.Log.V(3).Info("TLS detected: adding https to extractedRouteURL as the url ")
-		extractedRouteURL = fmt.Sprintf("https://%s", extractedRouteURL)
 	}
+	extractedRouteURL = routeMonitor.Spec.Route.URL(extractedRouteURL, route.Spec.TLS != nil)
 
 	if currentRouteURL == extractedRouteURL {
 		r.Log.V(3).Info("Same RouteURL: currentRouteURL and extractedRouteURL are equal, update not required")
@@ -235,6 +399,7 @@ func (r *RouteMonitorReconciler) EnsureRouteURLExists(route routev1.Route, route
 
 	if currentRouteURL != "" && extractedRouteURL != currentRouteURL {
 		r.Log.V(3).Info("RouteURL mismatch: currentRouteURL and extractedRouteURL are not equal, taking extractedRouteURL as source of truth")
+		r.Recorder.Eventf(&routeMonitor, corev1.EventTypeNormal, consts.EventReasonRouteURLChanged, "RouteURL changed from %s to %s", currentRouteURL, extractedRouteURL)
 	}
 
 	routeMonitor.Status.RouteURL = extractedRouteURL
@@ -1,6 +1,8 @@
 package routemonitor_test
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	fuzz "github.com/google/gofuzz"
 	. "github.com/onsi/ginkgo"
@@ -17,13 +19,17 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
 	routemonitorconst "github.com/openshift/route-monitor-operator/pkg/consts"
-	"github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
 	consterror "github.com/openshift/route-monitor-operator/pkg/consts/test/error"
 	constinit "github.com/openshift/route-monitor-operator/pkg/consts/test/init"
 	customerrors "github.com/openshift/route-monitor-operator/pkg/util/errors"
@@ -48,6 +54,7 @@ var _ = Describe("Routemonitor", func() {
 		get    helper.MockHelper
 		create helper.MockHelper
 
+		recorder                      *record.FakeRecorder
 		routeMonitorReconciler        routemonitor.RouteMonitorReconciler
 		routeMonitor                  v1alpha1.RouteMonitor
 		routeMonitorFinalizers        []string
@@ -62,7 +69,9 @@ var _ = Describe("Routemonitor", func() {
 		mockServiceMonitor = controllermocks.NewMockServiceMonitorHandler(mockCtrl)
 		mockPrometheusRule = controllermocks.NewMockPrometheusRuleHandler(mockCtrl)
 
+		recorder = record.NewFakeRecorder(10)
 		routeMonitorReconciler = routemonitor.RouteMonitorReconciler{
+			Recorder:         recorder,
 			Log:              logr.Discard(),
 			Client:           mockClient,
 			Scheme:           constinit.Scheme,
@@ -124,149 +133,66 @@ var _ = Describe("Routemonitor", func() {
 	//--------------------------------------------------------------------------------------
 	Describe("EnsureMonitorAndDependenciesAbsent", func() {
 		var (
-			shouldDeleteBlackBoxExporterResources         helper.MockHelper
-			ensureBlackBoxExporterResourcesAbsent         helper.MockHelper
-			ensureBlackBoxExporterResourcesExist          helper.MockHelper
-			deleteServiceMonitorDeployment                helper.MockHelper
-			deletePrometheusRuleDeployment                helper.MockHelper
-			deleteFinalizer                               helper.MockHelper
-			shouldDeleteBlackBoxExporterResourcesResponse blackboxexporter.ShouldDeleteBlackBoxExporter
+			deleteServiceMonitorDeployment helper.MockHelper
+			deletePrometheusRuleDeployment helper.MockHelper
+			deleteFinalizer                helper.MockHelper
 
 			res utilreconcile.Result
 			err error
 		)
 		BeforeEach(func() {
-			shouldDeleteBlackBoxExporterResources = helper.MockHelper{}
-			ensureBlackBoxExporterResourcesAbsent = helper.MockHelper{}
-			ensureBlackBoxExporterResourcesExist = helper.MockHelper{}
 			deleteServiceMonitorDeployment = helper.MockHelper{}
 			deletePrometheusRuleDeployment = helper.MockHelper{}
 			deleteFinalizer = helper.MockHelper{}
-			shouldDeleteBlackBoxExporterResourcesResponse = blackboxexporter.KeepBlackBoxExporter
+			routeMonitor.Status.ServiceMonitorType = v1alpha1.ServiceMonitorTypeCoreOS
 		})
 		JustBeforeEach(func() {
-
-			shouldDeleteBlackBoxExporterResources.CalledTimes = 1
-
-			mockBlackboxExporter.EXPECT().EnsureBlackBoxExporterResourcesAbsent().
-				Times(ensureBlackBoxExporterResourcesAbsent.CalledTimes).
-				Return(ensureBlackBoxExporterResourcesAbsent.ErrorResponse)
-
-			mockBlackboxExporter.EXPECT().ShouldDeleteBlackBoxExporterResources().
-				Times(shouldDeleteBlackBoxExporterResources.CalledTimes).
-				Return(shouldDeleteBlackBoxExporterResourcesResponse, shouldDeleteBlackBoxExporterResources.ErrorResponse)
-
-			mockBlackboxExporter.EXPECT().EnsureBlackBoxExporterResourcesExist().
-				Times(ensureBlackBoxExporterResourcesExist.CalledTimes).
-				Return(ensureBlackBoxExporterResourcesExist.ErrorResponse)
-
 			mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(gomock.Any(), gomock.Any()).
 				Times(deleteServiceMonitorDeployment.CalledTimes).
 				Return(deleteServiceMonitorDeployment.ErrorResponse)
 
-			mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(gomock.Any()).
+			mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(gomock.Any(), false).
 				Times(deletePrometheusRuleDeployment.CalledTimes).
 				Return(deletePrometheusRuleDeployment.ErrorResponse)
 
 			// act
 			res, err = routeMonitorReconciler.EnsureMonitorAndDependenciesAbsent(routeMonitor)
 		})
-		When("func ShouldDeleteBlackBoxExporterResources fails unexpectedly", func() {
-			BeforeEach(func() {
-				shouldDeleteBlackBoxExporterResources.ErrorResponse = consterror.ErrCustomError
-			})
-			It("should bubble up the error", func() {
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(MatchError(consterror.ErrCustomError))
-			})
-		})
-		Describe("ShouldDeleteBlackBoxExporterResources instructs to delete", func() {
+		When("the RouteMonitor has dependencies", func() {
 			BeforeEach(func() {
-				shouldDeleteBlackBoxExporterResourcesResponse = blackboxexporter.DeleteBlackBoxExporter
-				ensureBlackBoxExporterResourcesAbsent.CalledTimes = 1
-			})
-			When("func EnsureBlackBoxExporterServiceAbsent fails unexpectedly", func() {
-				BeforeEach(func() {
-					ensureBlackBoxExporterResourcesAbsent.ErrorResponse = consterror.ErrCustomError
-				})
-				It("should bubble up the error", func() {
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(consterror.ErrCustomError))
-				})
-			})
-			When("func EnsureBlackBoxExporterDeploymentAbsent fails unexpectedly", func() {
-				BeforeEach(func() {
-					ensureBlackBoxExporterResourcesAbsent = helper.CustomErrorHappensOnce()
-				})
-				It("should bubble up the error", func() {
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(consterror.ErrCustomError))
-				})
-			})
-			When("func deleteServiceMonitorDeployment fails unexpectedly", func() {
-				BeforeEach(func() {
-					ensureBlackBoxExporterResourcesAbsent.CalledTimes = 1
-					deleteServiceMonitorDeployment = helper.CustomErrorHappensOnce()
-				})
-				It("should bubble up the error", func() {
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(consterror.ErrCustomError))
-				})
-			})
-			When("func DeletePrometheusRuleDeployment fails unexpectedly", func() {
-				BeforeEach(func() {
-					ensureBlackBoxExporterResourcesAbsent.CalledTimes = 1
-					deleteServiceMonitorDeployment.CalledTimes = 1
-					deletePrometheusRuleDeployment = helper.CustomErrorHappensOnce()
-				})
-				It("should bubble up the error", func() {
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(consterror.ErrCustomError))
-				})
+				deleteServiceMonitorDeployment.CalledTimes = 1
+				deletePrometheusRuleDeployment.CalledTimes = 1
 			})
-			When("func EnsureFinalizerAbsent fails unexpectedly", func() {
+			When("func EnsureServiceMonitorResourceAbsent fails unexpectedly", func() {
 				BeforeEach(func() {
-					ensureBlackBoxExporterResourcesAbsent.CalledTimes = 1
-					deleteServiceMonitorDeployment.CalledTimes = 1
-					deletePrometheusRuleDeployment.CalledTimes = 1
-					mockUtils.EXPECT().DeleteFinalizer(gomock.Any(), gomock.Any()).Return(true).Times(2)
-					mockUtils.EXPECT().UpdateMonitorResource(gomock.Any()).Return(utilreconcile.RequeueOperation(), consterror.ErrCustomError)
+					deleteServiceMonitorDeployment.ErrorResponse = consterror.ErrCustomError
+					deletePrometheusRuleDeployment.CalledTimes = 0
 				})
 				It("should bubble up the error", func() {
 					Expect(err).To(HaveOccurred())
 					Expect(err).To(MatchError(consterror.ErrCustomError))
 				})
 			})
-			When("all deletions happened successfully", func() {
+			When("the type of the ServiceMonitor was not recorded", func() {
 				BeforeEach(func() {
-					ensureBlackBoxExporterResourcesAbsent.CalledTimes = 1
-					deleteServiceMonitorDeployment.CalledTimes = 1
-					deletePrometheusRuleDeployment.CalledTimes = 1
-					deleteFinalizer.CalledTimes = 1
-					mockUtils.EXPECT().DeleteFinalizer(gomock.Any(), gomock.Any()).Return(true).Times(2)
-					mockUtils.EXPECT().UpdateMonitorResource(gomock.Any())
+					routeMonitor.Status.ServiceMonitorType = ""
+					deleteServiceMonitorDeployment.CalledTimes = 2
+					mockUtils.EXPECT().DeleteFinalizer(gomock.Any(), gomock.Any()).Return(false)
 				})
-				It("should reconcile", func() {
+				It("should delete the ServiceMonitors of both types", func() {
 					Expect(err).NotTo(HaveOccurred())
 					Expect(res).To(Equal(utilreconcile.StopOperation()))
 				})
 			})
-		})
-		When("ShouldDeleteBlackBoxExporterResources instructs to keep the BlackBoxExporter", func() {
-			BeforeEach(func() {
-				shouldDeleteBlackBoxExporterResources.CalledTimes = 1
-				shouldDeleteBlackBoxExporterResourcesResponse = blackboxexporter.KeepBlackBoxExporter
-				deleteServiceMonitorDeployment.CalledTimes = 1
-				deletePrometheusRuleDeployment.CalledTimes = 1
-			})
-			When("func EnsureServiceMonitorResourceAbsent fails unexpectedly", func() {
+			When("the ServiceMonitor is of the RHOBS type", func() {
 				BeforeEach(func() {
-					deleteServiceMonitorDeployment.ErrorResponse = consterror.ErrCustomError
-					deletePrometheusRuleDeployment.CalledTimes = 0
+					routeMonitor.Status.ServiceMonitorType = v1alpha1.ServiceMonitorTypeRHOBS
+					deleteServiceMonitorDeployment.CalledTimes = 0
+					mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(gomock.Any(), true).Return(nil)
+					mockUtils.EXPECT().DeleteFinalizer(gomock.Any(), gomock.Any()).Return(false)
 				})
-				It("should bubble up the error", func() {
-					Expect(err).To(HaveOccurred())
-					Expect(err).To(MatchError(consterror.ErrCustomError))
+				It("should delete the RHOBS ServiceMonitor", func() {
+					Expect(err).NotTo(HaveOccurred())
 				})
 			})
 			When("func EnsurePrometheusRuleResourceAbsent fails unexpectedly", func() {
@@ -612,6 +538,18 @@ var _ = Describe("Routemonitor", func() {
 			})
 		})
 
+		When("the Route's host changed since the RouteURL was recorded", func() {
+			BeforeEach(func() {
+				ingresses = []string{"new-host"}
+				routeMonitor.Status.RouteURL = "old-host"
+				mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Times(1).Return(utilreconcile.StopOperation(), nil)
+			})
+			It("should emit a RouteURLChanged event", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(recorder.Events).To(Receive(Equal("Normal RouteURLChanged RouteURL changed from old-host to new-host")))
+			})
+		})
+
 		When("the Route has the same RouteURL as the extracted one", func() {
 			BeforeEach(func() {
 				ingresses = []string{
@@ -659,6 +597,9 @@ var _ = Describe("Routemonitor", func() {
 						Expect(err).NotTo(HaveOccurred())
 						Expect(resp).To(Equal(utilreconcile.StopOperation()))
 					})
+					It("emits an InvalidSLO warning", func() {
+						Expect(recorder.Events).To(Receive(HavePrefix("Warning InvalidSLO")))
+					})
 				})
 				When("updating the RouteMonitor new error State failes", func() {
 					BeforeEach(func() {
@@ -678,7 +619,7 @@ var _ = Describe("Routemonitor", func() {
 				})
 				When("the PrometheusRule deletion fails", func() {
 					BeforeEach(func() {
-						mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef).Times(1).Return(consterror.ErrCustomError)
+						mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef, false).Times(1).Return(consterror.ErrCustomError)
 					})
 					It("should reconcile with the particular error", func() {
 						Expect(err).To(Equal(consterror.ErrCustomError))
@@ -687,11 +628,11 @@ var _ = Describe("Routemonitor", func() {
 				})
 				When("the PrometheusRule deletion was successful", func() {
 					BeforeEach(func() {
-						mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef).Times(1)
+						mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(routeMonitor.Status.PrometheusRuleRef, false).Times(1)
 					})
 					When("updating PrometheusRuleRef in the RouteMonitor fails", func() {
 						BeforeEach(func() {
-							mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true, nil)
+							mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true)
 							mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Return(utilreconcile.RequeueOperation(), consterror.ErrCustomError)
 						})
 						It("should reconcile with the particular error", func() {
@@ -701,7 +642,7 @@ var _ = Describe("Routemonitor", func() {
 					})
 					When("updating PrometheusRuleRef in the RouteMonitor was successful", func() {
 						BeforeEach(func() {
-							mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true, nil)
+							mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true)
 							mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Return(utilreconcile.StopOperation(), nil)
 						})
 						It("stops reconciling", func() {
@@ -712,6 +653,34 @@ var _ = Describe("Routemonitor", func() {
 				})
 			})
 		})
+		Describe("The RouteMonitor alerting settings are INVALID", func() {
+			BeforeEach(func() {
+				routeMonitor.Spec.Alerting.Labels = map[string]string{"severity": "info"}
+				mockUtils.EXPECT().ParseMonitorSLOSpecs(routeMonitor.Status.RouteURL, routeMonitor.Spec.Slo).Return("99.5", nil).Times(1)
+			})
+			When("the error is not yet reported", func() {
+				BeforeEach(func() {
+					mockUtils.EXPECT().SetErrorStatus(gomock.Any(), gomock.Not(gomock.Nil())).Return(true)
+					mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Return(utilreconcile.StopOperation(), nil)
+				})
+				It("stops reconciling", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+				})
+				It("emits an InvalidAlerting warning", func() {
+					Expect(recorder.Events).To(Receive(HavePrefix("Warning InvalidAlerting")))
+				})
+			})
+			When("the error is already reported", func() {
+				BeforeEach(func() {
+					mockUtils.EXPECT().SetErrorStatus(gomock.Any(), gomock.Not(gomock.Nil())).Return(false)
+				})
+				It("stops reconciling without updating the PrometheusRule", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+				})
+			})
+		})
 		Describe("The RouteMonitor settings are VALID", func() {
 			BeforeEach(func() {
 				mockUtils.EXPECT().ParseMonitorSLOSpecs(routeMonitor.Status.RouteURL, routeMonitor.Spec.Slo).Return("99.5", nil).Times(1)
@@ -719,7 +688,7 @@ var _ = Describe("Routemonitor", func() {
 			})
 			When("the update the PrometheusRule failed", func() {
 				BeforeEach(func() {
-					mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any()).Return(consterror.ErrCustomError)
+					mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any(), false).Return(controllerutil.OperationResultNone, consterror.ErrCustomError)
 				})
 				It("requeues with the error", func() {
 					Expect(err).To(Equal(consterror.ErrCustomError))
@@ -728,11 +697,11 @@ var _ = Describe("Routemonitor", func() {
 			})
 			When("the update of the PrometheusRule succeeded", func() {
 				BeforeEach(func() {
-					mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any())
+					mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any(), false).Return(controllerutil.OperationResultCreated, nil)
 				})
 				When("a new PrometheusRule was created", func() {
 					BeforeEach(func() {
-						mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true, nil)
+						mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true)
 					})
 					When("the ServiceMonitor is updated successfully", func() {
 						BeforeEach(func() {
@@ -742,6 +711,9 @@ var _ = Describe("Routemonitor", func() {
 							Expect(err).NotTo(HaveOccurred())
 							Expect(resp).To(Equal(utilreconcile.StopOperation()))
 						})
+						It("emits a PrometheusRuleCreated event", func() {
+							Expect(recorder.Events).To(Receive(HavePrefix("Normal PrometheusRuleCreated")))
+						})
 					})
 					When("updating the reference fails", func() {
 						BeforeEach(func() {
@@ -754,6 +726,47 @@ var _ = Describe("Routemonitor", func() {
 					})
 				})
 			})
+			When("the RouteMonitor is scraped by RHOBS", func() {
+				previousRef := v1alpha1.NamespacedName{Name: "previous", Namespace: "previous-namespace"}
+				BeforeEach(func() {
+					routeMonitor.Spec.ServiceMonitorType = v1alpha1.ServiceMonitorTypeRHOBS
+					routeMonitor.Status.PrometheusRuleRef = previousRef
+					mockPrometheusRule.EXPECT().UpdatePrometheusRuleDeployment(gomock.Any(), true).Return(controllerutil.OperationResultCreated, nil)
+				})
+				When("a CoreOS PrometheusRule was created before", func() {
+					BeforeEach(func() {
+						routeMonitor.Status.PrometheusRuleType = ""
+						mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(previousRef, false).Return(nil)
+						mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(false)
+						mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Return(utilreconcile.StopOperation(), nil)
+					})
+					It("replaces it with a RHOBS PrometheusRule", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(utilreconcile.StopOperation()))
+					})
+				})
+				When("the CoreOS PrometheusRule can not be deleted", func() {
+					BeforeEach(func() {
+						routeMonitor.Status.PrometheusRuleType = v1alpha1.ServiceMonitorTypeCoreOS
+						mockPrometheusRule.EXPECT().DeletePrometheusRuleDeployment(previousRef, false).Return(consterror.ErrCustomError)
+					})
+					It("requeues with the error without switching the PrometheusRuleType", func() {
+						Expect(err).To(Equal(consterror.ErrCustomError))
+						Expect(resp).To(Equal(utilreconcile.RequeueOperation()))
+					})
+				})
+				When("the RHOBS PrometheusRule is already recorded", func() {
+					BeforeEach(func() {
+						routeMonitor.Status.PrometheusRuleRef = v1alpha1.NamespacedName{Name: routeMonitor.Name, Namespace: routeMonitor.Namespace}
+						routeMonitor.Status.PrometheusRuleType = v1alpha1.ServiceMonitorTypeRHOBS
+						mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(false)
+					})
+					It("continues reconciling", func() {
+						Expect(err).NotTo(HaveOccurred())
+						Expect(resp).To(Equal(utilreconcile.ContinueOperation()))
+					})
+				})
+			})
 		})
 	})
 	//--------------------------------------------------------------------------------------
@@ -776,11 +789,82 @@ var _ = Describe("Routemonitor", func() {
 				Expect(resp).To(Equal(utilreconcile.RequeueOperation()))
 			})
 		})
+		When("the probe expects an invalid regular expression", func() {
+			BeforeEach(func() {
+				routeMonitor.Spec.Probe.Expect = &v1alpha1.ProbeExpectSpec{BodyRegex: "(unclosed"}
+			})
+			When("the error is not reported yet", func() {
+				BeforeEach(func() {
+					mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).DoAndReturn(func(monitor *v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
+						Expect(monitor.Status.ErrorStatus).To(ContainSubstring("probe.expect.bodyRegex"))
+						return utilreconcile.StopOperation(), nil
+					})
+				})
+				It("reports the error in the status without updating the ServiceMonitor", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+					Expect(recorder.Events).To(Receive(HavePrefix("Warning InvalidProbe")))
+				})
+			})
+			When("the status holds the error of another step", func() {
+				BeforeEach(func() {
+					routeMonitor.Status.ErrorStatus = customerrors.ErrInvalidSLO.Error()
+					mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).DoAndReturn(func(monitor *v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
+						Expect(monitor.Status.ErrorStatus).To(ContainSubstring("probe.expect.bodyRegex"))
+						return utilreconcile.StopOperation(), nil
+					})
+				})
+				It("replaces it with the probe error", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+					Expect(recorder.Events).To(Receive(HavePrefix("Warning InvalidProbe")))
+				})
+			})
+			When("the error is already reported", func() {
+				BeforeEach(func() {
+					routeMonitor.Status.ErrorStatus = blackboxexporter.ValidateProbe(routeMonitor.Spec.Probe).Error()
+				})
+				It("stops reconciling", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+				})
+			})
+		})
+		When("the probe references a Secret which does not exist", func() {
+			BeforeEach(func() {
+				mockUtils.EXPECT().GetOSDClusterID().Return("test-cluster-id", nil)
+				mockBlackboxExporter.EXPECT().GetExporterService("").Return(types.NamespacedName{Name: "blackbox-exporter", Namespace: "bla"}, nil)
+				mockBlackboxExporter.EXPECT().ValidateProbeReferences(gomock.Any(), gomock.Any()).Return(consterror.ErrCustomError)
+			})
+			When("the error is not reported yet", func() {
+				BeforeEach(func() {
+					mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).DoAndReturn(func(monitor *v1alpha1.RouteMonitor) (utilreconcile.Result, error) {
+						Expect(monitor.Status.ErrorStatus).To(Equal(consterror.ErrCustomError.Error()))
+						return utilreconcile.StopOperation(), nil
+					})
+				})
+				It("reports the error in the status without updating the ServiceMonitor", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+					Expect(recorder.Events).To(Receive(HavePrefix("Warning InvalidProbe")))
+				})
+			})
+			When("the error is already reported", func() {
+				BeforeEach(func() {
+					routeMonitor.Status.ErrorStatus = consterror.ErrCustomError.Error()
+				})
+				It("will requeue with the error until the Secret exists", func() {
+					Expect(err).To(Equal(consterror.ErrCustomError))
+					Expect(resp).To(Equal(utilreconcile.RequeueOperation()))
+				})
+			})
+		})
 		Describe("It updates the ServiceMonitor targeting the blackbox Exporter Namespace", func() {
 			When("the update of the ServiceMonitor fails", func() {
 				BeforeEach(func() {
-					mockServiceMonitor.EXPECT().TemplateAndUpdateServiceMonitorDeployment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(consterror.ErrCustomError)
-					mockBlackboxExporter.EXPECT().GetBlackBoxExporterNamespace().Return("bla")
+					mockServiceMonitor.EXPECT().TemplateAndUpdateServiceMonitorDeployment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(controllerutil.OperationResultNone, consterror.ErrCustomError)
+					mockBlackboxExporter.EXPECT().GetExporterService("").Return(types.NamespacedName{Name: "blackbox-exporter", Namespace: "bla"}, nil)
+					mockBlackboxExporter.EXPECT().ValidateProbeReferences(routeMonitor.Namespace, routeMonitor.Spec.Probe).Return(nil)
 					mockUtils.EXPECT().GetOSDClusterID().Return("test-cluster-id", nil)
 				})
 				It("will requeue with the error", func() {
@@ -790,22 +874,41 @@ var _ = Describe("Routemonitor", func() {
 			})
 			When("the update of the ServiceMonitor is successful", func() {
 				BeforeEach(func() {
-					mockServiceMonitor.EXPECT().TemplateAndUpdateServiceMonitorDeployment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
-					mockBlackboxExporter.EXPECT().GetBlackBoxExporterNamespace().Return("bla")
+					mockServiceMonitor.EXPECT().TemplateAndUpdateServiceMonitorDeployment(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(controllerutil.OperationResultUpdated, nil)
+					mockBlackboxExporter.EXPECT().GetExporterService("").Return(types.NamespacedName{Name: "blackbox-exporter", Namespace: "bla"}, nil)
+					mockBlackboxExporter.EXPECT().ValidateProbeReferences(routeMonitor.Namespace, routeMonitor.Spec.Probe).Return(nil)
 					mockUtils.EXPECT().GetOSDClusterID().Return("test-cluster-id", nil)
 				})
-				When("the update of the ServiceMonitorRef fails", func() {
+				When("the ServiceMonitorRef points to a previous ServiceMonitor", func() {
+					previousRef := v1alpha1.NamespacedName{Name: "previous", Namespace: "previous-namespace"}
 					BeforeEach(func() {
-						mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(false, consterror.ErrCustomError)
+						routeMonitor.Status.ServiceMonitorRef = previousRef
+						routeMonitor.Status.ServiceMonitorType = v1alpha1.ServiceMonitorTypeCoreOS
 					})
-					It("will requeue with the error", func() {
-						Expect(err).To(Equal(consterror.ErrCustomError))
-						Expect(resp).To(Equal(utilreconcile.RequeueOperation()))
+					When("the previous ServiceMonitor can not be deleted", func() {
+						BeforeEach(func() {
+							mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(previousRef, false).Return(consterror.ErrCustomError)
+						})
+						It("will requeue with the error without switching the ServiceMonitorRef", func() {
+							Expect(err).To(Equal(consterror.ErrCustomError))
+							Expect(resp).To(Equal(utilreconcile.RequeueOperation()))
+						})
+					})
+					When("the previous ServiceMonitor is deleted", func() {
+						BeforeEach(func() {
+							mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(previousRef, false).Return(nil)
+							mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true)
+							mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Return(utilreconcile.StopOperation(), nil)
+						})
+						It("switches the ServiceMonitorRef to the new ServiceMonitor", func() {
+							Expect(err).NotTo(HaveOccurred())
+							Expect(resp).To(Equal(utilreconcile.StopOperation()))
+						})
 					})
 				})
 				When("the update of the ServiceMonitorRef is successful", func() {
 					BeforeEach(func() {
-						mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true, nil)
+						mockUtils.EXPECT().SetResourceReference(gomock.Any(), gomock.Any()).Return(true)
 					})
 					When("it updates the RouteMonitor", func() {
 						BeforeEach(func() {
@@ -815,8 +918,256 @@ var _ = Describe("Routemonitor", func() {
 							Expect(err).NotTo(HaveOccurred())
 							Expect(resp).To(Equal(utilreconcile.StopOperation()))
 						})
+						It("emits a ServiceMonitorUpdated event", func() {
+							Expect(recorder.Events).To(Receive(HavePrefix("Normal ServiceMonitorUpdated")))
+						})
+					})
+				})
+			})
+		})
+	})
+	//--------------------------------------------------------------------------------------
+	// 		EnsureDashboardExists
+	//--------------------------------------------------------------------------------------
+	Describe("EnsureDashboardExists", func() {
+		var (
+			mockDashboard *controllermocks.MockDashboardHandler
+			resp          utilreconcile.Result
+			err           error
+		)
+		BeforeEach(func() {
+			mockDashboard = controllermocks.NewMockDashboardHandler(mockCtrl)
+			routeMonitorReconciler.Dashboard = mockDashboard
+		})
+		JustBeforeEach(func() {
+			resp, err = routeMonitorReconciler.EnsureDashboardExists(routeMonitor)
+		})
+		When("dashboards are disabled", func() {
+			BeforeEach(func() {
+				routeMonitor.Status.DashboardRef = v1alpha1.NamespacedName{Name: "scott-pilgrim-dashboard", Namespace: "the-world"}
+				mockDashboard.EXPECT().Enabled().Return(false)
+			})
+			When("the dashboard deletion fails", func() {
+				BeforeEach(func() {
+					mockDashboard.EXPECT().DeleteDashboardDeployment(routeMonitor.Status.DashboardRef).Return(consterror.ErrCustomError)
+				})
+				It("will requeue with the error", func() {
+					Expect(err).To(Equal(consterror.ErrCustomError))
+					Expect(resp).To(Equal(utilreconcile.RequeueOperation()))
+				})
+			})
+			When("the dashboard deletion succeeds", func() {
+				BeforeEach(func() {
+					mockDashboard.EXPECT().DeleteDashboardDeployment(routeMonitor.Status.DashboardRef).Return(nil)
+					mockUtils.EXPECT().SetResourceReference(gomock.Any(), types.NamespacedName{}).Return(true)
+					mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Return(utilreconcile.StopOperation(), nil)
+				})
+				It("clears the DashboardRef", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+				})
+			})
+		})
+		When("dashboards are enabled", func() {
+			BeforeEach(func() {
+				mockDashboard.EXPECT().Enabled().Return(true)
+			})
+			When("the update of the dashboard fails", func() {
+				BeforeEach(func() {
+					mockDashboard.EXPECT().TemplateAndUpdateDashboardDeployment("fake-route-url", "0.995", gomock.Any(), gomock.Any()).Return(controllerutil.OperationResultNone, consterror.ErrCustomError)
+				})
+				It("will requeue with the error", func() {
+					Expect(err).To(Equal(consterror.ErrCustomError))
+					Expect(resp).To(Equal(utilreconcile.RequeueOperation()))
+				})
+			})
+			When("a new dashboard was created", func() {
+				BeforeEach(func() {
+					mockDashboard.EXPECT().TemplateAndUpdateDashboardDeployment("fake-route-url", "0.995", types.NamespacedName{Name: "scott-pilgrim", Namespace: "the-world"}, gomock.Any()).Return(controllerutil.OperationResultCreated, nil)
+					mockUtils.EXPECT().SetResourceReference(gomock.Any(), types.NamespacedName{Name: "scott-pilgrim-dashboard", Namespace: "the-world"}).Return(true)
+					mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).Return(utilreconcile.StopOperation(), nil)
+				})
+				It("records the DashboardRef", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+				})
+				It("emits a DashboardCreated event", func() {
+					Expect(recorder.Events).To(Receive(HavePrefix("Normal DashboardCreated")))
+				})
+			})
+		})
+	})
+	//--------------------------------------------------------------------------------------
+	// 		EnsureSLOStatus
+	//--------------------------------------------------------------------------------------
+	Describe("EnsureSLOStatus", func() {
+		var (
+			mockSLO      *controllermocks.MockSLOStatusHandler
+			sloStatus    *v1alpha1.SloStatus
+			statusUpdate *v1alpha1.RouteMonitor
+			resp         utilreconcile.Result
+			err          error
+		)
+		BeforeEach(func() {
+			mockSLO = controllermocks.NewMockSLOStatusHandler(mockCtrl)
+			routeMonitorReconciler.SLO = mockSLO
+			sloStatus = &v1alpha1.SloStatus{AvailabilityPercent: "99.900"}
+			statusUpdate = nil
+		})
+		JustBeforeEach(func() {
+			resp, err = routeMonitorReconciler.EnsureSLOStatus(routeMonitor)
+		})
+		When("SLO status reporting is disabled", func() {
+			BeforeEach(func() {
+				routeMonitorReconciler.SLO = nil
+			})
+			It("continues reconciling", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp).To(Equal(utilreconcile.ContinueOperation()))
+			})
+		})
+		When("the SLO status was recently queried", func() {
+			BeforeEach(func() {
+				routeMonitor.Status.SLO = sloStatus
+				mockSLO.EXPECT().ShouldResync(sloStatus).Return(false)
+			})
+			It("continues reconciling without querying Prometheus", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp).To(Equal(utilreconcile.ContinueOperation()))
+			})
+		})
+		When("the SLO status is due to be queried", func() {
+			BeforeEach(func() {
+				mockSLO.EXPECT().ShouldResync(gomock.Nil()).Return(true)
+			})
+			When("the query fails", func() {
+				BeforeEach(func() {
+					mockSLO.EXPECT().GetSLOStatus(gomock.Any(), "fake-route-url", "0.995").Return(nil, consterror.ErrCustomError)
+				})
+				It("keeps the last SLO status and continues reconciling", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.ContinueOperation()))
+					Expect(recorder.Events).To(Receive(HavePrefix("Warning SLOQueryFailed")))
+				})
+			})
+			When("the query succeeds", func() {
+				BeforeEach(func() {
+					mockSLO.EXPECT().GetSLOStatus(gomock.Any(), "fake-route-url", "0.995").Return(sloStatus, nil)
+					mockUtils.EXPECT().UpdateMonitorResourceStatus(gomock.Any()).DoAndReturn(func(cr client.Object) (utilreconcile.Result, error) {
+						statusUpdate = cr.(*v1alpha1.RouteMonitor)
+						return utilreconcile.StopOperation(), nil
 					})
 				})
+				It("records the SLO status on the RouteMonitor", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.StopOperation()))
+					Expect(statusUpdate.Status.SLO).To(Equal(sloStatus))
+				})
+			})
+			When("Prometheus holds no probe results for the RouteMonitor", func() {
+				BeforeEach(func() {
+					mockSLO.EXPECT().GetSLOStatus(gomock.Any(), "fake-route-url", "0.995").Return(nil, nil)
+				})
+				It("continues reconciling without updating the status", func() {
+					Expect(err).NotTo(HaveOccurred())
+					Expect(resp).To(Equal(utilreconcile.ContinueOperation()))
+				})
+			})
+		})
+		When("the RouteMonitor uses RHOBS ServiceMonitors", func() {
+			BeforeEach(func() {
+				routeMonitor.Spec.ServiceMonitorType = v1alpha1.ServiceMonitorTypeRHOBS
+			})
+			It("continues reconciling without querying Prometheus", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp).To(Equal(utilreconcile.ContinueOperation()))
+			})
+		})
+	})
+	//--------------------------------------------------------------------------------------
+	// 		EnsurePreviousServiceMonitorAbsent
+	//--------------------------------------------------------------------------------------
+	Describe("EnsurePreviousServiceMonitorAbsent", func() {
+		var (
+			target = types.NamespacedName{Name: "scott-pilgrim", Namespace: "the-world"}
+			ref    = v1alpha1.NamespacedName{Name: "scott-pilgrim", Namespace: "the-world"}
+			err    error
+		)
+		BeforeEach(func() {
+			routeMonitor.Status.ServiceMonitorRef = ref
+		})
+		When("the RouteMonitor has no ServiceMonitor yet", func() {
+			BeforeEach(func() {
+				routeMonitor.Status.ServiceMonitorRef = v1alpha1.NamespacedName{}
+			})
+			It("deletes nothing", func() {
+				err = routeMonitorReconciler.EnsurePreviousServiceMonitorAbsent(routeMonitor, target, v1alpha1.ServiceMonitorTypeRHOBS)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("the ServiceMonitor is of the desired type", func() {
+			BeforeEach(func() {
+				routeMonitor.Status.ServiceMonitorType = v1alpha1.ServiceMonitorTypeRHOBS
+			})
+			It("deletes nothing", func() {
+				err = routeMonitorReconciler.EnsurePreviousServiceMonitorAbsent(routeMonitor, target, v1alpha1.ServiceMonitorTypeRHOBS)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("the type of the ServiceMonitor changed", func() {
+			BeforeEach(func() {
+				routeMonitor.Status.ServiceMonitorType = v1alpha1.ServiceMonitorTypeCoreOS
+				mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(ref, false).Return(nil)
+			})
+			It("deletes the ServiceMonitor of the previous type", func() {
+				err = routeMonitorReconciler.EnsurePreviousServiceMonitorAbsent(routeMonitor, target, v1alpha1.ServiceMonitorTypeRHOBS)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+		When("the type of the ServiceMonitor was not recorded", func() {
+			BeforeEach(func() {
+				mockServiceMonitor.EXPECT().DeleteServiceMonitorDeployment(ref, true).Return(nil)
+			})
+			It("deletes the ServiceMonitor of the other type", func() {
+				err = routeMonitorReconciler.EnsurePreviousServiceMonitorAbsent(routeMonitor, target, v1alpha1.ServiceMonitorTypeCoreOS)
+				Expect(err).NotTo(HaveOccurred())
+			})
+		})
+	})
+	//--------------------------------------------------------------------------------------
+	// 		RouteMonitorsForRoute
+	//--------------------------------------------------------------------------------------
+	Describe("RouteMonitorsForRoute", func() {
+		var (
+			route    routev1.Route
+			requests []reconcile.Request
+		)
+		BeforeEach(func() {
+			route = routev1.Route{ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"}}
+			watching := v1alpha1.RouteMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "monitors"},
+				Spec:       v1alpha1.RouteMonitorSpec{Route: v1alpha1.RouteMonitorRouteSpec{Name: "console", Namespace: "openshift-console"}},
+			}
+			other := v1alpha1.RouteMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "downloads", Namespace: "monitors"},
+				Spec:       v1alpha1.RouteMonitorSpec{Route: v1alpha1.RouteMonitorRouteSpec{Name: "downloads", Namespace: "openshift-console"}},
+			}
+			builder := fake.NewClientBuilder().WithScheme(constinit.Scheme).WithObjects(&watching, &other)
+			Expect(routemonitor.IndexRoute(context.Background(), builderIndexer{builder})).To(Succeed())
+			routeMonitorReconciler.Client = builder.Build()
+		})
+		JustBeforeEach(func() {
+			requests = routeMonitorReconciler.RouteMonitorsForRoute(context.Background(), &route)
+		})
+		It("enqueues the RouteMonitors of the Route", func() {
+			Expect(requests).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "console", Namespace: "monitors"}}))
+		})
+		When("no RouteMonitor monitors the Route", func() {
+			BeforeEach(func() {
+				route.Namespace = "default"
+			})
+			It("enqueues nothing", func() {
+				Expect(requests).To(BeEmpty())
 			})
 		})
 	})
@@ -826,6 +1177,16 @@ var _ = Describe("Routemonitor", func() {
 // 		Helper Functions
 //--------------------------------------------------------------------------------------
 
+// builderIndexer registers indexes with a fake client
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (i builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	i.builder.WithIndex(obj, field, extractValue)
+	return nil
+}
+
 func ConvertToIngressHosts(in []string) []routev1.RouteIngress {
 	res := make([]routev1.RouteIngress, len(in))
 	for i, s := range in {
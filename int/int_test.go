@@ -87,7 +87,7 @@ var _ = Describe("Integrationtests", func() {
 				err = i.Client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, &clusterConfig)
 				Expect(err).NotTo(HaveOccurred())
 				spec := clusterUrlMonitor.Spec
-				expectedUrl := spec.Prefix + clusterConfig.Spec.BaseDomain + ":" + spec.Port + spec.Suffix
+				expectedUrl := spec.URL(clusterConfig.Spec.BaseDomain)
 				Expect(len(serviceMonitor.Spec.Endpoints)).To(Equal(1))
 				Expect(len(serviceMonitor.Spec.Endpoints[0].Params["target"])).To(Equal(1))
 				Expect(serviceMonitor.Spec.Endpoints[0].Params["target"][0]).To(Equal(expectedUrl))
@@ -180,7 +180,7 @@ var _ = Describe("Integrationtests", func() {
 				err = i.Client.Get(context.TODO(), types.NamespacedName{Name: "cluster"}, &clusterConfig)
 				Expect(err).NotTo(HaveOccurred())
 				spec := clusterUrlMonitor.Spec
-				expectedUrl := spec.Prefix + clusterConfig.Spec.BaseDomain + ":" + spec.Port + spec.Suffix
+				expectedUrl := spec.URL(clusterConfig.Spec.BaseDomain)
 				err = i.ClusterUrlMonitorWaitForPrometheusRuleCorrectSLO(expectedServiceMonitorName, parsedSlo, 20, expectedUrl)
 				Expect(err).NotTo(HaveOccurred())
 			})
@@ -233,7 +233,10 @@ func (i *Integration) RouteMonitorWaitForPrometheusRuleCorrectSLO(name types.Nam
 		return err
 	}
 
-	template := alert.TemplateForPrometheusRuleResource(routeMonitor.Status.RouteURL, targetSlo, name)
+	template, err := alert.TemplateForPrometheusRuleResource(routeMonitor.Status.RouteURL, targetSlo, name, routeMonitor.Spec.Alerting, routeMonitor.Spec.CertificateExpiry)
+	if err != nil {
+		return err
+	}
 	t := 0
 	for ; t < seconds; t++ {
 		err := i.Client.Get(context.TODO(), name, &prometheusRule)
@@ -270,7 +273,10 @@ func (i *Integration) ClusterUrlMonitorWaitForPrometheusRuleCorrectSLO(name type
 		return err
 	}
 
-	template := alert.TemplateForPrometheusRuleResource(expectedUrl, targetSlo, name)
+	template, err := alert.TemplateForPrometheusRuleResource(expectedUrl, targetSlo, name, clusterUrlMonitor.Spec.Alerting, clusterUrlMonitor.Spec.CertificateExpiry)
+	if err != nil {
+		return err
+	}
 	t := 0
 	for ; t < seconds; t++ {
 		err := i.Client.Get(context.TODO(), name, &prometheusRule)
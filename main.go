@@ -19,10 +19,14 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -46,9 +50,16 @@ import (
 
 	rmov1alpha1 "github.com/openshift/route-monitor-operator/api/v1alpha1"
 	"github.com/openshift/route-monitor-operator/config"
+	"github.com/openshift/route-monitor-operator/controllers"
+	"github.com/openshift/route-monitor-operator/controllers/blackboxexporter"
 	"github.com/openshift/route-monitor-operator/controllers/clusterurlmonitor"
 	"github.com/openshift/route-monitor-operator/controllers/hostedcontrolplane"
+	"github.com/openshift/route-monitor-operator/controllers/probeexporter"
 	"github.com/openshift/route-monitor-operator/controllers/routemonitor"
+	"github.com/openshift/route-monitor-operator/pkg/alert"
+	"github.com/openshift/route-monitor-operator/pkg/dashboard"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
+	"github.com/openshift/route-monitor-operator/pkg/slo"
 	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	// +kubebuilder:scaffold:imports
 )
@@ -74,6 +85,16 @@ func init() {
 }
 
 func main() {
+	if len(os.Args) > 1 {
+		if run, ok := subcommands[os.Args[1]]; ok {
+			if err := run(os.Args[2:]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var enablehypershift bool
@@ -93,14 +114,37 @@ func main() {
 	var oidcClientID string
 	var oidcClientSecret string
 	var oidcIssuerURL string
-
-	flag.StringVar(&blackboxExporterImage, "blackbox-image", "quay.io/prometheus/blackbox-exporter@sha256:b04a9fef4fa086a02fc7fcd8dcdbc4b7b35cc30cdee860fdc6a19dd8b208d63e", "The image that will be used for the blackbox-exporter deployment")
+	var enableDynatrace bool
+	healthcheckConfig := hostedcontrolplane.DefaultHealthCheckConfig()
+	sloConfig := slo.DefaultConfig()
+	dashboardConfig := dashboard.DefaultConfig()
+	dashboardInstanceSelector := labels.Set(dashboardConfig.InstanceSelector).String()
+	alertDefaults := alert.Defaults{}
+
+	// the generated modules use fail_if_body_json_not_matches_cel and proxy_from_environment, which need blackbox exporter v0.26 or newer
+	flag.StringVar(&blackboxExporterImage, "blackbox-image", "quay.io/prometheus/blackbox-exporter:v0.26.0", "The image that will be used for the blackbox-exporter deployment, at least v0.26.0")
 	flag.StringVar(&blackboxExporterNamespace, "blackbox-namespace", config.OperatorNamespace, "Blackbox-exporter deployment will reside on this Namespace")
 	flag.StringVar(&probeAPIURL, "probe-api-url", "", "The fully qualified API URL for RHOBS synthetics probe management (for HostedCluster monitoring). When empty, uses default blackbox exporter behavior.")
 	flag.StringVar(&probeTenant, "probe-tenant", "hcp", "RHOBS tenant name used in API URLs. Defaults to 'hcp'.")
 	flag.StringVar(&oidcClientID, "oidc-client-id", "", "OIDC client ID for RHOBS API authentication. When empty, no OIDC authentication is used.")
 	flag.StringVar(&oidcClientSecret, "oidc-client-secret", "", "OIDC client secret for RHOBS API authentication. When empty, no OIDC authentication is used.")
 	flag.StringVar(&oidcIssuerURL, "oidc-issuer-url", "", "OIDC issuer URL for RHOBS API authentication. When empty, no OIDC authentication is used.")
+	flag.BoolVar(&enableDynatrace, "enable-dynatrace", true, "Enabling this will manage Dynatrace HTTP monitors for HostedControlPlanes. Requires the Dynatrace API secret to be present in the operator's namespace.")
+	flag.IntVar(&healthcheckConfig.ConsecutiveSuccesses, "hcp-healthcheck-successes", healthcheckConfig.ConsecutiveSuccesses, "Number of consecutive successful healthchecks a HostedControlPlane must pass before it is monitored. Setting this to 0 disables healthchecking.")
+	flag.DurationVar(&healthcheckConfig.Interval, "hcp-healthcheck-interval", healthcheckConfig.Interval, "Wait period between HostedControlPlane healthchecks.")
+	flag.DurationVar(&healthcheckConfig.MaxClusterAge, "hcp-healthcheck-max-cluster-age", healthcheckConfig.MaxClusterAge, "HostedControlPlanes older than this are assumed to be healthy and are not healthchecked. Setting this to 0 healthchecks clusters regardless of age.")
+	flag.StringVar(&healthcheckConfig.Endpoint, "hcp-healthcheck-endpoint", healthcheckConfig.Endpoint, "The kube-apiserver endpoint used to healthcheck HostedControlPlanes. One of '/livez', '/readyz' or '/version'.")
+	flag.DurationVar(&healthcheckConfig.Timeout, "hcp-healthcheck-timeout", healthcheckConfig.Timeout, "Timeout for each HostedControlPlane healthcheck request.")
+	flag.StringVar(&sloConfig.QueryURL, "slo-query-url", sloConfig.QueryURL, "Base URL of the Prometheus or Thanos Querier HTTP API used to report the SLO status of RouteMonitors and ClusterUrlMonitors. When empty, SLO status is not reported.")
+	flag.StringVar(&sloConfig.BearerTokenFile, "slo-query-bearer-token-file", sloConfig.BearerTokenFile, "File containing the bearer token sent with SLO status queries.")
+	flag.StringVar(&sloConfig.CAFile, "slo-query-ca-file", sloConfig.CAFile, "File containing the CA bundle used to verify the SLO query endpoint's certificate.")
+	flag.StringVar(&sloConfig.Window, "slo-window", sloConfig.Window, "Period over which the availability and remaining error budget of monitors are reported.")
+	flag.DurationVar(&sloConfig.ResyncInterval, "slo-resync-interval", sloConfig.ResyncInterval, "Wait period between SLO status queries for the same monitor.")
+	flag.StringVar(&dashboardConfig.Type, "dashboard-type", dashboardConfig.Type, "Type of the Grafana dashboard generated for each RouteMonitor and ClusterUrlMonitor, either 'ConfigMap' (for the Grafana dashboard sidecar) or 'GrafanaDashboard'. When empty, no dashboards are generated.")
+	flag.StringVar(&dashboardInstanceSelector, "dashboard-grafana-instance-selector", dashboardInstanceSelector, "Label selector (e.g. 'dashboards=grafana') of the Grafana instances GrafanaDashboard CRs are imported into.")
+	flag.StringVar(&alertDefaults.RunbookURL, "alert-runbook-url", alertDefaults.RunbookURL, "Default runbook_url annotation of the alerts generated for RouteMonitors and ClusterUrlMonitors. Go template which can refer to the monitor's [[ .URL ]], [[ .Namespace ]] and [[ .Name ]].")
+	flag.StringVar(&alertDefaults.Summary, "alert-summary", alertDefaults.Summary, "Default summary annotation of the alerts generated for RouteMonitors and ClusterUrlMonitors. Go template like alert-runbook-url.")
+	flag.StringVar(&alertDefaults.Description, "alert-description", alertDefaults.Description, "Default description annotation of the alerts generated for RouteMonitors and ClusterUrlMonitors. Go template like alert-runbook-url.")
 
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
@@ -156,6 +200,115 @@ func main() {
 			flagParams = append(flagParams, "oidc-issuer-url")
 		}
 
+		if configData.EnableDynatrace != "" {
+			parsed, err := strconv.ParseBool(configData.EnableDynatrace)
+			if err != nil {
+				setupLog.Error(err, "Invalid enable-dynatrace value in ConfigMap, using command-line flag", "enableDynatrace", configData.EnableDynatrace)
+				flagParams = append(flagParams, "enable-dynatrace")
+			} else {
+				setupLog.V(1).Info("Using enable-dynatrace from ConfigMap", "enableDynatrace", parsed)
+				enableDynatrace = parsed
+				configMapParams = append(configMapParams, "enable-dynatrace")
+			}
+		} else {
+			flagParams = append(flagParams, "enable-dynatrace")
+		}
+
+		params := []struct {
+			name  string
+			value string
+			apply func(string) error
+		}{
+			{"hcp-healthcheck-successes", configData.HealthcheckSuccesses, func(v string) error {
+				parsed, err := strconv.Atoi(v)
+				if err == nil {
+					healthcheckConfig.ConsecutiveSuccesses = parsed
+				}
+				return err
+			}},
+			{"hcp-healthcheck-interval", configData.HealthcheckInterval, func(v string) error {
+				parsed, err := time.ParseDuration(v)
+				if err == nil {
+					healthcheckConfig.Interval = parsed
+				}
+				return err
+			}},
+			{"hcp-healthcheck-max-cluster-age", configData.HealthcheckMaxClusterAge, func(v string) error {
+				parsed, err := time.ParseDuration(v)
+				if err == nil {
+					healthcheckConfig.MaxClusterAge = parsed
+				}
+				return err
+			}},
+			{"hcp-healthcheck-endpoint", configData.HealthcheckEndpoint, func(v string) error {
+				healthcheckConfig.Endpoint = v
+				return nil
+			}},
+			{"hcp-healthcheck-timeout", configData.HealthcheckTimeout, func(v string) error {
+				parsed, err := time.ParseDuration(v)
+				if err == nil {
+					healthcheckConfig.Timeout = parsed
+				}
+				return err
+			}},
+			{"slo-query-url", configData.SLOQueryURL, func(v string) error {
+				sloConfig.QueryURL = v
+				return nil
+			}},
+			{"slo-query-bearer-token-file", configData.SLOQueryBearerTokenFile, func(v string) error {
+				sloConfig.BearerTokenFile = v
+				return nil
+			}},
+			{"slo-query-ca-file", configData.SLOQueryCAFile, func(v string) error {
+				sloConfig.CAFile = v
+				return nil
+			}},
+			{"slo-window", configData.SLOWindow, func(v string) error {
+				sloConfig.Window = v
+				return nil
+			}},
+			{"slo-resync-interval", configData.SLOResyncInterval, func(v string) error {
+				parsed, err := time.ParseDuration(v)
+				if err == nil {
+					sloConfig.ResyncInterval = parsed
+				}
+				return err
+			}},
+			{"dashboard-type", configData.DashboardType, func(v string) error {
+				dashboardConfig.Type = v
+				return nil
+			}},
+			{"dashboard-grafana-instance-selector", configData.DashboardInstanceSelector, func(v string) error {
+				dashboardInstanceSelector = v
+				return nil
+			}},
+			{"alert-runbook-url", configData.AlertRunbookURL, func(v string) error {
+				alertDefaults.RunbookURL = v
+				return nil
+			}},
+			{"alert-summary", configData.AlertSummary, func(v string) error {
+				alertDefaults.Summary = v
+				return nil
+			}},
+			{"alert-description", configData.AlertDescription, func(v string) error {
+				alertDefaults.Description = v
+				return nil
+			}},
+		}
+		for _, param := range params {
+			if param.value == "" {
+				flagParams = append(flagParams, param.name)
+				continue
+			}
+			if err := param.apply(param.value); err != nil {
+				setupLog.Error(err, "Invalid value in ConfigMap, using command-line flag", "parameter", param.name, "value", param.value)
+				flagParams = append(flagParams, param.name)
+				continue
+			}
+			setupLog.V(1).Info("Using value from ConfigMap", "parameter", param.name, "value", param.value)
+			configMapParams = append(configMapParams, param.name)
+		}
+
 		// Summarize configuration sources
 		if len(configMapParams) > 0 && len(flagParams) > 0 {
 			setupLog.Info("Using mixed configuration sources",
@@ -174,6 +327,41 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := healthcheckConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid HostedControlPlane healthcheck configuration")
+		os.Exit(1)
+	}
+
+	dashboardConfig.InstanceSelector, err = labels.ConvertSelectorToLabelsMap(dashboardInstanceSelector)
+	if err != nil {
+		setupLog.Error(err, "invalid dashboard-grafana-instance-selector", "selector", dashboardInstanceSelector)
+		os.Exit(1)
+	}
+	if err := dashboardConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid dashboard configuration")
+		os.Exit(1)
+	}
+
+	if err := alertDefaults.Validate(); err != nil {
+		setupLog.Error(err, "invalid default alert annotations")
+		os.Exit(1)
+	}
+
+	if err := sloConfig.Validate(); err != nil {
+		setupLog.Error(err, "invalid SLO status configuration")
+		os.Exit(1)
+	}
+	// sloStatusHandler is left nil when SLO status reporting is disabled
+	var sloStatusHandler controllers.SLOStatusHandler
+	if sloConfig.Enabled() {
+		sloClient, err := slo.NewClient(sloConfig)
+		if err != nil {
+			setupLog.Error(err, "unable to create SLO query client")
+			os.Exit(1)
+		}
+		sloStatusHandler = sloClient
+	}
+
 	enableHCP, err := shouldEnableHCP()
 	if err != nil {
 		setupLog.Error(err, "failed to determine whether HCP controller should be enabled", "controller", "HostedControlPlane")
@@ -225,6 +413,17 @@ func main() {
 				},
 			},
 		}
+		if dashboardConfig.Type == dashboard.TypeConfigMap {
+			// Dashboard ConfigMaps reside in the monitors' namespaces, only cache those outside of the operator's namespace
+			cacheOptions.ByObject[&corev1.ConfigMap{}] = cache.ByObject{
+				Namespaces: map[string]cache.Config{
+					config.OperatorNamespace: {},
+					cache.AllNamespaces: {
+						LabelSelector: labels.SelectorFromSet(labels.Set{dashboard.SidecarLabelName: dashboard.SidecarLabelValue}),
+					},
+				},
+			}
+		}
 	}
 
 	options := ctrl.Options{
@@ -244,18 +443,40 @@ func main() {
 		os.Exit(1)
 	}
 
-	routeMonitorReconciler := routemonitor.NewReconciler(mgr, blackboxExporterImage, blackboxExporterNamespace, enablehypershift, probeAPIURL)
+	if err := metrics.RegisterMonitorInventoryCollector(mgr.GetClient(), ctrl.Log.WithName("metrics")); err != nil {
+		setupLog.Error(err, "unable to register metrics collector")
+		os.Exit(1)
+	}
+
+	routeMonitorReconciler := routemonitor.NewReconciler(mgr, blackboxExporterImage, blackboxExporterNamespace, enablehypershift, probeAPIURL, sloStatusHandler, dashboardConfig, alertDefaults)
 	if err := routeMonitorReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RouteMonitor")
 		os.Exit(1)
 	}
 
-	clusterUrlMonitorReconciler := clusterurlmonitor.NewReconciler(mgr, blackboxExporterImage, blackboxExporterNamespace, enablehypershift, probeAPIURL)
+	clusterUrlMonitorReconciler := clusterurlmonitor.NewReconciler(mgr, blackboxExporterImage, blackboxExporterNamespace, enablehypershift, probeAPIURL, sloStatusHandler, dashboardConfig, alertDefaults)
+	clusterUrlMonitorReconciler.EnableHCP = enableHCP
 	if err := clusterUrlMonitorReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "clusterUrlMonitorReconciler")
 		os.Exit(1)
 	}
 
+	blackBoxExporterReconciler := blackboxexporter.NewReconciler(mgr, blackboxExporterImage, blackboxExporterNamespace)
+	if err := blackBoxExporterReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "BlackBoxExporter")
+		os.Exit(1)
+	}
+
+	probeExporterReconciler, err := probeexporter.NewReconciler(mgr, blackboxExporterImage)
+	if err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ProbeExporter")
+		os.Exit(1)
+	}
+	if err := probeExporterReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "ProbeExporter")
+		os.Exit(1)
+	}
+
 	if enableHCP {
 		rhobsConfig := hostedcontrolplane.RHOBSConfig{
 			ProbeAPIURL:      probeAPIURL,
@@ -264,7 +485,10 @@ func main() {
 			OIDCClientSecret: oidcClientSecret,
 			OIDCIssuerURL:    oidcIssuerURL,
 		}
-		hostedControlPlaneReconciler := hostedcontrolplane.NewHostedControlPlaneReconciler(mgr, rhobsConfig)
+		dynatraceConfig := hostedcontrolplane.DynatraceConfig{
+			Enabled: enableDynatrace,
+		}
+		hostedControlPlaneReconciler := hostedcontrolplane.NewHostedControlPlaneReconciler(mgr, rhobsConfig, dynatraceConfig, healthcheckConfig)
 		if err = hostedControlPlaneReconciler.SetupWithManager(mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "HostedControlPlane")
 			os.Exit(1)
@@ -319,6 +543,26 @@ type OperatorConfig struct {
 	OIDCClientID     string
 	OIDCClientSecret string
 	OIDCIssuerURL    string
+	EnableDynatrace  string
+
+	HealthcheckSuccesses     string
+	HealthcheckInterval      string
+	HealthcheckMaxClusterAge string
+	HealthcheckEndpoint      string
+	HealthcheckTimeout       string
+
+	SLOQueryURL             string
+	SLOQueryBearerTokenFile string
+	SLOQueryCAFile          string
+	SLOWindow               string
+	SLOResyncInterval       string
+
+	DashboardType             string
+	DashboardInstanceSelector string
+
+	AlertRunbookURL  string
+	AlertSummary     string
+	AlertDescription string
 }
 
 // getConfigFromConfigMap reads configuration from the route-monitor-operator-config ConfigMap
@@ -355,6 +599,26 @@ func getConfigFromConfigMap() (*OperatorConfig, error) {
 		OIDCClientID:     strings.TrimSpace(configMap.Data["oidc-client-id"]),
 		OIDCClientSecret: strings.TrimSpace(configMap.Data["oidc-client-secret"]),
 		OIDCIssuerURL:    strings.TrimSpace(configMap.Data["oidc-issuer-url"]),
+		EnableDynatrace:  strings.TrimSpace(configMap.Data["enable-dynatrace"]),
+
+		HealthcheckSuccesses:     strings.TrimSpace(configMap.Data["hcp-healthcheck-successes"]),
+		HealthcheckInterval:      strings.TrimSpace(configMap.Data["hcp-healthcheck-interval"]),
+		HealthcheckMaxClusterAge: strings.TrimSpace(configMap.Data["hcp-healthcheck-max-cluster-age"]),
+		HealthcheckEndpoint:      strings.TrimSpace(configMap.Data["hcp-healthcheck-endpoint"]),
+		HealthcheckTimeout:       strings.TrimSpace(configMap.Data["hcp-healthcheck-timeout"]),
+
+		SLOQueryURL:             strings.TrimSpace(configMap.Data["slo-query-url"]),
+		SLOQueryBearerTokenFile: strings.TrimSpace(configMap.Data["slo-query-bearer-token-file"]),
+		SLOQueryCAFile:          strings.TrimSpace(configMap.Data["slo-query-ca-file"]),
+		SLOWindow:               strings.TrimSpace(configMap.Data["slo-window"]),
+		SLOResyncInterval:       strings.TrimSpace(configMap.Data["slo-resync-interval"]),
+
+		DashboardType:             strings.TrimSpace(configMap.Data["dashboard-type"]),
+		DashboardInstanceSelector: strings.TrimSpace(configMap.Data["dashboard-grafana-instance-selector"]),
+
+		AlertRunbookURL:  strings.TrimSpace(configMap.Data["alert-runbook-url"]),
+		AlertSummary:     strings.TrimSpace(configMap.Data["alert-summary"]),
+		AlertDescription: strings.TrimSpace(configMap.Data["alert-description"]),
 	}
 
 	// Log detailed information about what was found in the ConfigMap
@@ -391,6 +655,36 @@ func getConfigFromConfigMap() (*OperatorConfig, error) {
 		missingParams = append(missingParams, "oidc-issuer-url")
 	}
 
+	if cfg.EnableDynatrace != "" {
+		foundParams = append(foundParams, "enable-dynatrace")
+	} else {
+		missingParams = append(missingParams, "enable-dynatrace")
+	}
+
+	for _, param := range [][2]string{
+		{"hcp-healthcheck-successes", cfg.HealthcheckSuccesses},
+		{"hcp-healthcheck-interval", cfg.HealthcheckInterval},
+		{"hcp-healthcheck-max-cluster-age", cfg.HealthcheckMaxClusterAge},
+		{"hcp-healthcheck-endpoint", cfg.HealthcheckEndpoint},
+		{"hcp-healthcheck-timeout", cfg.HealthcheckTimeout},
+		{"slo-query-url", cfg.SLOQueryURL},
+		{"slo-query-bearer-token-file", cfg.SLOQueryBearerTokenFile},
+		{"slo-query-ca-file", cfg.SLOQueryCAFile},
+		{"slo-window", cfg.SLOWindow},
+		{"slo-resync-interval", cfg.SLOResyncInterval},
+		{"dashboard-type", cfg.DashboardType},
+		{"dashboard-grafana-instance-selector", cfg.DashboardInstanceSelector},
+		{"alert-runbook-url", cfg.AlertRunbookURL},
+		{"alert-summary", cfg.AlertSummary},
+		{"alert-description", cfg.AlertDescription},
+	} {
+		if param[1] != "" {
+			foundParams = append(foundParams, param[0])
+		} else {
+			missingParams = append(missingParams, param[0])
+		}
+	}
+
 	setupLog.Info("ConfigMap found and processed",
 		"configmap", configMapName,
 		"namespace", config.OperatorNamespace,
@@ -0,0 +1,164 @@
+package alert
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	prometheus "github.com/prometheus/common/model"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/servicemonitor"
+)
+
+const (
+	// leftDelim and rightDelim delimit the operator's template actions, leaving Prometheus' {{ }} templates such
+	// as {{ $value }} in the rendered annotations for Prometheus to expand
+	leftDelim  = "[["
+	rightDelim = "]]"
+
+	RunbookURLAnnotation  = "runbook_url"
+	SummaryAnnotation     = "summary"
+	DescriptionAnnotation = "description"
+	MessageAnnotation     = "message"
+)
+
+// Defaults are the operator-wide annotations of the alerts generated for all monitors.
+// They are Go templates delimited by [[ ]] like the values of a monitor's spec.alerting, which override them
+type Defaults struct {
+	RunbookURL  string
+	Summary     string
+	Description string
+}
+
+// Apply returns the monitor's alerting spec with the default annotations it does not override
+func (d Defaults) Apply(alerting v1alpha1.AlertingSpec) v1alpha1.AlertingSpec {
+	annotations := map[string]string{}
+	for name, value := range map[string]string{
+		RunbookURLAnnotation:  d.RunbookURL,
+		SummaryAnnotation:     d.Summary,
+		DescriptionAnnotation: d.Description,
+	} {
+		if value != "" {
+			annotations[name] = value
+		}
+	}
+	for name, value := range alerting.Annotations {
+		annotations[name] = value
+	}
+	alerting.Annotations = annotations
+	return alerting
+}
+
+// Validate returns an error if one of the default annotations is not a valid template
+func (d Defaults) Validate() error {
+	_, err := renderTemplates(d.Apply(v1alpha1.AlertingSpec{}).Annotations, templateData{})
+	return err
+}
+
+// templateData is passed to the templates of alert labels and annotations
+type templateData struct {
+	URL       string
+	Namespace string
+	Name      string
+}
+
+// reservedLabels are set on the alerts by the operator and cannot be overridden
+var reservedLabels = map[string]bool{
+	servicemonitor.UrlLabelName: true,
+	"namespace":                 true,
+	"severity":                  true,
+	"long_window":               true,
+	"short_window":              true,
+}
+
+// defaultGuardExpressions are ANDed into the alerts of the monitors with the given name which set no guard expressions:
+// the console only alerts while it is enabled and served on the monitored route
+var defaultGuardExpressions = map[string][]string{
+	"console": {`count(console_url{url="[[ .URL | trimSuffix "/health" ]]"} == 1) > 0`},
+}
+
+// templateFuncs are available to the templates in addition to Go's builtin functions
+var templateFuncs = template.FuncMap{
+	// trimSuffix takes its arguments in pipeline order, e.g. [[ .URL | trimSuffix "/health" ]]
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+}
+
+// renderedAlerting holds the rendered templates of a monitor's spec.alerting
+type renderedAlerting struct {
+	labels           map[string]string
+	annotations      map[string]string
+	guardExpressions []string
+}
+
+// renderAlerting renders the templates of the labels, annotations and guard expressions of the monitor's alerts
+func renderAlerting(alerting v1alpha1.AlertingSpec, url string, namespacedName types.NamespacedName) (renderedAlerting, error) {
+	data := templateData{URL: url, Namespace: namespacedName.Namespace, Name: namespacedName.Name}
+	for name := range alerting.Labels {
+		if reservedLabels[name] {
+			return renderedAlerting{}, fmt.Errorf("alert label %q is set by the operator and cannot be overridden", name)
+		}
+		if !prometheus.LabelName(name).IsValid() {
+			return renderedAlerting{}, fmt.Errorf("invalid alert label name %q", name)
+		}
+	}
+
+	rendered := renderedAlerting{}
+	var err error
+	if rendered.labels, err = renderTemplates(alerting.Labels, data); err != nil {
+		return renderedAlerting{}, fmt.Errorf("invalid alert labels: %w", err)
+	}
+	if rendered.annotations, err = renderTemplates(alerting.Annotations, data); err != nil {
+		return renderedAlerting{}, fmt.Errorf("invalid alert annotations: %w", err)
+	}
+	guardExpressions := alerting.GuardExpressions
+	if len(guardExpressions) == 0 {
+		guardExpressions = defaultGuardExpressions[namespacedName.Name]
+	}
+	for i, guard := range guardExpressions {
+		expr, err := renderTemplate(fmt.Sprintf("guardExpressions[%d]", i), guard, data)
+		if err != nil {
+			return renderedAlerting{}, fmt.Errorf("invalid alert guard expression: %w", err)
+		}
+		if strings.TrimSpace(expr) == "" {
+			return renderedAlerting{}, fmt.Errorf("invalid alert guard expression: guardExpressions[%d] is empty", i)
+		}
+		rendered.guardExpressions = append(rendered.guardExpressions, expr)
+	}
+	return rendered, nil
+}
+
+// renderTemplates renders each value of a map as Go template delimited by [[ ]], in the order of the keys for deterministic errors
+func renderTemplates(values map[string]string, data templateData) (map[string]string, error) {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	rendered := make(map[string]string, len(values))
+	for _, name := range names {
+		value, err := renderTemplate(name, values[name], data)
+		if err != nil {
+			return nil, err
+		}
+		rendered[name] = value
+	}
+	return rendered, nil
+}
+
+func renderTemplate(name, text string, data templateData) (string, error) {
+	tmpl, err := template.New(name).Delims(leftDelim, rightDelim).Option("missingkey=error").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	out := bytes.Buffer{}
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return out.String(), nil
+}
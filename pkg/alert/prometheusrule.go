@@ -4,13 +4,14 @@ import (
 	"context"
 	"fmt"
 	"strconv"
-	"strings"
 	"time"
 
 	prometheus "github.com/prometheus/common/model"
 
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/openshift/route-monitor-operator/api/v1alpha1"
@@ -20,48 +21,84 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 type PrometheusRule struct {
-	Client   client.Client
+	Client client.Client
+	// Reader reads RHOBS PrometheusRules bypassing the cache, which only holds them on management clusters
+	Reader   client.Reader
 	Ctx      context.Context
 	Comparer util.ResourceComparerInterface
 }
 
-func NewPrometheusRule(ctx context.Context, c client.Client) *PrometheusRule {
+func NewPrometheusRule(ctx context.Context, c client.Client, reader client.Reader) *PrometheusRule {
 	return &PrometheusRule{
 		Client:   c,
+		Reader:   reader,
 		Ctx:      ctx,
 		Comparer: &util.ResourceComparer{},
 	}
 }
 
-// Creates or Updates PrometheusRule Deployment according to the template
-func (u *PrometheusRule) UpdatePrometheusRuleDeployment(template monitoringv1.PrometheusRule) error {
+// Creates or Updates PrometheusRule Deployment according to the template, reporting which operation was performed.
+// The PrometheusRules of HCP monitors are created as RHOBS PrometheusRules, next to their RHOBS ServiceMonitors
+func (u *PrometheusRule) UpdatePrometheusRuleDeployment(template monitoringv1.PrometheusRule, isHCPMonitor bool) (controllerutil.OperationResult, error) {
+	if isHCPMonitor {
+		return u.HypershiftUpdatePrometheusRuleDeployment(HyperShiftTemplateForPrometheusRuleResource(template))
+	}
 	namespacedName := types.NamespacedName{Name: template.Name, Namespace: template.Namespace}
 	deployedPrometheusRule := &monitoringv1.PrometheusRule{}
 	err := u.Client.Get(u.Ctx, namespacedName, deployedPrometheusRule)
 	if err != nil {
 		// No similar Prometheus Rule exists
 		if !k8serrors.IsNotFound(err) {
-			return err
+			return controllerutil.OperationResultNone, err
 		}
-		return u.Client.Create(u.Ctx, &template)
+		return controllerutil.OperationResultCreated, u.Client.Create(u.Ctx, &template)
 	}
 	if !u.Comparer.DeepEqual(template.Spec, deployedPrometheusRule.Spec) {
 		// Update existing PrometheuesRule for the case that the template changed
 		deployedPrometheusRule.Spec = template.Spec
-		return u.Client.Update(u.Ctx, deployedPrometheusRule)
+		return controllerutil.OperationResultUpdated, u.Client.Update(u.Ctx, deployedPrometheusRule)
 	}
-	return nil
+	return controllerutil.OperationResultNone, nil
 }
 
-func (u *PrometheusRule) DeletePrometheusRuleDeployment(prometheusRuleRef v1alpha1.NamespacedName) error {
+// Creates or Updates the RHOBS PrometheusRule Deployment according to the template, reporting which operation was performed
+func (u *PrometheusRule) HypershiftUpdatePrometheusRuleDeployment(template rhobsv1.PrometheusRule) (controllerutil.OperationResult, error) {
+	namespacedName := types.NamespacedName{Name: template.Name, Namespace: template.Namespace}
+	deployedPrometheusRule := &rhobsv1.PrometheusRule{}
+	err := u.Reader.Get(u.Ctx, namespacedName, deployedPrometheusRule)
+	if err != nil {
+		// No similar Prometheus Rule exists
+		if !k8serrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+		return controllerutil.OperationResultCreated, u.Client.Create(u.Ctx, &template)
+	}
+	if !u.Comparer.DeepEqual(template.Spec, deployedPrometheusRule.Spec) {
+		// Update existing PrometheuesRule for the case that the template changed
+		deployedPrometheusRule.Spec = template.Spec
+		return controllerutil.OperationResultUpdated, u.Client.Update(u.Ctx, deployedPrometheusRule)
+	}
+	return controllerutil.OperationResultNone, nil
+}
+
+func (u *PrometheusRule) DeletePrometheusRuleDeployment(prometheusRuleRef v1alpha1.NamespacedName, isHCPMonitor bool) error {
 	// nothing to delete, stopping early
 	if prometheusRuleRef == (v1alpha1.NamespacedName{}) {
 		return nil
 	}
 	namespacedName := types.NamespacedName{Name: prometheusRuleRef.Name, Namespace: prometheusRuleRef.Namespace}
+	if isHCPMonitor {
+		// RHOBS PrometheusRules are deleted without a lookup, as they are only cached on management clusters
+		resource := &rhobsv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Name: namespacedName.Name, Namespace: namespacedName.Namespace}}
+		if err := u.Client.Delete(u.Ctx, resource); err != nil && !k8serrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return err
+		}
+		return nil
+	}
 	resource := &monitoringv1.PrometheusRule{}
 	// Does the resource already exist?
 	err := u.Client.Get(u.Ctx, namespacedName, resource)
@@ -84,6 +121,60 @@ type multiWindowMultiBurnAlertRule struct {
 	burnRate    string
 }
 
+// alertRules are the multiwindow, multi-burn-rate alerts created for each monitor
+var alertRules = []multiWindowMultiBurnAlertRule{
+	{
+		duration:    "2m",
+		severity:    "critical",
+		longWindow:  "1h",
+		shortWindow: "5m",
+		burnRate:    "14.40",
+	},
+	{
+		duration:    "15m",
+		severity:    "critical",
+		longWindow:  "6h",
+		shortWindow: "30m",
+		burnRate:    "6",
+	},
+	{
+		duration:    "1h",
+		severity:    "warning",
+		longWindow:  "1d",
+		shortWindow: "2h",
+		burnRate:    "3",
+	},
+	{
+		duration:    "3h",
+		severity:    "warning",
+		longWindow:  "3d",
+		shortWindow: "6h",
+		burnRate:    "1",
+	},
+}
+
+// BurnRateWindow describes the windows and burn rate threshold of one of the ErrorBudgetBurn alerts
+type BurnRateWindow struct {
+	Severity    string
+	LongWindow  string
+	ShortWindow string
+	BurnRate    string
+}
+
+// BurnRateWindows returns the windows the ErrorBudgetBurn alerts of each monitor are evaluated over
+func BurnRateWindows() []BurnRateWindow {
+	windows := make([]BurnRateWindow, 0, len(alertRules))
+	for _, rule := range alertRules {
+		windows = append(windows, BurnRateWindow{
+			Severity:    rule.severity,
+			LongWindow:  rule.longWindow,
+			ShortWindow: rule.shortWindow,
+			BurnRate:    rule.burnRate,
+		})
+	}
+	return windows
+}
+
 func alertThreshold(windowSize, percent, label, burnRate string) string {
 
 	rule := "1-(sum(sum_over_time(probe_success{" + label + "}[" + windowSize + "]))" +
@@ -106,8 +197,9 @@ func sufficientProbes(windowSize, label string) string {
 	return rule
 }
 
-// render creates a monitoring rule for the defined multiwindow multi-burn rate alert
-func (r *multiWindowMultiBurnAlertRule) render(url string, percent string, namespacedName types.NamespacedName) monitoringv1.Rule {
+// render creates a monitoring rule for the defined multiwindow multi-burn rate alert, adding the rendered
+// labels, annotations and guard expressions of the monitor's spec.alerting
+func (r *multiWindowMultiBurnAlertRule) render(url string, percent string, namespacedName types.NamespacedName, alerting renderedAlerting) monitoringv1.Rule {
 	labelSelector := fmt.Sprintf(`%s="%s"`, servicemonitor.UrlLabelName, url)
 
 	alertString := "" +
@@ -119,70 +211,151 @@ func (r *multiWindowMultiBurnAlertRule) render(url string, percent string, names
 		" and " +
 		sufficientProbes(r.longWindow, labelSelector)
 
-	if namespacedName.Name == "console" {
-		defaultConsoleURL := strings.TrimSuffix(url, "/health")
-		defaultConsoleIndicator := fmt.Sprintf("count(console_url{url=\"%s\"} == 1) > 0", defaultConsoleURL)
-		alertString = alertString + "\nand\n" + defaultConsoleIndicator
+	// the alert only fires while all guard expressions return a result
+	for _, guard := range alerting.guardExpressions {
+		alertString = alertString + "\nand\n" + guard
+	}
+
+	ruleAnnotations := map[string]string{
+		MessageAnnotation: fmt.Sprintf("High error budget burn for %s (current value: {{ $value }})", url),
+	}
+	for name, value := range alerting.annotations {
+		ruleAnnotations[name] = value
 	}
 
 	return monitoringv1.Rule{
-		Alert:  namespacedName.Name + "-ErrorBudgetBurn",
-		Expr:   intstr.FromString(alertString),
-		Labels: r.renderLabels(url, namespacedName.Namespace),
-		Annotations: map[string]string{
-			"message": fmt.Sprintf("High error budget burn for %s (current value: {{ $value }})", url),
-		},
-		For: monitoringv1.Duration(r.duration),
+		Alert:       namespacedName.Name + "-ErrorBudgetBurn",
+		Expr:        intstr.FromString(alertString),
+		Labels:      r.renderLabels(url, namespacedName.Namespace, alerting.labels),
+		Annotations: ruleAnnotations,
+		For:         monitoringv1.Duration(r.duration),
 	}
 }
 
-func (r *multiWindowMultiBurnAlertRule) renderLabels(url, namespace string) map[string]string {
-	return map[string]string{
+func (r *multiWindowMultiBurnAlertRule) renderLabels(url, namespace string, labels map[string]string) map[string]string {
+	ruleLabels := map[string]string{
 		servicemonitor.UrlLabelName: url,
 		"namespace":                 namespace,
 		"severity":                  r.severity,
 		"long_window":               r.longWindow,
 		"short_window":              r.shortWindow,
 	}
+	for name, value := range labels {
+		ruleLabels[name] = value
+	}
+	return ruleLabels
 }
 
-// TemplateForPrometheusRuleResource returns a PrometheusRule
-func TemplateForPrometheusRuleResource(url, percent string, namespacedName types.NamespacedName) monitoringv1.PrometheusRule {
+// certificateExpiryDuration is the duration the certificate expiry alerts need to be pending before firing,
+// so that a single probe of an endpoint serving an outdated certificate during a rollout does not page
+const certificateExpiryDuration = "15m"
+
+// certificateExpiryRules returns the alerts firing when the earliest certificate served at the URL expires within the
+// thresholds of the monitor's spec.certificateExpiry, or an error if the thresholds are invalid
+func certificateExpiryRules(url string, namespacedName types.NamespacedName, certificateExpiry v1alpha1.CertificateExpirySpec, alerting renderedAlerting) ([]monitoringv1.Rule, error) {
+	if certificateExpiry.WarningDays < 0 || certificateExpiry.CriticalDays < 0 {
+		return nil, fmt.Errorf("certificate expiry thresholds must not be negative")
+	}
+	if certificateExpiry.WarningDays > 0 && certificateExpiry.CriticalDays > 0 && certificateExpiry.CriticalDays >= certificateExpiry.WarningDays {
+		return nil, fmt.Errorf("certificate expiry criticalDays must be lower than warningDays")
+	}
 
+	labelSelector := fmt.Sprintf(`%s="%s"`, servicemonitor.UrlLabelName, url)
 	rules := []monitoringv1.Rule{}
-	alertRules := []multiWindowMultiBurnAlertRule{
-		{
-			duration:    "2m",
-			severity:    "critical",
-			longWindow:  "1h",
-			shortWindow: "5m",
-			burnRate:    "14.40",
-		},
-		{
-			duration:    "15m",
-			severity:    "critical",
-			longWindow:  "6h",
-			shortWindow: "30m",
-			burnRate:    "6",
-		},
-		{
-			duration:    "1h",
-			severity:    "warning",
-			longWindow:  "1d",
-			shortWindow: "2h",
-			burnRate:    "3",
-		},
-		{
-			duration:    "3h",
-			severity:    "warning",
-			longWindow:  "3d",
-			shortWindow: "6h",
-			burnRate:    "1",
-		},
+	for _, threshold := range []struct {
+		severity string
+		days     int32
+	}{
+		{severity: "warning", days: certificateExpiry.WarningDays},
+		{severity: "critical", days: certificateExpiry.CriticalDays},
+	} {
+		if threshold.days == 0 {
+			continue
+		}
+
+		ruleLabels := map[string]string{
+			servicemonitor.UrlLabelName: url,
+			"namespace":                 namespacedName.Namespace,
+			"severity":                  threshold.severity,
+		}
+		for name, value := range alerting.labels {
+			ruleLabels[name] = value
+		}
+		ruleAnnotations := map[string]string{
+			MessageAnnotation: fmt.Sprintf("The TLS certificate served at %s expires in {{ $value | humanize }} days", url),
+		}
+		for name, value := range alerting.annotations {
+			ruleAnnotations[name] = value
+		}
+
+		rules = append(rules, monitoringv1.Rule{
+			Alert:       namespacedName.Name + "-CertificateExpiry",
+			Expr:        intstr.FromString(fmt.Sprintf("(probe_ssl_earliest_cert_expiry{%s} - time()) / 86400 < %d", labelSelector, threshold.days)),
+			Labels:      ruleLabels,
+			Annotations: ruleAnnotations,
+			For:         monitoringv1.Duration(certificateExpiryDuration),
+		})
+	}
+	return rules, nil
+}
+
+// HyperShiftTemplateForPrometheusRuleResource returns the RHOBS PrometheusRule equivalent to the template
+func HyperShiftTemplateForPrometheusRuleResource(template monitoringv1.PrometheusRule) rhobsv1.PrometheusRule {
+	groups := []rhobsv1.RuleGroup{}
+	for _, group := range template.Spec.Groups {
+		rules := []rhobsv1.Rule{}
+		for _, rule := range group.Rules {
+			rules = append(rules, rhobsv1.Rule{
+				Record:      rule.Record,
+				Alert:       rule.Alert,
+				Expr:        rule.Expr,
+				For:         string(rule.For),
+				Labels:      rule.Labels,
+				Annotations: rule.Annotations,
+			})
+		}
+		groups = append(groups, rhobsv1.RuleGroup{
+			Name:                    group.Name,
+			Interval:                string(group.Interval),
+			Rules:                   rules,
+			PartialResponseStrategy: group.PartialResponseStrategy,
+		})
+	}
+	return rhobsv1.PrometheusRule{
+		ObjectMeta: *template.ObjectMeta.DeepCopy(),
+		Spec:       rhobsv1.PrometheusRuleSpec{Groups: groups},
+	}
+}
+
+// TemplateForPrometheusRuleResource returns a PrometheusRule, or an error if the monitor's alerting spec is invalid.
+// The certificate expiry alerts are only added for a non-nil certificateExpiry
+func TemplateForPrometheusRuleResource(url, percent string, namespacedName types.NamespacedName, alerting v1alpha1.AlertingSpec, certificateExpiry *v1alpha1.CertificateExpirySpec) (monitoringv1.PrometheusRule, error) {
+	rendered, err := renderAlerting(alerting, url, namespacedName)
+	if err != nil {
+		return monitoringv1.PrometheusRule{}, err
 	}
 
+	rules := []monitoringv1.Rule{}
 	for _, alertrule := range alertRules { // Create all the alerts
-		rules = append(rules, alertrule.render(url, percent, namespacedName))
+		rules = append(rules, alertrule.render(url, percent, namespacedName, rendered))
+	}
+	groups := []monitoringv1.RuleGroup{
+		{
+			Name:  "SLOs-probe",
+			Rules: rules,
+		},
+	}
+	if certificateExpiry != nil {
+		expiryRules, err := certificateExpiryRules(url, namespacedName, *certificateExpiry, rendered)
+		if err != nil {
+			return monitoringv1.PrometheusRule{}, err
+		}
+		if len(expiryRules) > 0 {
+			groups = append(groups, monitoringv1.RuleGroup{
+				Name:  "certificate-expiry-probe",
+				Rules: expiryRules,
+			})
+		}
 	}
 
 	resource := monitoringv1.PrometheusRule{
@@ -191,13 +364,8 @@ func TemplateForPrometheusRuleResource(url, percent string, namespacedName types
 			Namespace: namespacedName.Namespace,
 		},
 		Spec: monitoringv1.PrometheusRuleSpec{
-			Groups: []monitoringv1.RuleGroup{
-				{
-					Name:  "SLOs-probe",
-					Rules: rules,
-				},
-			},
+			Groups: groups,
 		},
 	}
-	return resource
+	return resource, nil
 }
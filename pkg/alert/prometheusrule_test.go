@@ -15,6 +15,12 @@ import (
 	utilmock "github.com/openshift/route-monitor-operator/pkg/util/test/generated/mocks/reconcile"
 	testhelper "github.com/openshift/route-monitor-operator/pkg/util/test/helper"
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 type ResourceComparerMockHelper struct {
@@ -38,6 +44,7 @@ var _ = Describe("CR Deployment Handling", func() {
 		prometheusRule    monitoringv1.PrometheusRule
 		pr                alert.PrometheusRule
 		err               error
+		result            controllerutil.OperationResult
 	)
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
@@ -55,6 +62,7 @@ var _ = Describe("CR Deployment Handling", func() {
 
 		pr = alert.PrometheusRule{
 			Client:   mockClient,
+			Reader:   mockClient,
 			Ctx:      context.Background(),
 			Comparer: mockResourceComparer,
 		}
@@ -90,7 +98,7 @@ var _ = Describe("CR Deployment Handling", func() {
 			get.CalledTimes = 1
 		})
 		JustBeforeEach(func() {
-			err = pr.UpdatePrometheusRuleDeployment(prometheusRule)
+			result, err = pr.UpdatePrometheusRuleDeployment(prometheusRule, false)
 		})
 		When("the Client failed to fetch existing deployments", func() {
 			BeforeEach(func() {
@@ -107,6 +115,7 @@ var _ = Describe("CR Deployment Handling", func() {
 			})
 			It("tryies to creates one", func() {
 				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(controllerutil.OperationResultCreated))
 			})
 			When("an error appeared during the creation", func() {
 				BeforeEach(func() {
@@ -128,6 +137,7 @@ var _ = Describe("CR Deployment Handling", func() {
 				})
 				It("updates the existing deployment", func() {
 					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(controllerutil.OperationResultUpdated))
 				})
 				When("the client failed to update the existing deployments", func() {
 					BeforeEach(func() {
@@ -144,13 +154,14 @@ var _ = Describe("CR Deployment Handling", func() {
 				})
 				It("does nothing", func() {
 					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(controllerutil.OperationResultNone))
 				})
 			})
 		})
 	})
 	Describe("DeletePrometheusRuleDeployment", func() {
 		JustBeforeEach(func() {
-			err = pr.DeletePrometheusRuleDeployment(prometheusRuleRef)
+			err = pr.DeletePrometheusRuleDeployment(prometheusRuleRef, false)
 		})
 		When("The PrometheusRuleRef is not set", func() {
 			BeforeEach(func() {
@@ -200,13 +211,276 @@ var _ = Describe("CR Deployment Handling", func() {
 		})
 	})
 
+	Describe("the monitor is an HCP monitor", func() {
+		When("its PrometheusRule is updated", func() {
+			BeforeEach(func() {
+				get.CalledTimes = 1
+				get.ErrorResponse = consterror.NotFoundErr
+				create.CalledTimes = 1
+			})
+			JustBeforeEach(func() {
+				result, err = pr.UpdatePrometheusRuleDeployment(prometheusRule, true)
+			})
+			It("creates a RHOBS PrometheusRule", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(controllerutil.OperationResultCreated))
+			})
+		})
+		When("its PrometheusRule is deleted", func() {
+			BeforeEach(func() {
+				prometheusRuleRef = v1alpha1.NamespacedName{Name: "test", Namespace: "test"}
+				delete.CalledTimes = 1
+			})
+			JustBeforeEach(func() {
+				err = pr.DeletePrometheusRuleDeployment(prometheusRuleRef, true)
+			})
+			It("deletes the RHOBS PrometheusRule without looking it up", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+			When("the RHOBS PrometheusRule doesnt exist", func() {
+				BeforeEach(func() {
+					delete.ErrorResponse = consterror.NotFoundErr
+				})
+				It("does nothing", func() {
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
+			When("the client failed to delete the RHOBS PrometheusRule", func() {
+				BeforeEach(func() {
+					delete.ErrorResponse = consterror.ErrCustomError
+				})
+				It("returns the received error", func() {
+					Expect(err).To(Equal(consterror.ErrCustomError))
+				})
+			})
+		})
+	})
+
+	Describe("HyperShiftTemplateForPrometheusRuleResource", func() {
+		It("converts every alert into a RHOBS PrometheusRule", func() {
+			name := types.NamespacedName{Name: "scott-pilgrim", Namespace: "the-world"}
+			template, err := alert.TemplateForPrometheusRuleResource("https://fake-route-url/health", "0.995", name, v1alpha1.AlertingSpec{}, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			rhobsRule := alert.HyperShiftTemplateForPrometheusRuleResource(template)
+			Expect(rhobsRule.ObjectMeta).To(Equal(template.ObjectMeta))
+			Expect(rhobsRule.Spec.Groups).To(HaveLen(len(template.Spec.Groups)))
+			for i, group := range template.Spec.Groups {
+				Expect(rhobsRule.Spec.Groups[i].Name).To(Equal(group.Name))
+				Expect(rhobsRule.Spec.Groups[i].Rules).To(HaveLen(len(group.Rules)))
+				for j, rule := range group.Rules {
+					converted := rhobsRule.Spec.Groups[i].Rules[j]
+					Expect(converted.Alert).To(Equal(rule.Alert))
+					Expect(converted.Expr).To(Equal(rule.Expr))
+					Expect(string(converted.For)).To(Equal(string(rule.For)))
+					Expect(converted.Labels).To(Equal(rule.Labels))
+					Expect(converted.Annotations).To(Equal(rule.Annotations))
+				}
+			}
+		})
+	})
+
+	Describe("TemplateForPrometheusRuleResource", func() {
+		var (
+			alerting          v1alpha1.AlertingSpec
+			certificateExpiry *v1alpha1.CertificateExpirySpec
+			name              types.NamespacedName
+		)
+		BeforeEach(func() {
+			alerting = v1alpha1.AlertingSpec{}
+			certificateExpiry = nil
+			name = types.NamespacedName{Name: "scott-pilgrim", Namespace: "the-world"}
+		})
+		JustBeforeEach(func() {
+			prometheusRule, err = alert.TemplateForPrometheusRuleResource("https://fake-route-url/health", "0.995", name, alerting, certificateExpiry)
+		})
+		When("the monitor does not customize its alerts", func() {
+			It("only sets the operator's labels and the message annotation", func() {
+				Expect(err).NotTo(HaveOccurred())
+				for _, rule := range prometheusRule.Spec.Groups[0].Rules {
+					Expect(rule.Labels).To(HaveLen(5))
+					Expect(rule.Annotations).To(HaveKey(alert.MessageAnnotation))
+					Expect(rule.Annotations).To(HaveLen(1))
+				}
+			})
+		})
+		When("the monitor sets templated labels and annotations", func() {
+			BeforeEach(func() {
+				alerting = alert.Defaults{
+					RunbookURL: "https://runbooks.example.com/[[ .Namespace ]]/[[ .Name ]]",
+					Summary:    "default summary",
+				}.Apply(v1alpha1.AlertingSpec{
+					Labels:      map[string]string{"team": "[[ .Namespace ]]-owners"},
+					Annotations: map[string]string{"summary": "[[ .URL ]] is burning its error budget", "dashboard": `{{ $value }} on {{ $labels.instance }}`},
+				})
+			})
+			It("renders them into every alert", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(prometheusRule.Spec.Groups[0].Rules).To(HaveLen(4))
+				for _, rule := range prometheusRule.Spec.Groups[0].Rules {
+					Expect(rule.Labels).To(HaveKeyWithValue("team", "the-world-owners"))
+					Expect(rule.Labels).To(HaveKey("severity"))
+					Expect(rule.Annotations).To(HaveKeyWithValue("runbook_url", "https://runbooks.example.com/the-world/scott-pilgrim"))
+					Expect(rule.Annotations).To(HaveKeyWithValue("summary", "https://fake-route-url/health is burning its error budget"))
+					Expect(rule.Annotations).To(HaveKeyWithValue("dashboard", "{{ $value }} on {{ $labels.instance }}"))
+					Expect(rule.Annotations).To(HaveKey(alert.MessageAnnotation))
+				}
+			})
+		})
+		When("the monitor is named console", func() {
+			BeforeEach(func() {
+				name.Name = "console"
+			})
+			It("ANDs the default console guard expression into every alert", func() {
+				Expect(err).NotTo(HaveOccurred())
+				for _, rule := range prometheusRule.Spec.Groups[0].Rules {
+					Expect(rule.Expr.String()).To(HaveSuffix("\nand\n" + `count(console_url{url="https://fake-route-url"} == 1) > 0`))
+				}
+			})
+			When("it sets its own guard expressions", func() {
+				BeforeEach(func() {
+					alerting.GuardExpressions = []string{`up{job="[[ .Name ]]"} == 1`}
+				})
+				It("replaces the default", func() {
+					Expect(err).NotTo(HaveOccurred())
+					for _, rule := range prometheusRule.Spec.Groups[0].Rules {
+						Expect(rule.Expr.String()).NotTo(ContainSubstring("console_url"))
+						Expect(rule.Expr.String()).To(HaveSuffix("\nand\n" + `up{job="console"} == 1`))
+					}
+				})
+			})
+		})
+		When("the monitor sets guard expressions", func() {
+			BeforeEach(func() {
+				alerting.GuardExpressions = []string{
+					`count(console_url{url="[[ .URL | trimSuffix "/health" ]]"} == 1) > 0`,
+					`up{job="[[ .Name ]]"} == 1`,
+				}
+			})
+			It("ANDs them into every alert", func() {
+				Expect(err).NotTo(HaveOccurred())
+				for _, rule := range prometheusRule.Spec.Groups[0].Rules {
+					Expect(rule.Expr.String()).To(HaveSuffix("\nand\n" + `count(console_url{url="https://fake-route-url"} == 1) > 0` + "\nand\n" + `up{job="scott-pilgrim"} == 1`))
+				}
+			})
+		})
+		When("the monitor sets an empty guard expression", func() {
+			BeforeEach(func() {
+				alerting.GuardExpressions = []string{" "}
+			})
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("the monitor overrides a label set by the operator", func() {
+			BeforeEach(func() {
+				alerting.Labels = map[string]string{"severity": "info"}
+			})
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("the monitor sets an invalid label name", func() {
+			BeforeEach(func() {
+				alerting.Labels = map[string]string{"owning-team": "sre"}
+			})
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("the monitor sets an invalid template", func() {
+			BeforeEach(func() {
+				alerting.Annotations = map[string]string{"summary": "[[ .Cluster ]]"}
+			})
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		When("the monitor does not set certificate expiry thresholds", func() {
+			It("only creates the SLO alerts", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(prometheusRule.Spec.Groups).To(HaveLen(1))
+			})
+		})
+		When("the monitor sets certificate expiry thresholds", func() {
+			BeforeEach(func() {
+				certificateExpiry = &v1alpha1.CertificateExpirySpec{WarningDays: 30, CriticalDays: 7}
+				alerting.Labels = map[string]string{"team": "sre"}
+			})
+			It("adds a warning and a critical alert on the earliest certificate expiry", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(prometheusRule.Spec.Groups).To(HaveLen(2))
+				rules := prometheusRule.Spec.Groups[1].Rules
+				Expect(rules).To(HaveLen(2))
+				Expect(rules[0].Alert).To(Equal("scott-pilgrim-CertificateExpiry"))
+				Expect(rules[0].Labels).To(HaveKeyWithValue("severity", "warning"))
+				Expect(rules[0].Labels).To(HaveKeyWithValue("team", "sre"))
+				Expect(rules[0].Expr.String()).To(Equal(`(probe_ssl_earliest_cert_expiry{probe_url="https://fake-route-url/health"} - time()) / 86400 < 30`))
+				Expect(rules[1].Labels).To(HaveKeyWithValue("severity", "critical"))
+				Expect(rules[1].Expr.String()).To(HaveSuffix("< 7"))
+			})
+		})
+		When("the monitor disables the warning certificate expiry alert", func() {
+			BeforeEach(func() {
+				certificateExpiry = &v1alpha1.CertificateExpirySpec{CriticalDays: 7}
+			})
+			It("only adds the critical alert", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(prometheusRule.Spec.Groups[1].Rules).To(HaveLen(1))
+				Expect(prometheusRule.Spec.Groups[1].Rules[0].Labels).To(HaveKeyWithValue("severity", "critical"))
+			})
+		})
+		When("the critical certificate expiry threshold is not lower than the warning one", func() {
+			BeforeEach(func() {
+				certificateExpiry = &v1alpha1.CertificateExpirySpec{WarningDays: 7, CriticalDays: 14}
+			})
+			It("returns an error", func() {
+				Expect(err).To(HaveOccurred())
+			})
+		})
+	})
+
+	Describe("Defaults", func() {
+		It("rejects invalid templates", func() {
+			Expect(alert.Defaults{Summary: "[[ .URL ]] is down"}.Validate()).To(Succeed())
+			Expect(alert.Defaults{Description: "{{ $value }} on {{ $labels.instance }}"}.Validate()).To(Succeed())
+			Expect(alert.Defaults{Description: "[[ $value ]]"}.Validate()).NotTo(Succeed())
+		})
+	})
+
+	Describe("HypershiftUpdatePrometheusRuleDeployment", func() {
+		var rhobsPrometheusRule rhobsv1.PrometheusRule
+		BeforeEach(func() {
+			rhobsPrometheusRule = rhobsv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "ocm-production-test"}}
+		})
+		JustBeforeEach(func() {
+			result, err = pr.HypershiftUpdatePrometheusRuleDeployment(rhobsPrometheusRule)
+		})
+		When("RHOBS PrometheusRules are not cached, as on clusters which are no management cluster", func() {
+			BeforeEach(func() {
+				scheme := runtime.NewScheme()
+				Expect(rhobsv1.AddToScheme(scheme)).To(Succeed())
+				deployed := rhobsPrometheusRule.DeepCopy()
+				deployed.Spec.Groups = []rhobsv1.RuleGroup{{Name: "outdated"}}
+				pr.Reader = fake.NewClientBuilder().WithScheme(scheme).WithObjects(deployed).Build()
+				deepEqual.CalledTimes = 1
+				update.CalledTimes = 1
+			})
+			It("reads the deployed PrometheusRule without the cached client", func() {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(controllerutil.OperationResultUpdated))
+			})
+		})
+	})
+
 	Describe("NewPrometheusRule", func() {
 		It("should create a PrometheusRule with correct properties", func() {
 			client := mockClient
 			ctx := context.Background()
-			result := alert.NewPrometheusRule(ctx, client)
+			result := alert.NewPrometheusRule(ctx, client, client)
 
 			Expect(result.Client).To(Equal(client))
+			Expect(result.Reader).To(Equal(client))
 			Expect(result.Ctx).To(Equal(ctx))
 			Expect(result.Comparer).NotTo(BeNil())
 		})
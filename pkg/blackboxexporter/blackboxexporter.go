@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"reflect"
 
+	configv1 "github.com/openshift/api/config/v1"
 	"github.com/openshift/route-monitor-operator/api/v1alpha1"
 	"github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
 	"github.com/openshift/route-monitor-operator/pkg/util"
@@ -18,6 +19,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -28,42 +30,155 @@ type BlackBoxExporter struct {
 	Ctx            context.Context
 	Image          string
 	NamespacedName types.NamespacedName
+	// ProbeExporter defines the placement and egress of the blackbox exporter and owns its resources.
+	// It is nil for the operator's blackbox exporter
+	ProbeExporter *v1alpha1.ProbeExporter
+	// Monitors lists the monitors selecting the blackbox exporter by the ExporterRefIndex. It defaults to Client
+	Monitors client.Reader
+	// References reads the Secrets and ConfigMaps referenced by the probe settings of the monitors, which can reside
+	// in namespaces outside of the manager's cache. It defaults to Client
+	References client.Reader
+
+	// modules, credentials and the usage of the injected CA bundles are loaded by LoadMonitorModules
+	modules             map[string]module
+	credentials         map[string][]byte
+	usesServiceCA       bool
+	usesTrustedCABundle bool
+	// proxy is loaded by LoadClusterProxy
+	proxy configv1.ProxyStatus
 }
 
 func New(client client.Client, log logr.Logger, ctx context.Context, blackBoxImage string, blackBoxExporterNamespace string) *BlackBoxExporter {
 	blackboxNamespacedName := types.NamespacedName{Name: blackboxexporter.BlackBoxExporterName, Namespace: blackBoxExporterNamespace}
-	return &BlackBoxExporter{client, log, ctx, blackBoxImage, blackboxNamespacedName}
+	return &BlackBoxExporter{Client: client, Log: log, Ctx: ctx, Image: blackBoxImage, NamespacedName: blackboxNamespacedName}
+}
+
+// NewForProbeExporter returns the BlackBoxExporter deployed for a ProbeExporter
+func NewForProbeExporter(client client.Client, log logr.Logger, ctx context.Context, blackBoxImage string, probeExporter *v1alpha1.ProbeExporter) *BlackBoxExporter {
+	blackboxNamespacedName := types.NamespacedName{Name: blackboxexporter.ProbeExporterResourceName(probeExporter.Name), Namespace: probeExporter.Spec.Namespace}
+	return &BlackBoxExporter{Client: client, Log: log, Ctx: ctx, Image: blackBoxImage, NamespacedName: blackboxNamespacedName, ProbeExporter: probeExporter}
 }
 
 func (b *BlackBoxExporter) GetBlackBoxExporterNamespace() string {
 	return b.NamespacedName.Namespace
 }
 
-func (b *BlackBoxExporter) ShouldDeleteBlackBoxExporterResources() (blackboxexporter.ShouldDeleteBlackBoxExporter, error) {
-	objectsDependingOnExporter := []metav1.Object{}
+// GetExporterService returns the Service of the blackbox exporter selected by a monitor's exporterRef.
+// For an empty exporterRef, this is the operator's blackbox exporter
+func (b *BlackBoxExporter) GetExporterService(exporterRef string) (types.NamespacedName, error) {
+	if exporterRef == "" {
+		return b.NamespacedName, nil
+	}
+	probeExporter := v1alpha1.ProbeExporter{}
+	if err := b.Client.Get(b.Ctx, types.NamespacedName{Name: exporterRef}, &probeExporter); err != nil {
+		if k8serrors.IsNotFound(err) {
+			return types.NamespacedName{}, fmt.Errorf("ProbeExporter %q referenced by exporterRef does not exist", exporterRef)
+		}
+		return types.NamespacedName{}, err
+	}
+	return types.NamespacedName{Name: blackboxexporter.ProbeExporterResourceName(probeExporter.Name), Namespace: probeExporter.Spec.Namespace}, nil
+}
 
-	routeMonitors := &v1alpha1.RouteMonitorList{}
-	if err := b.Client.List(b.Ctx, routeMonitors); err != nil {
-		return blackboxexporter.KeepBlackBoxExporter, err
+func (b *BlackBoxExporter) monitorReader() client.Reader {
+	if b.Monitors != nil {
+		return b.Monitors
 	}
-	for i := range routeMonitors.Items {
-		objectsDependingOnExporter = append(objectsDependingOnExporter, &routeMonitors.Items[i])
+	return b.Client
+}
+
+func (b *BlackBoxExporter) referenceReader() client.Reader {
+	if b.References != nil {
+		return b.References
 	}
+	return b.Client
+}
 
-	clusterUrlMonitors := &v1alpha1.ClusterUrlMonitorList{}
-	if err := b.Client.List(b.Ctx, clusterUrlMonitors); err != nil {
-		return blackboxexporter.KeepBlackBoxExporter, err
+// LoadClusterProxy loads the cluster-wide Proxy, which the blackbox exporter probes through
+func (b *BlackBoxExporter) LoadClusterProxy() error {
+	proxy, err := util.GetClusterProxy(b.Ctx, b.Client)
+	if err != nil {
+		return fmt.Errorf("failed to get the cluster-wide proxy: %w", err)
+	}
+	b.proxy = proxy
+	return nil
+}
+
+// labels returns the labels of the blackbox exporter's resources
+func (b *BlackBoxExporter) labels() map[string]string {
+	labels := blackboxexporter.GenerateLabelsForExporter(b.NamespacedName.Name)
+	if b.ProbeExporter != nil {
+		labels[blackboxexporter.ProbeExporterLabelName] = b.ProbeExporter.Name
+	}
+	return labels
+}
+
+// ownerReferences returns the owner references of the blackbox exporter's resources, so that
+// the resources of a ProbeExporter are garbage collected with it
+func (b *BlackBoxExporter) ownerReferences() []metav1.OwnerReference {
+	if b.ProbeExporter == nil {
+		return nil
+	}
+	return []metav1.OwnerReference{*metav1.NewControllerRef(b.ProbeExporter, v1alpha1.GroupVersion.WithKind("ProbeExporter"))}
+}
+
+// ExporterRefIndex is the cache index of RouteMonitors and ClusterUrlMonitors by the blackbox exporter they select.
+// Monitors being deleted are not indexed, as they no longer depend on their exporter
+const ExporterRefIndex = "exporterRef"
+
+// operatorExporterIndexValue is the ExporterRefIndex value of monitors using the operator's blackbox exporter.
+// It can not collide with the name of a ProbeExporter
+const operatorExporterIndexValue = "#operator"
+
+// IndexExporterRef registers the ExporterRefIndex for RouteMonitors and ClusterUrlMonitors
+func IndexExporterRef(ctx context.Context, indexer client.FieldIndexer) error {
+	if err := indexer.IndexField(ctx, &v1alpha1.RouteMonitor{}, ExporterRefIndex, func(o client.Object) []string {
+		return exporterRefIndexValues(o, o.(*v1alpha1.RouteMonitor).Spec.ExporterRef)
+	}); err != nil {
+		return err
 	}
-	for i := range clusterUrlMonitors.Items {
-		objectsDependingOnExporter = append(objectsDependingOnExporter, &clusterUrlMonitors.Items[i])
+	return indexer.IndexField(ctx, &v1alpha1.ClusterUrlMonitor{}, ExporterRefIndex, func(o client.Object) []string {
+		return exporterRefIndexValues(o, o.(*v1alpha1.ClusterUrlMonitor).Spec.ExporterRef)
+	})
+}
+
+func exporterRefIndexValues(monitor client.Object, exporterRef string) []string {
+	if finalizer.WasDeleteRequested(monitor) {
+		return nil
+	}
+	if exporterRef == "" {
+		return []string{operatorExporterIndexValue}
 	}
-	b.Log.V(4).Info("Number of objects depending on BlackBoxExporter:", "amountOfObjects", len(objectsDependingOnExporter))
+	return []string{exporterRef}
+}
 
-	if len(objectsDependingOnExporter) == 1 && finalizer.WasDeleteRequested(objectsDependingOnExporter[0]) {
-		b.Log.V(3).Info("Deleting BlackBoxResources: decided to clean BlackBoxExporter resources")
-		return blackboxexporter.DeleteBlackBoxExporter, nil
+// CountDependentMonitors returns the number of RouteMonitors and ClusterUrlMonitors which are not being deleted
+// and select this blackbox exporter. The monitors are looked up by the ExporterRefIndex of the client's cache
+func (b *BlackBoxExporter) CountDependentMonitors() (int, error) {
+	routeMonitors, clusterUrlMonitors, err := b.listDependentMonitors()
+	if err != nil {
+		return 0, err
 	}
-	return blackboxexporter.KeepBlackBoxExporter, nil
+	count := len(routeMonitors.Items) + len(clusterUrlMonitors.Items)
+	b.Log.V(4).Info("Number of objects depending on BlackBoxExporter:", "amountOfObjects", count)
+	return count, nil
+}
+
+// listDependentMonitors returns the RouteMonitors and ClusterUrlMonitors which are not being deleted and select this blackbox exporter
+func (b *BlackBoxExporter) listDependentMonitors() (*v1alpha1.RouteMonitorList, *v1alpha1.ClusterUrlMonitorList, error) {
+	value := operatorExporterIndexValue
+	if b.ProbeExporter != nil {
+		value = b.ProbeExporter.Name
+	}
+
+	routeMonitors := &v1alpha1.RouteMonitorList{}
+	if err := b.monitorReader().List(b.Ctx, routeMonitors, client.MatchingFields{ExporterRefIndex: value}); err != nil {
+		return nil, nil, err
+	}
+	clusterUrlMonitors := &v1alpha1.ClusterUrlMonitorList{}
+	if err := b.monitorReader().List(b.Ctx, clusterUrlMonitors, client.MatchingFields{ExporterRefIndex: value}); err != nil {
+		return nil, nil, err
+	}
+	return routeMonitors, clusterUrlMonitors, nil
 }
 
 func (b *BlackBoxExporter) EnsureBlackBoxExporterDeploymentExists() error {
@@ -105,7 +220,7 @@ func (b *BlackBoxExporter) EnsureBlackBoxExporterDeploymentExists() error {
 
 func (b *BlackBoxExporter) EnsureBlackBoxExporterServiceExists() error {
 	resource := corev1.Service{}
-	populationFunc := func() corev1.Service { return templateForBlackBoxExporterService(b.NamespacedName) }
+	populationFunc := func() corev1.Service { return b.templateForBlackBoxExporterService() }
 
 	// Does the resource already exist?
 	if err := b.Client.Get(b.Ctx, b.NamespacedName, &resource); err != nil {
@@ -124,9 +239,14 @@ func (b *BlackBoxExporter) EnsureBlackBoxExporterServiceExists() error {
 	return nil
 }
 
+// EnsureBlackBoxExporterConfigMapExists ensures that the configuration of the blackbox exporter contains the shared modules
+// and the modules loaded by LoadMonitorModules
 func (b *BlackBoxExporter) EnsureBlackBoxExporterConfigMapExists() error {
 	resource := corev1.ConfigMap{}
-	populationFunc := func() corev1.ConfigMap { return templateForBlackBoxExporterConfigMap(b.NamespacedName) }
+	template, err := b.templateForBlackBoxExporterConfigMap()
+	if err != nil {
+		return err
+	}
 
 	// Does the resource already exist?
 	if err := b.Client.Get(b.Ctx, b.NamespacedName, &resource); err != nil {
@@ -135,71 +255,159 @@ func (b *BlackBoxExporter) EnsureBlackBoxExporterConfigMapExists() error {
 			// return unexpectedly
 			return err
 		}
-		// populate the resource with the template
-		resource := populationFunc()
 		// and create it
-		return b.Client.Create(b.Ctx, &resource)
+		return b.Client.Create(b.Ctx, &template)
+	}
+	if !reflect.DeepEqual(resource.Data, template.Data) {
+		resource.Data = template.Data
+		return b.Client.Update(b.Ctx, &resource)
+	}
+	return nil
+}
+
+// EnsureBlackBoxExporterCredentialsExist ensures that the Secret mounted to the blackbox exporter holds the credentials
+// loaded by LoadMonitorModules, and that no Secret exists if no module reads credentials
+func (b *BlackBoxExporter) EnsureBlackBoxExporterCredentialsExist() error {
+	name := types.NamespacedName{Name: blackboxexporter.CredentialsResourceName(b.NamespacedName.Name), Namespace: b.NamespacedName.Namespace}
+	resource := corev1.Secret{}
+	err := b.Client.Get(b.Ctx, name, &resource)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !b.HasCredentials() {
+		if !exists {
+			return nil
+		}
+		return b.Client.Delete(b.Ctx, &resource)
+	}
+
+	template := b.templateForBlackBoxExporterCredentials()
+	if !exists {
+		return b.Client.Create(b.Ctx, &template)
+	}
+	if !reflect.DeepEqual(resource.Data, template.Data) {
+		resource.Data = template.Data
+		return b.Client.Update(b.Ctx, &resource)
+	}
+	return nil
+}
+
+// EnsureBlackBoxExporterCABundlesExist ensures that the ConfigMaps the service CA bundle and the cluster's trusted CA bundle
+// are injected into exist while a module loaded by LoadMonitorModules verifies with them, and are removed otherwise
+func (b *BlackBoxExporter) EnsureBlackBoxExporterCABundlesExist() error {
+	serviceCA := b.templateForBlackBoxExporterCABundle(blackboxexporter.ServiceCAResourceName(b.NamespacedName.Name))
+	serviceCA.Annotations = map[string]string{blackboxexporter.ServiceCAInjectionAnnotationName: "true"}
+	if err := b.ensureCABundle(serviceCA, b.usesServiceCA); err != nil {
+		return err
+	}
+
+	trustedCABundle := b.templateForBlackBoxExporterCABundle(blackboxexporter.TrustedCABundleResourceName(b.NamespacedName.Name))
+	trustedCABundle.Labels[blackboxexporter.TrustedCABundleInjectionLabelName] = "true"
+	return b.ensureCABundle(trustedCABundle, b.usesTrustedCABundle)
+}
+
+// ensureCABundle creates or deletes a ConfigMap a CA bundle is injected into. The data of an existing ConfigMap
+// is owned by the injecting operator and is left untouched
+func (b *BlackBoxExporter) ensureCABundle(template corev1.ConfigMap, used bool) error {
+	resource := corev1.ConfigMap{}
+	err := b.Client.Get(b.Ctx, types.NamespacedName{Name: template.Name, Namespace: template.Namespace}, &resource)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if !used {
+		if !exists {
+			return nil
+		}
+		return b.Client.Delete(b.Ctx, &resource)
+	}
+	if !exists {
+		return b.Client.Create(b.Ctx, &template)
 	}
 	return nil
 }
 
 // deploymentForBlackBoxExporter returns a blackbox deployment
 func (b *BlackBoxExporter) templateForBlackBoxExporterDeployment(blackBoxImage string, blackBoxNamespacedName types.NamespacedName) (appsv1.Deployment, error) {
-	privateNLB, err := util.ClusterHasPrivateNLB(b.Client)
-	if err != nil {
-		return appsv1.Deployment{}, fmt.Errorf("failed to determine if cluster has private network LoadBalancer: %w", err)
+	var nodeSelector map[string]string
+	var affinity *corev1.Affinity
+	var tolerations []corev1.Toleration
+	if b.ProbeExporter != nil {
+		nodeSelector = b.ProbeExporter.Spec.NodeSelector
+		affinity = b.ProbeExporter.Spec.Affinity
+		tolerations = b.ProbeExporter.Spec.Tolerations
+	} else {
+		privateNLB, err := util.ClusterHasPrivateNLB(b.Client)
+		if err != nil {
+			return appsv1.Deployment{}, fmt.Errorf("failed to determine if cluster has private network LoadBalancer: %w", err)
+		}
+
+		nodeLabel := "node-role.kubernetes.io/infra"
+		if util.IsClusterVersionHigherOrEqualThan(b.Client, "4.13") && privateNLB {
+			nodeLabel = "node-role.kubernetes.io/master"
+		}
+		affinity = &corev1.Affinity{
+			NodeAffinity: &corev1.NodeAffinity{
+				PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{
+					Preference: corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{{
+							Key:      nodeLabel,
+							Operator: corev1.NodeSelectorOpExists,
+						}},
+					},
+					Weight: 1,
+				}},
+			},
+		}
+		tolerations = []corev1.Toleration{{
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+			Key:      nodeLabel,
+		}}
 	}
 
-	nodeLabel := "node-role.kubernetes.io/infra"
-	if util.IsClusterVersionHigherOrEqualThan(b.Client, "4.13") && privateNLB {
-		nodeLabel = "node-role.kubernetes.io/master"
+	cfg, err := b.config()
+	if err != nil {
+		return appsv1.Deployment{}, err
 	}
+	optional := true
 
-	labels := blackboxexporter.GenerateBlackBoxExporterLables()
+	labels := b.labels()
 	labelSelectors := metav1.LabelSelector{
-		MatchLabels: labels}
+		MatchLabels: blackboxexporter.GenerateLabelsForExporter(blackBoxNamespacedName.Name)}
 	// hardcode the replicasize for no
 	// replicas := m.Spec.Size
 	var replicas int32 = 1
 
 	dep := appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      blackBoxNamespacedName.Name,
-			Namespace: blackBoxNamespacedName.Namespace,
-			Labels:    labels,
+			Name:            blackBoxNamespacedName.Name,
+			Namespace:       blackBoxNamespacedName.Namespace,
+			Labels:          labels,
+			OwnerReferences: b.ownerReferences(),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
 			Selector: &labelSelectors,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: labels,
+					Labels:      labels,
+					Annotations: map[string]string{blackboxexporter.ConfigHashAnnotationName: configHash(cfg)},
 				},
 				Spec: corev1.PodSpec{
-					Affinity: &corev1.Affinity{
-						NodeAffinity: &corev1.NodeAffinity{
-							PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{{
-								Preference: corev1.NodeSelectorTerm{
-									MatchExpressions: []corev1.NodeSelectorRequirement{{
-										Key:      nodeLabel,
-										Operator: corev1.NodeSelectorOpExists,
-									}},
-								},
-								Weight: 1,
-							}},
-						},
-					},
-					Tolerations: []corev1.Toleration{{
-						Operator: corev1.TolerationOpExists,
-						Effect:   corev1.TaintEffectNoSchedule,
-						Key:      nodeLabel,
-					}},
+					NodeSelector: nodeSelector,
+					Affinity:     affinity,
+					Tolerations:  tolerations,
 					Containers: []corev1.Container{{
 						Image: blackBoxImage,
 						Name:  "blackbox-exporter",
 						Args: []string{
 							"--config.file=/config/blackbox.yaml",
 						},
+						Env: util.ProxyEnvVars(b.proxy),
 						Ports: []corev1.ContainerPort{{
 							ContainerPort: blackboxexporter.BlackBoxExporterPortNumber,
 							Name:          blackboxexporter.BlackBoxExporterPortName,
@@ -210,6 +418,21 @@ func (b *BlackBoxExporter) templateForBlackBoxExporterDeployment(blackBoxImage s
 								ReadOnly:  true,
 								MountPath: "/config",
 							},
+							{
+								Name:      "blackbox-credentials",
+								ReadOnly:  true,
+								MountPath: blackboxexporter.CredentialsMountPath,
+							},
+							{
+								Name:      "blackbox-service-ca",
+								ReadOnly:  true,
+								MountPath: blackboxexporter.ServiceCAMountPath,
+							},
+							{
+								Name:      "blackbox-trusted-ca-bundle",
+								ReadOnly:  true,
+								MountPath: blackboxexporter.TrustedCABundleMountPath,
+							},
 						},
 					}},
 					Volumes: []corev1.Volume{
@@ -223,6 +446,39 @@ func (b *BlackBoxExporter) templateForBlackBoxExporterDeployment(blackBoxImage s
 								},
 							},
 						},
+						{
+							// The Secret only exists while a module reads credentials
+							Name: "blackbox-credentials",
+							VolumeSource: corev1.VolumeSource{
+								Secret: &corev1.SecretVolumeSource{
+									SecretName: blackboxexporter.CredentialsResourceName(blackBoxNamespacedName.Name),
+									Optional:   &optional,
+								},
+							},
+						},
+						{
+							// The CA bundle ConfigMaps only exist while a module verifies with them
+							Name: "blackbox-service-ca",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: blackboxexporter.ServiceCAResourceName(blackBoxNamespacedName.Name),
+									},
+									Optional: &optional,
+								},
+							},
+						},
+						{
+							Name: "blackbox-trusted-ca-bundle",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: blackboxexporter.TrustedCABundleResourceName(blackBoxNamespacedName.Name),
+									},
+									Optional: &optional,
+								},
+							},
+						},
 					},
 				},
 			},
@@ -232,17 +488,16 @@ func (b *BlackBoxExporter) templateForBlackBoxExporterDeployment(blackBoxImage s
 }
 
 // templateForBlackBoxExporterService returns a blackbox service
-func templateForBlackBoxExporterService(blackboxNamespacedName types.NamespacedName) corev1.Service {
-	labels := blackboxexporter.GenerateBlackBoxExporterLables()
-
+func (b *BlackBoxExporter) templateForBlackBoxExporterService() corev1.Service {
 	svc := corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      blackboxNamespacedName.Name,
-			Namespace: blackboxNamespacedName.Namespace,
-			Labels:    labels,
+			Name:            b.NamespacedName.Name,
+			Namespace:       b.NamespacedName.Namespace,
+			Labels:          b.labels(),
+			OwnerReferences: b.ownerReferences(),
 		},
 		Spec: corev1.ServiceSpec{
-			Selector: labels,
+			Selector: blackboxexporter.GenerateLabelsForExporter(b.NamespacedName.Name),
 			Ports: []corev1.ServicePort{{
 				TargetPort: intstr.FromString(blackboxexporter.BlackBoxExporterPortName),
 				Port:       blackboxexporter.BlackBoxExporterPortNumber,
@@ -253,31 +508,96 @@ func templateForBlackBoxExporterService(blackboxNamespacedName types.NamespacedN
 	return svc
 }
 
-func templateForBlackBoxExporterConfigMap(blackboxNamespacedName types.NamespacedName) corev1.ConfigMap {
-	labels := blackboxexporter.GenerateBlackBoxExporterLables()
-
-	cfg := `modules:
-  http_2xx:
-    prober: http
-    timeout: 15s
-  insecure_http_2xx:
-    prober: http
-    timeout: 15s
-    http:
-      tls_config:
-        insecure_skip_verify: true`
+func (b *BlackBoxExporter) templateForBlackBoxExporterConfigMap() (corev1.ConfigMap, error) {
+	cfg, err := b.config()
+	if err != nil {
+		return corev1.ConfigMap{}, err
+	}
 
 	cm := corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      blackboxNamespacedName.Name,
-			Namespace: blackboxNamespacedName.Namespace,
-			Labels:    labels,
+			Name:            b.NamespacedName.Name,
+			Namespace:       b.NamespacedName.Namespace,
+			Labels:          b.labels(),
+			OwnerReferences: b.ownerReferences(),
 		},
 		Data: map[string]string{
 			"blackbox.yaml": cfg,
 		},
 	}
-	return cm
+	return cm, nil
+}
+
+// templateForBlackBoxExporterCredentials returns the Secret holding the credentials read by the modules of the blackbox exporter
+func (b *BlackBoxExporter) templateForBlackBoxExporterCredentials() corev1.Secret {
+	return corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            blackboxexporter.CredentialsResourceName(b.NamespacedName.Name),
+			Namespace:       b.NamespacedName.Namespace,
+			Labels:          b.labels(),
+			OwnerReferences: b.ownerReferences(),
+		},
+		Data: b.credentials,
+	}
+}
+
+// templateForBlackBoxExporterCABundle returns an empty ConfigMap for a CA bundle to be injected into
+func (b *BlackBoxExporter) templateForBlackBoxExporterCABundle(name string) corev1.ConfigMap {
+	return corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       b.NamespacedName.Namespace,
+			Labels:          b.labels(),
+			OwnerReferences: b.ownerReferences(),
+		},
+	}
+}
+
+// templateForBlackBoxExporterNetworkPolicy returns a NetworkPolicy restricting the egress of the blackbox exporter pods
+func (b *BlackBoxExporter) templateForBlackBoxExporterNetworkPolicy() networkingv1.NetworkPolicy {
+	return networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            b.NamespacedName.Name,
+			Namespace:       b.NamespacedName.Namespace,
+			Labels:          b.labels(),
+			OwnerReferences: b.ownerReferences(),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: blackboxexporter.GenerateLabelsForExporter(b.NamespacedName.Name),
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      b.ProbeExporter.Spec.Egress,
+		},
+	}
+}
+
+// EnsureBlackBoxExporterNetworkPolicy ensures that the egress of a ProbeExporter's blackbox exporter is restricted
+// by a NetworkPolicy according to its spec, and that no NetworkPolicy exists if its egress is not restricted
+func (b *BlackBoxExporter) EnsureBlackBoxExporterNetworkPolicy() error {
+	resource := networkingv1.NetworkPolicy{}
+	err := b.Client.Get(b.Ctx, b.NamespacedName, &resource)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	exists := err == nil
+
+	if b.ProbeExporter == nil || len(b.ProbeExporter.Spec.Egress) == 0 {
+		if !exists {
+			return nil
+		}
+		return b.Client.Delete(b.Ctx, &resource)
+	}
+
+	template := b.templateForBlackBoxExporterNetworkPolicy()
+	if !exists {
+		return b.Client.Create(b.Ctx, &template)
+	}
+	if !reflect.DeepEqual(resource.Spec, template.Spec) {
+		resource.Spec = template.Spec
+		return b.Client.Update(b.Ctx, &resource)
+	}
+	return nil
 }
 
 func (b *BlackBoxExporter) EnsureBlackBoxExporterDeploymentAbsent() error {
@@ -356,13 +676,42 @@ func (b *BlackBoxExporter) EnsureBlackBoxExporterResourcesAbsent() error {
 	if err := b.EnsureBlackBoxExporterConfigMapAbsent(); err != nil {
 		return err
 	}
+	b.Log.V(2).Info("Entering EnsureBlackBoxExporterCredentialsExist")
+	b.credentials = nil
+	if err := b.EnsureBlackBoxExporterCredentialsExist(); err != nil {
+		return err
+	}
+	b.Log.V(2).Info("Entering EnsureBlackBoxExporterCABundlesExist")
+	b.usesServiceCA, b.usesTrustedCABundle = false, false
+	if err := b.EnsureBlackBoxExporterCABundlesExist(); err != nil {
+		return err
+	}
 	return nil
 }
 
 func (b *BlackBoxExporter) EnsureBlackBoxExporterResourcesExist() error {
+	if err := b.LoadClusterProxy(); err != nil {
+		return err
+	}
+	if err := b.LoadMonitorModules(); err != nil {
+		return err
+	}
+	if err := b.EnsureBlackBoxExporterCredentialsExist(); err != nil {
+		return err
+	}
+	if err := b.EnsureBlackBoxExporterCABundlesExist(); err != nil {
+		return err
+	}
 	if err := b.EnsureBlackBoxExporterConfigMapExists(); err != nil {
 		return err
 	}
+	// Only the egress of ProbeExporters can be restricted, so that the operator's
+	// blackbox exporter does not need to look up a NetworkPolicy
+	if b.ProbeExporter != nil {
+		if err := b.EnsureBlackBoxExporterNetworkPolicy(); err != nil {
+			return err
+		}
+	}
 	if err := b.EnsureBlackBoxExporterDeploymentExists(); err != nil {
 		return err
 	}
@@ -2,6 +2,7 @@ package blackboxexporter_test
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -10,7 +11,17 @@ import (
 	"github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
 	consterror "github.com/openshift/route-monitor-operator/pkg/consts/test/error"
 	clientmocks "github.com/openshift/route-monitor-operator/pkg/util/test/generated/mocks/client"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -19,6 +30,7 @@ import (
 	. "github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
 	"github.com/openshift/route-monitor-operator/pkg/util/test/helper"
 
+	configv1 "github.com/openshift/api/config/v1"
 	operatorv1 "github.com/openshift/api/operator/v1"
 )
 
@@ -32,7 +44,7 @@ var _ = Describe("Blackboxexporter", func() {
 		get    helper.MockHelper
 		delete helper.MockHelper
 		create helper.MockHelper
-		list   helper.MockHelper
+		update helper.MockHelper
 	)
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
@@ -41,7 +53,7 @@ var _ = Describe("Blackboxexporter", func() {
 		get = helper.MockHelper{}
 		delete = helper.MockHelper{}
 		create = helper.MockHelper{}
-		list = helper.MockHelper{}
+		update = helper.MockHelper{}
 	})
 	JustBeforeEach(func() {
 		blackboxExporter = BlackBoxExporter{
@@ -61,6 +73,10 @@ var _ = Describe("Blackboxexporter", func() {
 		mockClient.EXPECT().Create(gomock.Any(), gomock.Any()).
 			Return(create.ErrorResponse).
 			Times(create.CalledTimes)
+
+		mockClient.EXPECT().Update(gomock.Any(), gomock.Any()).
+			Return(update.ErrorResponse).
+			Times(update.CalledTimes)
 	})
 	AfterEach(func() {
 		mockCtrl.Finish()
@@ -321,90 +337,50 @@ var _ = Describe("Blackboxexporter", func() {
 			})
 		})
 	})
-	Describe("ShouldDeleteBlackBoxExporterResources", func() {
+	Describe("CountDependentMonitors", func() {
 		var (
-			routeMonitor       v1alpha1.RouteMonitor
-			routeMonitors      v1alpha1.RouteMonitorList
-			clusterUrlMonitors v1alpha1.ClusterUrlMonitorList
+			objects []client.Object
+			bbe     *BlackBoxExporter
 		)
-		JustBeforeEach(func() {
-			gomock.InOrder(
-				mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(list.ErrorResponse).SetArg(1, routeMonitors).Times(1),
-				mockClient.EXPECT().List(gomock.Any(), gomock.Any()).Return(list.ErrorResponse).SetArg(1, clusterUrlMonitors).AnyTimes(),
-			)
-		})
 		BeforeEach(func() {
-			list.CalledTimes = 2
+			objects = []client.Object{
+				&v1alpha1.RouteMonitor{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "test"}},
+				&v1alpha1.RouteMonitor{
+					ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: "test", DeletionTimestamp: &metav1.Time{Time: time.Unix(0, 0)}, Finalizers: []string{"test"}},
+				},
+				&v1alpha1.RouteMonitor{ObjectMeta: metav1.ObjectMeta{Name: "zone-a", Namespace: "test"}, Spec: v1alpha1.RouteMonitorSpec{ExporterRef: "zone-a"}},
+				&v1alpha1.ClusterUrlMonitor{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "test"}},
+				&v1alpha1.ClusterUrlMonitor{ObjectMeta: metav1.ObjectMeta{Name: "zone-b", Namespace: "test"}, Spec: v1alpha1.ClusterUrlMonitorSpec{ExporterRef: "zone-b"}},
+			}
 		})
-
 		JustBeforeEach(func() {
-			routeMonitor.DeletionTimestamp = &metav1.Time{Time: time.Unix(0, 0)}
+			builder := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objects...)
+			Expect(IndexExporterRef(context.Background(), builderIndexer{builder})).To(Succeed())
+			bbe = New(builder.Build(), logr.Discard(), context.Background(), "test-image", "test-namespace")
 		})
-		When("the `List` command fails", func() {
-			// Arrange
-			BeforeEach(func() {
-				list = helper.CustomErrorHappensOnce()
-			})
-			It("should fail with the List error", func() {
-				// Act
-				_, err := blackboxExporter.ShouldDeleteBlackBoxExporterResources()
-				// Assert
-				Expect(err).To(HaveOccurred())
-				Expect(err).To(MatchError(consterror.ErrCustomError))
-			})
-		})
-
-		When("there are many RouteMonitors", func() {
-			var (
-				routeMonitorSecond v1alpha1.RouteMonitor
-			)
 
-			BeforeEach(func() {
-				routeMonitor.ObjectMeta = metav1.ObjectMeta{
-					Name:      "fake-name",
-					Namespace: "fake-namespace",
-				}
-				routeMonitorSecond.ObjectMeta = metav1.ObjectMeta{
-					Name:      routeMonitor.Name + "-but-different",
-					Namespace: routeMonitor.Namespace,
-				}
-				routeMonitors.Items = []v1alpha1.RouteMonitor{
-					routeMonitor,
-					routeMonitorSecond,
-				}
-			})
-			It("should return 'false' for too many RouteMonitors", func() {
-				// Act
-				res, err := blackboxExporter.ShouldDeleteBlackBoxExporterResources()
-				// Assert
-				Expect(err).NotTo(HaveOccurred())
-				Expect(res).To(Equal(blackboxexporter.KeepBlackBoxExporter))
+		It("should count the monitors using the operator's blackbox exporter which are not being deleted", func() {
+			count, err := bbe.CountDependentMonitors()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
 
-			})
+		It("should count the monitors using a ProbeExporter", func() {
+			bbe.ProbeExporter = testProbeExporter()
+			count, err := bbe.CountDependentMonitors()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
 		})
 
-		When("there is just one RouteMonitor, and it's being deleted", func() {
+		When("no monitors remain", func() {
 			BeforeEach(func() {
-				clusterUrlMonitors.Items = []v1alpha1.ClusterUrlMonitor{}
-				routeMonitors.Items = []v1alpha1.RouteMonitor{
-					{
-						ObjectMeta: metav1.ObjectMeta{
-							Name:              "fake-route-monitor",
-							Namespace:         "fake-route-monitor-namespace",
-							DeletionTimestamp: &metav1.Time{Time: time.Unix(0, 0)},
-						},
-					},
-				}
+				objects = objects[1:3]
 			})
-			// Arrange
-			It("should return 'true'", func() {
-				// Act
-				res, err := blackboxExporter.ShouldDeleteBlackBoxExporterResources()
-				// Assert
+			It("should return zero", func() {
+				count, err := bbe.CountDependentMonitors()
 				Expect(err).NotTo(HaveOccurred())
-				Expect(res).To(Equal(blackboxexporter.DeleteBlackBoxExporter))
+				Expect(count).To(BeZero())
 			})
-
 		})
 	})
 
@@ -425,12 +401,405 @@ var _ = Describe("Blackboxexporter", func() {
 		})
 	})
 
+	Describe("GetExporterService", func() {
+		When("the monitor does not reference a ProbeExporter", func() {
+			It("should return the operator's blackbox exporter", func() {
+				bbe := New(mockClient, logr.Discard(), context.Background(), "test-image", "test-namespace")
+				result, err := bbe.GetExporterService("")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(types.NamespacedName{Name: blackboxexporter.BlackBoxExporterName, Namespace: "test-namespace"}))
+			})
+		})
+
+		When("the referenced ProbeExporter does not exist", func() {
+			BeforeEach(func() {
+				get = helper.NotFoundErrorHappensOnce()
+			})
+			It("should return an error", func() {
+				_, err := blackboxExporter.GetExporterService("zone-a")
+				Expect(err).To(MatchError(ContainSubstring(`ProbeExporter "zone-a"`)))
+			})
+		})
+
+		When("the referenced ProbeExporter exists", func() {
+			It("should return the Service of its blackbox exporter", func() {
+				c := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(testProbeExporter()).Build()
+				bbe := New(c, logr.Discard(), context.Background(), "test-image", "test-namespace")
+				result, err := bbe.GetExporterService("zone-a")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(types.NamespacedName{Name: "blackbox-exporter-zone-a", Namespace: "zone-a-probes"}))
+			})
+		})
+	})
+
+	Describe("NewForProbeExporter", func() {
+		var (
+			c             client.Client
+			probeExporter *v1alpha1.ProbeExporter
+			name          types.NamespacedName
+		)
+		BeforeEach(func() {
+			probeExporter = testProbeExporter()
+			builder := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(probeExporter)
+			Expect(IndexExporterRef(context.Background(), builderIndexer{builder})).To(Succeed())
+			c = builder.Build()
+			name = types.NamespacedName{Name: "blackbox-exporter-zone-a", Namespace: "zone-a-probes"}
+		})
+
+		It("should deploy the blackbox exporter in the ProbeExporter's namespace", func() {
+			bbe := NewForProbeExporter(c, logr.Discard(), context.Background(), "test-image", probeExporter)
+			Expect(bbe.EnsureBlackBoxExporterResourcesExist()).To(Succeed())
+
+			deployment := appsv1.Deployment{}
+			Expect(c.Get(context.Background(), name, &deployment)).To(Succeed())
+			Expect(deployment.OwnerReferences).To(HaveLen(1))
+			Expect(deployment.OwnerReferences[0].Name).To(Equal("zone-a"))
+			Expect(deployment.Labels).To(HaveKeyWithValue(blackboxexporter.ProbeExporterLabelName, "zone-a"))
+			Expect(deployment.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "blackbox-exporter-zone-a"}))
+			Expect(deployment.Spec.Template.Spec.NodeSelector).To(Equal(probeExporter.Spec.NodeSelector))
+			Expect(deployment.Spec.Template.Spec.Tolerations).To(BeEmpty())
+
+			service := corev1.Service{}
+			Expect(c.Get(context.Background(), name, &service)).To(Succeed())
+			Expect(service.Spec.Selector).To(Equal(map[string]string{"app": "blackbox-exporter-zone-a"}))
+			Expect(c.Get(context.Background(), name, &corev1.ConfigMap{})).To(Succeed())
+
+			networkPolicy := networkingv1.NetworkPolicy{}
+			Expect(c.Get(context.Background(), name, &networkPolicy)).To(Succeed())
+			Expect(networkPolicy.Spec.PolicyTypes).To(Equal([]networkingv1.PolicyType{networkingv1.PolicyTypeEgress}))
+			Expect(networkPolicy.Spec.Egress).To(Equal(probeExporter.Spec.Egress))
+		})
+
+		It("should delete the NetworkPolicy once egress is not restricted anymore", func() {
+			bbe := NewForProbeExporter(c, logr.Discard(), context.Background(), "test-image", probeExporter)
+			Expect(bbe.EnsureBlackBoxExporterResourcesExist()).To(Succeed())
+
+			probeExporter.Spec.Egress = nil
+			Expect(bbe.EnsureBlackBoxExporterNetworkPolicy()).To(Succeed())
+			err := c.Get(context.Background(), name, &networkingv1.NetworkPolicy{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
+
+	Describe("ModuleForRouteMonitor", func() {
+		var routeMonitor v1alpha1.RouteMonitor
+		BeforeEach(func() {
+			routeMonitor = v1alpha1.RouteMonitor{ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"}}
+		})
+		It("should use the shared modules for monitors without probe settings", func() {
+			Expect(ModuleForRouteMonitor(routeMonitor)).To(Equal(blackboxexporter.HTTP2xxModule))
+			routeMonitor.Spec.InsecureSkipTLSVerify = true
+			Expect(ModuleForRouteMonitor(routeMonitor)).To(Equal(blackboxexporter.InsecureHTTP2xxModule))
+		})
+		It("should use a module of their own for monitors probing with credentials", func() {
+			routeMonitor.Spec.Probe.Auth = &v1alpha1.ProbeAuthSpec{BearerToken: &corev1.SecretKeySelector{}}
+			Expect(ModuleForRouteMonitor(routeMonitor)).To(Equal("routemonitor_openshift-console_console"))
+			Expect(ModuleForClusterUrlMonitor(v1alpha1.ClusterUrlMonitor{ObjectMeta: routeMonitor.ObjectMeta, Spec: v1alpha1.ClusterUrlMonitorSpec{Probe: routeMonitor.Spec.Probe}})).
+				To(Equal("clusterurlmonitor_openshift-console_console"))
+		})
+		It("should use a module of their own for monitors verifying with a CA bundle", func() {
+			routeMonitor.Spec.Probe.TLS = &v1alpha1.ProbeTLSSpec{CA: &v1alpha1.CABundleSpec{TrustedCABundle: true}}
+			Expect(ModuleForRouteMonitor(routeMonitor)).To(Equal("routemonitor_openshift-console_console"))
+		})
+	})
+
+	Describe("ValidateProbe", func() {
+		It("should accept probes without expectations", func() {
+			Expect(ValidateProbe(v1alpha1.ProbeSpec{})).To(Succeed())
+		})
+		It("should reject invalid regular expressions", func() {
+			Expect(ValidateProbe(v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{BodyRegex: "(unclosed"}})).
+				To(MatchError(ContainSubstring("probe.expect.bodyRegex")))
+			Expect(ValidateProbe(v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{Headers: []v1alpha1.HeaderExpectation{{Name: "Server", Regex: "[a-"}}}})).
+				To(MatchError(ContainSubstring("probe.expect.headers[0].regex")))
+		})
+		It("should reject JSONPaths beyond field names and array indices", func() {
+			Expect(ValidateProbe(v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{JSONPath: &v1alpha1.JSONPathExpectation{Path: "$.items[*].name", Value: "a"}}})).
+				To(MatchError(ContainSubstring("probe.expect.jsonPath.path")))
+		})
+		It("should accept JSON literals and plain strings as values", func() {
+			for _, value := range []string{"3", `"ok"`, "ok", "null", "false"} {
+				Expect(ValidateProbe(v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{JSONPath: &v1alpha1.JSONPathExpectation{Path: "$.items[0].status", Value: value}}})).To(Succeed())
+			}
+			Expect(ValidateProbe(v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{JSONPath: &v1alpha1.JSONPathExpectation{Path: "$.items", Value: "[1, 2]"}}})).
+				To(MatchError(ContainSubstring("probe.expect.jsonPath.value")))
+		})
+	})
+
+	Describe("ValidateRouteMonitorProbe", func() {
+		var routeMonitor v1alpha1.RouteMonitor
+		BeforeEach(func() {
+			routeMonitor = v1alpha1.RouteMonitor{
+				ObjectMeta: metav1.ObjectMeta{Name: "protected", Namespace: "app"},
+				Spec: v1alpha1.RouteMonitorSpec{
+					Route: v1alpha1.RouteMonitorRouteSpec{Name: "protected", Namespace: "app"},
+					Probe: v1alpha1.ProbeSpec{Auth: &v1alpha1.ProbeAuthSpec{BearerToken: &corev1.SecretKeySelector{}}},
+				},
+			}
+		})
+		It("should accept credentials sent to a Route in the monitor's namespace", func() {
+			Expect(ValidateRouteMonitorProbe(routeMonitor)).To(Succeed())
+		})
+		It("should reject credentials sent to a Route in another namespace", func() {
+			routeMonitor.Spec.Route.Namespace = "other"
+			Expect(ValidateRouteMonitorProbe(routeMonitor)).To(MatchError(ContainSubstring("probe.auth")))
+			routeMonitor.Spec.Probe.Auth = nil
+			routeMonitor.Spec.Probe.TLS = &v1alpha1.ProbeTLSSpec{ClientCertificate: &v1alpha1.ClientCertificateSpec{}}
+			Expect(ValidateRouteMonitorProbe(routeMonitor)).To(MatchError(ContainSubstring("probe.tls.clientCertificate")))
+		})
+		It("should accept Routes in another namespace for probes without credentials", func() {
+			routeMonitor.Spec.Route.Namespace = "other"
+			routeMonitor.Spec.Probe = v1alpha1.ProbeSpec{TLS: &v1alpha1.ProbeTLSSpec{CA: &v1alpha1.CABundleSpec{ServiceCA: true}}}
+			Expect(ValidateRouteMonitorProbe(routeMonitor)).To(Succeed())
+		})
+	})
+
+	Describe("LoadMonitorModules", func() {
+		var (
+			objects []client.Object
+			c       client.Client
+			bbe     *BlackBoxExporter
+			name    types.NamespacedName
+		)
+		basicAuth := &v1alpha1.ProbeAuthSpec{BasicAuth: &v1alpha1.BasicAuthSpec{
+			Username: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "probe-credentials"}, Key: "username"},
+			Password: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "probe-credentials"}, Key: "password"},
+		}}
+		BeforeEach(func() {
+			name = types.NamespacedName{Name: blackboxexporter.BlackBoxExporterName, Namespace: "test-namespace"}
+			objects = []client.Object{
+				testPrivateDefaultICObject(),
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "probe-credentials", Namespace: "test"},
+					Data:       map[string][]byte{"username": []byte("prober"), "password": []byte("hunter2")},
+				},
+				&v1alpha1.ClusterUrlMonitor{
+					ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "test"},
+					Spec:       v1alpha1.ClusterUrlMonitorSpec{Probe: v1alpha1.ProbeSpec{Auth: basicAuth}},
+				},
+			}
+		})
+		JustBeforeEach(func() {
+			builder := fake.NewClientBuilder().WithScheme(testScheme()).WithObjects(objects...)
+			Expect(IndexExporterRef(context.Background(), builderIndexer{builder})).To(Succeed())
+			c = builder.Build()
+			bbe = New(c, logr.Discard(), context.Background(), "test-image", "test-namespace")
+			Expect(bbe.EnsureBlackBoxExporterResourcesExist()).To(Succeed())
+		})
+
+		It("should generate a module reading the basic auth credentials from files", func() {
+			Expect(bbe.HasCredentials()).To(BeTrue())
+			credentials := corev1.Secret{}
+			Expect(c.Get(context.Background(), types.NamespacedName{Name: "blackbox-exporter-credentials", Namespace: "test-namespace"}, &credentials)).To(Succeed())
+			Expect(credentials.Data).To(Equal(map[string][]byte{
+				"clusterurlmonitor_test_api_username": []byte("prober"),
+				"clusterurlmonitor_test_api_password": []byte("hunter2"),
+			}))
+
+			configMap := corev1.ConfigMap{}
+			Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+			cfg := configMap.Data["blackbox.yaml"]
+			Expect(cfg).To(ContainSubstring("username_file: /credentials/clusterurlmonitor_test_api_username"))
+			Expect(cfg).To(ContainSubstring("password_file: /credentials/clusterurlmonitor_test_api_password"))
+			Expect(cfg).NotTo(ContainSubstring("hunter2"))
+
+			deployment := appsv1.Deployment{}
+			Expect(c.Get(context.Background(), name, &deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations).To(HaveKey(blackboxexporter.ConfigHashAnnotationName))
+		})
+
+		It("should report references which can not be resolved", func() {
+			Expect(bbe.ValidateProbeReferences("test", v1alpha1.ProbeSpec{Auth: basicAuth})).To(Succeed())
+			Expect(bbe.ValidateProbeReferences("other", v1alpha1.ProbeSpec{Auth: basicAuth})).To(MatchError(ContainSubstring("invalid probe.auth")))
+		})
+
+		When("a monitor sends credentials to a Route in another namespace", func() {
+			BeforeEach(func() {
+				objects = append(objects, &v1alpha1.RouteMonitor{
+					ObjectMeta: metav1.ObjectMeta{Name: "foreign", Namespace: "test"},
+					Spec: v1alpha1.RouteMonitorSpec{
+						Route: v1alpha1.RouteMonitorRouteSpec{Name: "foreign", Namespace: "other"},
+						Probe: v1alpha1.ProbeSpec{Auth: basicAuth},
+					},
+				})
+			})
+			It("should not generate a module for it", func() {
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+				Expect(configMap.Data["blackbox.yaml"]).NotTo(ContainSubstring("routemonitor_test_foreign"))
+			})
+		})
+
+		It("should remove the module and credentials once no monitor needs them", func() {
+			deployment := appsv1.Deployment{}
+			Expect(c.Get(context.Background(), name, &deployment)).To(Succeed())
+			previousHash := deployment.Spec.Template.Annotations[blackboxexporter.ConfigHashAnnotationName]
+
+			monitor := v1alpha1.ClusterUrlMonitor{}
+			Expect(c.Get(context.Background(), types.NamespacedName{Name: "api", Namespace: "test"}, &monitor)).To(Succeed())
+			monitor.Spec.Probe.Auth = nil
+			Expect(c.Update(context.Background(), &monitor)).To(Succeed())
+			Expect(bbe.EnsureBlackBoxExporterResourcesExist()).To(Succeed())
+
+			Expect(bbe.HasCredentials()).To(BeFalse())
+			err := c.Get(context.Background(), types.NamespacedName{Name: "blackbox-exporter-credentials", Namespace: "test-namespace"}, &corev1.Secret{})
+			Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			configMap := corev1.ConfigMap{}
+			Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+			Expect(configMap.Data["blackbox.yaml"]).NotTo(ContainSubstring("clusterurlmonitor_test_api"))
+			Expect(c.Get(context.Background(), name, &deployment)).To(Succeed())
+			Expect(deployment.Spec.Template.Annotations[blackboxexporter.ConfigHashAnnotationName]).NotTo(Equal(previousHash))
+		})
+
+		When("monitors verify the probed endpoints with CA bundles", func() {
+			BeforeEach(func() {
+				objects = append(objects,
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Name: "root-ca", Namespace: "test"},
+						Data:       map[string]string{"ca.crt": "test-ca"},
+					},
+					&corev1.Secret{
+						ObjectMeta: metav1.ObjectMeta{Name: "client-certificate", Namespace: "test"},
+						Data:       map[string][]byte{"tls.crt": []byte("test-certificate"), "tls.key": []byte("test-key")},
+					},
+					&v1alpha1.RouteMonitor{
+						ObjectMeta: metav1.ObjectMeta{Name: "internal", Namespace: "test"},
+						Spec: v1alpha1.RouteMonitorSpec{Route: v1alpha1.RouteMonitorRouteSpec{Name: "internal", Namespace: "test"}, Probe: v1alpha1.ProbeSpec{TLS: &v1alpha1.ProbeTLSSpec{
+							CA: &v1alpha1.CABundleSpec{ConfigMap: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "root-ca"}, Key: "ca.crt"}},
+							ClientCertificate: &v1alpha1.ClientCertificateSpec{
+								Certificate: corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "client-certificate"}, Key: "tls.crt"},
+								Key:         corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "client-certificate"}, Key: "tls.key"},
+							},
+						}}},
+					},
+					&v1alpha1.RouteMonitor{
+						ObjectMeta: metav1.ObjectMeta{Name: "service", Namespace: "test"},
+						Spec:       v1alpha1.RouteMonitorSpec{Probe: v1alpha1.ProbeSpec{TLS: &v1alpha1.ProbeTLSSpec{CA: &v1alpha1.CABundleSpec{ServiceCA: true}}}},
+					},
+				)
+			})
+			It("should copy the CA bundle and client certificate next to the credentials", func() {
+				credentials := corev1.Secret{}
+				Expect(c.Get(context.Background(), types.NamespacedName{Name: "blackbox-exporter-credentials", Namespace: "test-namespace"}, &credentials)).To(Succeed())
+				Expect(credentials.Data).To(HaveKeyWithValue("routemonitor_test_internal_ca.crt", []byte("test-ca")))
+				Expect(credentials.Data).To(HaveKeyWithValue("routemonitor_test_internal_tls.crt", []byte("test-certificate")))
+				Expect(credentials.Data).To(HaveKeyWithValue("routemonitor_test_internal_tls.key", []byte("test-key")))
+
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+				cfg := configMap.Data["blackbox.yaml"]
+				Expect(cfg).To(ContainSubstring("ca_file: /credentials/routemonitor_test_internal_ca.crt"))
+				Expect(cfg).To(ContainSubstring("cert_file: /credentials/routemonitor_test_internal_tls.crt"))
+				Expect(cfg).To(ContainSubstring("key_file: /credentials/routemonitor_test_internal_tls.key"))
+				Expect(cfg).To(ContainSubstring("ca_file: /ca/service-ca/service-ca.crt"))
+			})
+			It("should request the injection of the service CA bundle only while it is used", func() {
+				serviceCA := types.NamespacedName{Name: "blackbox-exporter-service-ca", Namespace: "test-namespace"}
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), serviceCA, &configMap)).To(Succeed())
+				Expect(configMap.Annotations).To(HaveKeyWithValue(blackboxexporter.ServiceCAInjectionAnnotationName, "true"))
+				err := c.Get(context.Background(), types.NamespacedName{Name: "blackbox-exporter-trusted-ca-bundle", Namespace: "test-namespace"}, &corev1.ConfigMap{})
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+
+				monitor := v1alpha1.RouteMonitor{}
+				Expect(c.Get(context.Background(), types.NamespacedName{Name: "service", Namespace: "test"}, &monitor)).To(Succeed())
+				Expect(c.Delete(context.Background(), &monitor)).To(Succeed())
+				Expect(bbe.EnsureBlackBoxExporterResourcesExist()).To(Succeed())
+				err = c.Get(context.Background(), serviceCA, &corev1.ConfigMap{})
+				Expect(k8serrors.IsNotFound(err)).To(BeTrue())
+			})
+		})
+
+		When("the cluster has a proxy", func() {
+			BeforeEach(func() {
+				objects = append(objects, &configv1.Proxy{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+					Status: configv1.ProxyStatus{
+						HTTPProxy:  "http://proxy.example.com:3128",
+						HTTPSProxy: "http://proxy.example.com:3128",
+						NoProxy:    ".cluster.local,.svc",
+					},
+				})
+			})
+			It("should probe through the proxy", func() {
+				deployment := appsv1.Deployment{}
+				Expect(c.Get(context.Background(), name, &deployment)).To(Succeed())
+				Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(ConsistOf(
+					corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy.example.com:3128"},
+					corev1.EnvVar{Name: "HTTPS_PROXY", Value: "http://proxy.example.com:3128"},
+					corev1.EnvVar{Name: "NO_PROXY", Value: ".cluster.local,.svc"},
+				))
+
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+				Expect(strings.Count(configMap.Data["blackbox.yaml"], "proxy_from_environment: true")).To(Equal(3))
+			})
+			It("should roll out the exporter once the proxy is removed", func() {
+				Expect(c.Delete(context.Background(), &configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}})).To(Succeed())
+				Expect(bbe.EnsureBlackBoxExporterResourcesExist()).To(Succeed())
+				deployment := appsv1.Deployment{}
+				Expect(c.Get(context.Background(), name, &deployment)).To(Succeed())
+				Expect(deployment.Spec.Template.Spec.Containers[0].Env).To(BeEmpty())
+			})
+		})
+
+		When("monitors assert on the probe responses", func() {
+			BeforeEach(func() {
+				objects = append(objects,
+					&v1alpha1.RouteMonitor{
+						ObjectMeta: metav1.ObjectMeta{Name: "health", Namespace: "test"},
+						Spec: v1alpha1.RouteMonitorSpec{Probe: v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{
+							StatusCodes: []int32{200, 204},
+							BodyRegex:   "ok|healthy",
+							Headers:     []v1alpha1.HeaderExpectation{{Name: "Content-Type", Regex: "application/json"}},
+							JSONPath:    &v1alpha1.JSONPathExpectation{Path: "$.status.ready", Value: "true"},
+						}}},
+					},
+					&v1alpha1.RouteMonitor{
+						ObjectMeta: metav1.ObjectMeta{Name: "invalid", Namespace: "test"},
+						Spec:       v1alpha1.RouteMonitorSpec{Probe: v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{BodyRegex: "(unclosed"}}},
+					},
+				)
+			})
+			It("should generate a module failing on unexpected responses", func() {
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+				cfg := configMap.Data["blackbox.yaml"]
+				Expect(cfg).To(ContainSubstring("routemonitor_test_health"))
+				Expect(cfg).To(MatchRegexp(`valid_status_codes:\s+- 200\s+- 204`))
+				Expect(cfg).To(MatchRegexp(`fail_if_body_not_matches_regexp:\s+- ok\|healthy`))
+				Expect(cfg).To(ContainSubstring("header: Content-Type"))
+				Expect(cfg).To(ContainSubstring("regexp: application/json"))
+				Expect(cfg).To(ContainSubstring("fail_if_body_json_not_matches_cel: body.status.ready == true"))
+			})
+			It("should skip the module of a monitor with an invalid regular expression", func() {
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+				Expect(configMap.Data["blackbox.yaml"]).NotTo(ContainSubstring("routemonitor_test_invalid"))
+			})
+		})
+
+		When("the referenced Secret does not exist", func() {
+			BeforeEach(func() {
+				objects = append(objects[:1], objects[2:]...)
+			})
+			It("should skip the monitor's module without failing", func() {
+				Expect(bbe.HasCredentials()).To(BeFalse())
+				configMap := corev1.ConfigMap{}
+				Expect(c.Get(context.Background(), name, &configMap)).To(Succeed())
+				Expect(configMap.Data["blackbox.yaml"]).NotTo(ContainSubstring("clusterurlmonitor_test_api"))
+				Expect(configMap.Data["blackbox.yaml"]).To(ContainSubstring(blackboxexporter.HTTP2xxModule))
+			})
+		})
+	})
+
 	Describe("EnsureBlackBoxExporterConfigMapExists", func() {
 		When("the resource exists", func() {
 			BeforeEach(func() {
 				get.CalledTimes = 1
+				update.CalledTimes = 1
 			})
-			It("should not create a new ConfigMap", func() {
+			It("should update the ConfigMap instead of creating a new one", func() {
 				err := blackboxExporter.EnsureBlackBoxExporterConfigMapExists()
 				Expect(err).NotTo(HaveOccurred())
 			})
@@ -495,8 +864,8 @@ var _ = Describe("Blackboxexporter", func() {
 
 	Describe("EnsureBlackBoxExporterResourcesAbsent", func() {
 		BeforeEach(func() {
-			get.CalledTimes = 3
-			delete.CalledTimes = 3
+			get.CalledTimes = 6
+			delete.CalledTimes = 6
 		})
 		It("should delete all BlackBox Exporter resources", func() {
 			err := blackboxExporter.EnsureBlackBoxExporterResourcesAbsent()
@@ -506,6 +875,44 @@ var _ = Describe("Blackboxexporter", func() {
 
 })
 
+// builderIndexer registers indexes with a fake client
+type builderIndexer struct {
+	builder *fake.ClientBuilder
+}
+
+func (i builderIndexer) IndexField(_ context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	i.builder.WithIndex(obj, field, extractValue)
+	return nil
+}
+
+func testScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(v1alpha1.AddToScheme(scheme)).To(Succeed())
+	Expect(operatorv1.AddToScheme(scheme)).To(Succeed())
+	Expect(configv1.AddToScheme(scheme)).To(Succeed())
+	return scheme
+}
+
+func testProbeExporter() *v1alpha1.ProbeExporter {
+	port := intstr.FromInt(443)
+	return &v1alpha1.ProbeExporter{
+		ObjectMeta: metav1.ObjectMeta{Name: "zone-a"},
+		Spec: v1alpha1.ProbeExporterSpec{
+			Namespace:    "zone-a-probes",
+			NodeSelector: map[string]string{"topology.kubernetes.io/zone": "zone-a"},
+			Egress: []networkingv1.NetworkPolicyEgressRule{{
+				Ports: []networkingv1.NetworkPolicyPort{{Port: &port}},
+			}},
+		},
+	}
+}
+
+func testPrivateDefaultICObject() *operatorv1.IngressController {
+	ic := testPrivateDefaultIC()
+	return &ic
+}
+
 func testPrivateDefaultIC() operatorv1.IngressController {
 	ic := operatorv1.IngressController{
 		ObjectMeta: metav1.ObjectMeta{
@@ -0,0 +1,487 @@
+package blackboxexporter
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/util"
+)
+
+const (
+	routeMonitorModulePrefix      = "routemonitor"
+	clusterUrlMonitorModulePrefix = "clusterurlmonitor"
+
+	// probeTimeout is the timeout of every module's probes
+	probeTimeout = "15s"
+)
+
+// blackboxConfig is the configuration file of the blackbox exporter
+type blackboxConfig struct {
+	Modules map[string]module `json:"modules"`
+}
+
+// module is a blackbox exporter module, see https://github.com/prometheus/blackbox_exporter/blob/master/CONFIGURATION.md
+type module struct {
+	Prober  string     `json:"prober"`
+	Timeout string     `json:"timeout"`
+	HTTP    *httpProbe `json:"http,omitempty"`
+}
+
+type httpProbe struct {
+	// ProxyFromEnvironment probes through the proxy of the exporter's HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables
+	ProxyFromEnvironment bool           `json:"proxy_from_environment,omitempty"`
+	TLSConfig            *tlsConfig     `json:"tls_config,omitempty"`
+	Authorization        *authorization `json:"authorization,omitempty"`
+	BasicAuth            *basicAuth     `json:"basic_auth,omitempty"`
+
+	ValidStatusCodes             []int32       `json:"valid_status_codes,omitempty"`
+	FailIfBodyNotMatchesRegexp   []string      `json:"fail_if_body_not_matches_regexp,omitempty"`
+	FailIfHeaderNotMatchesRegexp []headerMatch `json:"fail_if_header_not_matches,omitempty"`
+	// FailIfBodyJSONNotMatchesCEL is evaluated on the JSON body, which is available to the expression as body
+	FailIfBodyJSONNotMatchesCEL string `json:"fail_if_body_json_not_matches_cel,omitempty"`
+}
+
+type headerMatch struct {
+	Header       string `json:"header"`
+	Regexp       string `json:"regexp"`
+	AllowMissing bool   `json:"allow_missing,omitempty"`
+}
+
+type tlsConfig struct {
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty"`
+	CAFile             string `json:"ca_file,omitempty"`
+	CertFile           string `json:"cert_file,omitempty"`
+	KeyFile            string `json:"key_file,omitempty"`
+}
+
+type authorization struct {
+	Type            string `json:"type"`
+	CredentialsFile string `json:"credentials_file"`
+}
+
+type basicAuth struct {
+	UsernameFile string `json:"username_file"`
+	PasswordFile string `json:"password_file"`
+}
+
+// ModuleForRouteMonitor returns the name of the blackbox exporter module probing the URL of a RouteMonitor
+func ModuleForRouteMonitor(routeMonitor v1alpha1.RouteMonitor) string {
+	return moduleForMonitor(routeMonitorModulePrefix, routeMonitor.Namespace, routeMonitor.Name, routeMonitor.Spec.Probe, routeMonitor.Spec.InsecureSkipTLSVerify)
+}
+
+// ModuleForClusterUrlMonitor returns the name of the blackbox exporter module probing the URL of a ClusterUrlMonitor
+func ModuleForClusterUrlMonitor(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) string {
+	return moduleForMonitor(clusterUrlMonitorModulePrefix, clusterUrlMonitor.Namespace, clusterUrlMonitor.Name, clusterUrlMonitor.Spec.Probe, false)
+}
+
+// moduleForMonitor returns the shared module matching the probe settings of a monitor, or the name of the module
+// generated for it. Kubernetes names can not contain underscores, so the modules of different monitors never collide
+func moduleForMonitor(prefix, namespace, name string, probe v1alpha1.ProbeSpec, insecureSkipTLSVerify bool) string {
+	if probe.Auth != nil || probe.TLS != nil || probe.Expect != nil {
+		return prefix + "_" + namespace + "_" + name
+	}
+	if insecureSkipTLSVerify {
+		return blackboxexporter.InsecureHTTP2xxModule
+	}
+	return blackboxexporter.HTTP2xxModule
+}
+
+// ExporterRef returns the name of the ProbeExporter selected by a RouteMonitor or ClusterUrlMonitor
+func ExporterRef(monitor client.Object) string {
+	switch m := monitor.(type) {
+	case *v1alpha1.RouteMonitor:
+		return m.Spec.ExporterRef
+	case *v1alpha1.ClusterUrlMonitor:
+		return m.Spec.ExporterRef
+	}
+	return ""
+}
+
+// MonitorExporterChanged filters the RouteMonitor and ClusterUrlMonitor updates which can change whether they use
+// a blackbox exporter, or the module they are probed with
+var MonitorExporterChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld.GetDeletionTimestamp().IsZero() != e.ObjectNew.GetDeletionTimestamp().IsZero() {
+			return true
+		}
+		return ExporterRef(e.ObjectOld) != ExporterRef(e.ObjectNew) ||
+			!equality.Semantic.DeepEqual(probeSettings(e.ObjectOld), probeSettings(e.ObjectNew))
+	},
+}
+
+func probeSettings(monitor client.Object) any {
+	switch m := monitor.(type) {
+	case *v1alpha1.RouteMonitor:
+		return []any{m.Spec.Probe, m.Spec.InsecureSkipTLSVerify}
+	case *v1alpha1.ClusterUrlMonitor:
+		return []any{m.Spec.Probe}
+	}
+	return nil
+}
+
+// sharedModules returns the modules used by all monitors which do not need a module of their own
+func sharedModules() map[string]module {
+	return map[string]module{
+		blackboxexporter.HTTP2xxModule: {
+			Prober:  "http",
+			Timeout: probeTimeout,
+		},
+		blackboxexporter.InsecureHTTP2xxModule: {
+			Prober:  "http",
+			Timeout: probeTimeout,
+			HTTP:    &httpProbe{TLSConfig: &tlsConfig{InsecureSkipVerify: true}},
+		},
+	}
+}
+
+// LoadMonitorModules generates the modules of the monitors selecting this blackbox exporter which need a module of their own,
+// and collects the credentials and CA bundles they read from files. Monitors whose credentials can not be resolved are left
+// without a module, so that they do not prevent the other monitors from being probed
+func (b *BlackBoxExporter) LoadMonitorModules() error {
+	routeMonitors, clusterUrlMonitors, err := b.listDependentMonitors()
+	if err != nil {
+		return err
+	}
+
+	modules := sharedModules()
+	credentials := map[string][]byte{}
+	b.usesServiceCA, b.usesTrustedCABundle = false, false
+	for _, routeMonitor := range routeMonitors.Items {
+		// the credentials of a monitor are never sent to Routes outside of its namespace
+		if err := ValidateRouteMonitorProbe(routeMonitor); err != nil {
+			b.Log.V(2).Info("Skipping the module of a monitor with invalid probe settings", "module", ModuleForRouteMonitor(routeMonitor), "error", err.Error())
+			continue
+		}
+		b.addMonitorModule(modules, credentials, ModuleForRouteMonitor(routeMonitor), routeMonitor.Namespace, routeMonitor.Spec.Probe, routeMonitor.Spec.InsecureSkipTLSVerify)
+	}
+	for _, clusterUrlMonitor := range clusterUrlMonitors.Items {
+		b.addMonitorModule(modules, credentials, ModuleForClusterUrlMonitor(clusterUrlMonitor), clusterUrlMonitor.Namespace, clusterUrlMonitor.Spec.Probe, false)
+	}
+	if util.IsProxyConfigured(b.proxy) {
+		for name, m := range modules {
+			if m.HTTP == nil {
+				m.HTTP = &httpProbe{}
+			}
+			m.HTTP.ProxyFromEnvironment = true
+			modules[name] = m
+		}
+	}
+	b.modules = modules
+	b.credentials = credentials
+	return nil
+}
+
+func (b *BlackBoxExporter) addMonitorModule(modules map[string]module, credentials map[string][]byte, name, namespace string, probe v1alpha1.ProbeSpec, insecureSkipTLSVerify bool) {
+	if _, shared := modules[name]; shared {
+		return
+	}
+
+	http := &httpProbe{}
+	if insecureSkipTLSVerify {
+		http.TLSConfig = &tlsConfig{InsecureSkipVerify: true}
+	}
+	moduleCredentials := map[string][]byte{}
+	// unresolved references are reported in the status of the monitor
+	if err := b.addProbeAuth(http, moduleCredentials, name, namespace, probe.Auth); err != nil {
+		b.Log.Info("Failed to resolve the probe credentials of a monitor, skipping its module", "module", name, "error", err.Error())
+		return
+	}
+	if err := b.addProbeTLS(http, moduleCredentials, name, namespace, probe.TLS); err != nil {
+		b.Log.Info("Failed to resolve the probe TLS settings of a monitor, skipping its module", "module", name, "error", err.Error())
+		return
+	}
+	// invalid expectations are reported in the status of the monitor
+	if err := addProbeExpect(http, probe.Expect); err != nil {
+		b.Log.V(2).Info("Skipping the module of a monitor with invalid probe expectations", "module", name, "error", err.Error())
+		return
+	}
+
+	modules[name] = module{Prober: "http", Timeout: probeTimeout, HTTP: http}
+	for key, value := range moduleCredentials {
+		credentials[key] = value
+	}
+}
+
+// ValidateProbeReferences returns an error if the Secrets and ConfigMaps referenced by the probe settings of a monitor
+// in namespace can not be resolved, in which case the blackbox exporter has no module for the monitor
+func (b *BlackBoxExporter) ValidateProbeReferences(namespace string, probe v1alpha1.ProbeSpec) error {
+	// resolve the references with a copy, which leaves the loaded modules and CA bundle usage untouched
+	resolver := &BlackBoxExporter{Ctx: b.Ctx, Client: b.Client, References: b.References}
+	credentials := map[string][]byte{}
+	http := &httpProbe{}
+	if err := resolver.addProbeAuth(http, credentials, "", namespace, probe.Auth); err != nil {
+		return fmt.Errorf("invalid probe.auth: %w", err)
+	}
+	if err := resolver.addProbeTLS(http, credentials, "", namespace, probe.TLS); err != nil {
+		return fmt.Errorf("invalid probe.tls: %w", err)
+	}
+	return nil
+}
+
+// addProbeAuth configures the module to read the credentials referenced by auth from the files of the credentials Secret
+func (b *BlackBoxExporter) addProbeAuth(http *httpProbe, credentials map[string][]byte, name, namespace string, auth *v1alpha1.ProbeAuthSpec) error {
+	if auth == nil {
+		return nil
+	}
+	if (auth.BearerToken == nil) == (auth.BasicAuth == nil) {
+		return fmt.Errorf("exactly one of bearerToken and basicAuth must be set")
+	}
+
+	if auth.BearerToken != nil {
+		token, err := b.getSecretValue(namespace, *auth.BearerToken)
+		if err != nil {
+			return err
+		}
+		key := name + "_bearer_token"
+		credentials[key] = token
+		http.Authorization = &authorization{Type: "Bearer", CredentialsFile: path.Join(blackboxexporter.CredentialsMountPath, key)}
+		return nil
+	}
+
+	username, err := b.getSecretValue(namespace, auth.BasicAuth.Username)
+	if err != nil {
+		return err
+	}
+	password, err := b.getSecretValue(namespace, auth.BasicAuth.Password)
+	if err != nil {
+		return err
+	}
+	usernameKey, passwordKey := name+"_username", name+"_password"
+	credentials[usernameKey] = username
+	credentials[passwordKey] = password
+	http.BasicAuth = &basicAuth{
+		UsernameFile: path.Join(blackboxexporter.CredentialsMountPath, usernameKey),
+		PasswordFile: path.Join(blackboxexporter.CredentialsMountPath, passwordKey),
+	}
+	return nil
+}
+
+// addProbeTLS configures the module to verify the probed endpoint with the CA bundle referenced by tlsSpec, and to
+// present the referenced client certificate
+func (b *BlackBoxExporter) addProbeTLS(http *httpProbe, credentials map[string][]byte, name, namespace string, tlsSpec *v1alpha1.ProbeTLSSpec) error {
+	if tlsSpec == nil {
+		return nil
+	}
+	if http.TLSConfig == nil {
+		http.TLSConfig = &tlsConfig{}
+	}
+
+	if ca := tlsSpec.CA; ca != nil {
+		switch {
+		case ca.ConfigMap != nil:
+			bundle, err := b.getConfigMapValue(namespace, *ca.ConfigMap)
+			if err != nil {
+				return err
+			}
+			key := name + "_ca.crt"
+			credentials[key] = bundle
+			http.TLSConfig.CAFile = path.Join(blackboxexporter.CredentialsMountPath, key)
+		case ca.ServiceCA:
+			http.TLSConfig.CAFile = path.Join(blackboxexporter.ServiceCAMountPath, blackboxexporter.ServiceCAKey)
+			b.usesServiceCA = true
+		case ca.TrustedCABundle:
+			http.TLSConfig.CAFile = path.Join(blackboxexporter.TrustedCABundleMountPath, blackboxexporter.TrustedCABundleKey)
+			b.usesTrustedCABundle = true
+		default:
+			return fmt.Errorf("exactly one of configMap, serviceCA and trustedCABundle must be set")
+		}
+	}
+
+	if cert := tlsSpec.ClientCertificate; cert != nil {
+		certificate, err := b.getSecretValue(namespace, cert.Certificate)
+		if err != nil {
+			return err
+		}
+		key, err := b.getSecretValue(namespace, cert.Key)
+		if err != nil {
+			return err
+		}
+		certificateKey, keyKey := name+"_tls.crt", name+"_tls.key"
+		credentials[certificateKey] = certificate
+		credentials[keyKey] = key
+		http.TLSConfig.CertFile = path.Join(blackboxexporter.CredentialsMountPath, certificateKey)
+		http.TLSConfig.KeyFile = path.Join(blackboxexporter.CredentialsMountPath, keyKey)
+	}
+	return nil
+}
+
+// addProbeExpect configures the module to only succeed on responses matching expect
+func addProbeExpect(http *httpProbe, expect *v1alpha1.ProbeExpectSpec) error {
+	if expect == nil {
+		return nil
+	}
+	if err := validateProbeExpect(*expect); err != nil {
+		return err
+	}
+
+	http.ValidStatusCodes = expect.StatusCodes
+	if expect.BodyRegex != "" {
+		http.FailIfBodyNotMatchesRegexp = []string{expect.BodyRegex}
+	}
+	for _, header := range expect.Headers {
+		http.FailIfHeaderNotMatchesRegexp = append(http.FailIfHeaderNotMatchesRegexp, headerMatch{
+			Header:       header.Name,
+			Regexp:       header.Regex,
+			AllowMissing: header.AllowMissing,
+		})
+	}
+	if expect.JSONPath != nil {
+		expr, err := jsonPathCEL(*expect.JSONPath)
+		if err != nil {
+			return err
+		}
+		http.FailIfBodyJSONNotMatchesCEL = expr
+	}
+	return nil
+}
+
+// ValidateProbe returns an error if the probe settings of a monitor can not be turned into a blackbox exporter module,
+// e.g. for invalid regular expressions
+func ValidateProbe(probe v1alpha1.ProbeSpec) error {
+	if probe.Expect == nil {
+		return nil
+	}
+	return validateProbeExpect(*probe.Expect)
+}
+
+// ValidateRouteMonitorProbe returns an error if the probe settings of a RouteMonitor are invalid. Credentials and client
+// certificates are read from the monitor's namespace, so they are only sent to a Route in the same namespace
+func ValidateRouteMonitorProbe(routeMonitor v1alpha1.RouteMonitor) error {
+	probe := routeMonitor.Spec.Probe
+	if err := ValidateProbe(probe); err != nil {
+		return err
+	}
+	sendsCredentials := probe.Auth != nil || (probe.TLS != nil && probe.TLS.ClientCertificate != nil)
+	if sendsCredentials && routeMonitor.Spec.Route.Namespace != routeMonitor.Namespace {
+		return fmt.Errorf("invalid probe: probe.auth and probe.tls.clientCertificate require the Route to be in the namespace of the RouteMonitor %s, got %s", routeMonitor.Namespace, routeMonitor.Spec.Route.Namespace)
+	}
+	return nil
+}
+
+func validateProbeExpect(expect v1alpha1.ProbeExpectSpec) error {
+	if expect.BodyRegex != "" {
+		if _, err := regexp.Compile(expect.BodyRegex); err != nil {
+			return fmt.Errorf("invalid probe.expect.bodyRegex: %w", err)
+		}
+	}
+	for i, header := range expect.Headers {
+		if header.Name == "" {
+			return fmt.Errorf("invalid probe.expect.headers[%d]: name must not be empty", i)
+		}
+		if _, err := regexp.Compile(header.Regex); err != nil {
+			return fmt.Errorf("invalid probe.expect.headers[%d].regex: %w", i, err)
+		}
+	}
+	for i, code := range expect.StatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("invalid probe.expect.statusCodes[%d]: %d is not an HTTP status code", i, code)
+		}
+	}
+	if expect.JSONPath != nil {
+		if _, err := jsonPathCEL(*expect.JSONPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// jsonPathSegment matches a field name of a JSONPath, optionally followed by array indices
+var jsonPathSegment = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\[[0-9]+\])*$`)
+
+// jsonPathCEL returns the CEL expression the blackbox exporter evaluates on the JSON response body
+// to compare the field at the JSONPath with the expected value
+func jsonPathCEL(expectation v1alpha1.JSONPathExpectation) (string, error) {
+	path := strings.TrimPrefix(strings.TrimPrefix(expectation.Path, "$"), ".")
+	if path == "" {
+		return "", fmt.Errorf("invalid probe.expect.jsonPath.path %q: the path must select a field", expectation.Path)
+	}
+	for _, segment := range strings.Split(path, ".") {
+		if !jsonPathSegment.MatchString(segment) {
+			return "", fmt.Errorf("invalid probe.expect.jsonPath.path %q: only field names and array indices are supported", expectation.Path)
+		}
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(expectation.Value), &value); err != nil {
+		value = expectation.Value
+	}
+	var literal string
+	switch v := value.(type) {
+	case string:
+		literal = strconv.Quote(v)
+	case nil:
+		literal = "null"
+	case bool, float64:
+		literal = strings.TrimSpace(expectation.Value)
+	default:
+		return "", fmt.Errorf("invalid probe.expect.jsonPath.value %q: only strings, numbers, booleans and null can be compared", expectation.Value)
+	}
+	return "body." + path + " == " + literal, nil
+}
+
+// getSecretValue returns the value of a key of a Secret in the monitor's namespace
+func (b *BlackBoxExporter) getSecretValue(namespace string, selector corev1.SecretKeySelector) ([]byte, error) {
+	secret := corev1.Secret{}
+	if err := b.referenceReader().Get(b.Ctx, types.NamespacedName{Name: selector.Name, Namespace: namespace}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get Secret %s/%s: %w", namespace, selector.Name, err)
+	}
+	value, ok := secret.Data[selector.Key]
+	if !ok {
+		return nil, fmt.Errorf("secret %s/%s has no key %q", namespace, selector.Name, selector.Key)
+	}
+	return value, nil
+}
+
+// getConfigMapValue returns the value of a key of a ConfigMap in the monitor's namespace
+func (b *BlackBoxExporter) getConfigMapValue(namespace string, selector corev1.ConfigMapKeySelector) ([]byte, error) {
+	configMap := corev1.ConfigMap{}
+	if err := b.referenceReader().Get(b.Ctx, types.NamespacedName{Name: selector.Name, Namespace: namespace}, &configMap); err != nil {
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, selector.Name, err)
+	}
+	value, ok := configMap.Data[selector.Key]
+	if !ok {
+		return nil, fmt.Errorf("configmap %s/%s has no key %q", namespace, selector.Name, selector.Key)
+	}
+	return []byte(value), nil
+}
+
+// HasCredentials reports whether any module loaded by LoadMonitorModules reads credentials
+func (b *BlackBoxExporter) HasCredentials() bool {
+	return len(b.credentials) > 0
+}
+
+// config returns the configuration file of the blackbox exporter, including the modules loaded by LoadMonitorModules
+func (b *BlackBoxExporter) config() (string, error) {
+	modules := b.modules
+	if modules == nil {
+		modules = sharedModules()
+	}
+	cfg, err := yaml.Marshal(blackboxConfig{Modules: modules})
+	if err != nil {
+		return "", err
+	}
+	return string(cfg), nil
+}
+
+// configHash returns a hash of the configuration of the blackbox exporter. The exporter does not reload its configuration,
+// while the mounted credentials and CA bundles are read on every probe, so only configuration changes need a restart
+func configHash(cfg string) string {
+	hash := sha256.Sum256([]byte(cfg))
+	return hex.EncodeToString(hash[:])
+}
@@ -4,17 +4,66 @@ const ( // All things related BlackBoxExporter
 	BlackBoxExporterName       = "blackbox-exporter"
 	BlackBoxExporterPortName   = "blackbox"
 	BlackBoxExporterPortNumber = 9115
+
+	// ProbeExporterLabelName is set on the resources of a ProbeExporter's blackbox exporter to the ProbeExporter's name
+	ProbeExporterLabelName = "monitoring.openshift.io/probe-exporter"
+
+	// HTTP2xxModule and InsecureHTTP2xxModule are the blackbox exporter modules shared by all monitors
+	// which do not need a module of their own
+	HTTP2xxModule         = "http_2xx"
+	InsecureHTTP2xxModule = "insecure_http_2xx"
+
+	// ConfigHashAnnotationName is set on the pods of a blackbox exporter to the hash of its configuration,
+	// so that the exporter is restarted when the configuration changes
+	ConfigHashAnnotationName = "monitoring.openshift.io/config-hash"
+
+	// CredentialsMountPath is the directory the probe credentials of a blackbox exporter are mounted to
+	CredentialsMountPath = "/credentials"
+
+	// ServiceCAMountPath and TrustedCABundleMountPath are the directories the CA bundles injected into the ConfigMaps
+	// of a blackbox exporter are mounted to
+	ServiceCAMountPath       = "/ca/service-ca"
+	TrustedCABundleMountPath = "/ca/trusted-ca-bundle"
+
+	// ServiceCAKey is the key the service CA operator injects the service CA bundle into
+	ServiceCAKey = "service-ca.crt"
+	// ServiceCAInjectionAnnotationName requests the injection of the service CA bundle into a ConfigMap
+	ServiceCAInjectionAnnotationName = "service.beta.openshift.io/inject-cabundle"
+
+	// TrustedCABundleKey is the key the cluster network operator injects the trusted CA bundle into
+	TrustedCABundleKey = "ca-bundle.crt"
+	// TrustedCABundleInjectionLabelName requests the injection of the cluster's trusted CA bundle into a ConfigMap
+	TrustedCABundleInjectionLabelName = "config.openshift.io/inject-trusted-cabundle"
 )
 
 // generateBlackBoxLables creates a set of common labels to most resources
 // this function is here in case we need more labels in the future
 func GenerateBlackBoxExporterLables() map[string]string {
-	return map[string]string{"app": BlackBoxExporterName}
+	return GenerateLabelsForExporter(BlackBoxExporterName)
+}
+
+// GenerateLabelsForExporter creates the labels selecting the resources of the blackbox exporter with the given name
+func GenerateLabelsForExporter(exporterName string) map[string]string {
+	return map[string]string{"app": exporterName}
 }
 
-type ShouldDeleteBlackBoxExporter bool
+// ProbeExporterResourceName returns the name of the resources of a ProbeExporter's blackbox exporter
+func ProbeExporterResourceName(probeExporterName string) string {
+	return BlackBoxExporterName + "-" + probeExporterName
+}
 
-var (
-	KeepBlackBoxExporter   ShouldDeleteBlackBoxExporter = false
-	DeleteBlackBoxExporter ShouldDeleteBlackBoxExporter = true
-)
+// CredentialsResourceName returns the name of the Secret holding the probe credentials of the blackbox exporter with the given name
+func CredentialsResourceName(exporterName string) string {
+	return exporterName + "-credentials"
+}
+
+// ServiceCAResourceName returns the name of the ConfigMap the service CA bundle is injected into for the blackbox exporter with the given name
+func ServiceCAResourceName(exporterName string) string {
+	return exporterName + "-service-ca"
+}
+
+// TrustedCABundleResourceName returns the name of the ConfigMap the cluster's trusted CA bundle is injected into for the
+// blackbox exporter with the given name
+func TrustedCABundleResourceName(exporterName string) string {
+	return exporterName + "-trusted-ca-bundle"
+}
@@ -0,0 +1,17 @@
+package consts
+
+// Reasons for the Events emitted on RouteMonitors and ClusterUrlMonitors
+const (
+	EventReasonServiceMonitorCreated string = "ServiceMonitorCreated"
+	EventReasonServiceMonitorUpdated string = "ServiceMonitorUpdated"
+	EventReasonPrometheusRuleCreated string = "PrometheusRuleCreated"
+	EventReasonPrometheusRuleUpdated string = "PrometheusRuleUpdated"
+	EventReasonDashboardCreated      string = "DashboardCreated"
+	EventReasonDashboardUpdated      string = "DashboardUpdated"
+	EventReasonRouteURLChanged       string = "RouteURLChanged"
+	EventReasonClusterURLChanged     string = "ClusterURLChanged"
+	EventReasonInvalidSLO            string = "InvalidSLO"
+	EventReasonInvalidAlerting       string = "InvalidAlerting"
+	EventReasonInvalidProbe          string = "InvalidProbe"
+	EventReasonSLOQueryFailed        string = "SLOQueryFailed"
+)
@@ -0,0 +1,238 @@
+package dashboard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	util "github.com/openshift/route-monitor-operator/pkg/reconcile"
+)
+
+const (
+	// TypeConfigMap generates dashboards as ConfigMaps picked up by the Grafana dashboard sidecar
+	TypeConfigMap = "ConfigMap"
+	// TypeGrafanaDashboard generates dashboards as grafana-operator GrafanaDashboard CRs
+	TypeGrafanaDashboard = "GrafanaDashboard"
+
+	// SidecarLabelName and SidecarLabelValue label the ConfigMaps watched by the Grafana dashboard sidecar
+	SidecarLabelName  = "grafana_dashboard"
+	SidecarLabelValue = "1"
+
+	// nameSuffix is appended to the monitor's name to avoid clashing with unrelated ConfigMaps
+	nameSuffix = "-dashboard"
+)
+
+// GrafanaDashboardGVK identifies the grafana-operator's GrafanaDashboard CRD, which is not part of the operator's scheme
+var GrafanaDashboardGVK = schema.GroupVersionKind{Group: "grafana.integreatly.org", Version: "v1beta1", Kind: "GrafanaDashboard"}
+
+// Config configures the generation of Grafana dashboards for monitors
+type Config struct {
+	// Type is either TypeConfigMap or TypeGrafanaDashboard. Dashboards are not generated when empty
+	Type string
+	// InstanceSelector selects the Grafana instances GrafanaDashboard CRs are imported into
+	InstanceSelector map[string]string
+}
+
+// DefaultConfig returns the default dashboard configuration, with dashboard generation disabled
+func DefaultConfig() Config {
+	return Config{
+		InstanceSelector: map[string]string{"dashboards": "grafana"},
+	}
+}
+
+// Validate returns an error if the configuration is invalid
+func (c Config) Validate() error {
+	switch c.Type {
+	case "", TypeConfigMap:
+		return nil
+	case TypeGrafanaDashboard:
+		if len(c.InstanceSelector) == 0 {
+			return fmt.Errorf("an instance selector is required for %s dashboards", TypeGrafanaDashboard)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported dashboard type %q: must be one of '%s' or '%s'", c.Type, TypeConfigMap, TypeGrafanaDashboard)
+	}
+}
+
+// NamespacedNameFor returns the name of the dashboard generated for a monitor
+func NamespacedNameFor(monitor types.NamespacedName) types.NamespacedName {
+	return types.NamespacedName{Name: monitor.Name + nameSuffix, Namespace: monitor.Namespace}
+}
+
+type Dashboard struct {
+	Client   client.Client
+	Ctx      context.Context
+	Config   Config
+	Comparer util.ResourceComparerInterface
+}
+
+func NewDashboard(ctx context.Context, c client.Client, cfg Config) *Dashboard {
+	return &Dashboard{
+		Client:   c,
+		Ctx:      ctx,
+		Config:   cfg,
+		Comparer: &util.ResourceComparer{},
+	}
+}
+
+// Enabled returns whether dashboards are generated for monitors
+func (d *Dashboard) Enabled() bool {
+	return d.Config.Type != ""
+}
+
+// TemplateAndUpdateDashboardDeployment generates the dashboard for the monitor and creates or updates it,
+// reporting which operation was performed
+func (d *Dashboard) TemplateAndUpdateDashboardDeployment(url, percent string, namespacedName types.NamespacedName, owner *metav1.OwnerReference) (controllerutil.OperationResult, error) {
+	dashboardJSON, err := TemplateForDashboardJSON(url, percent, namespacedName)
+	if err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	switch d.Config.Type {
+	case TypeConfigMap:
+		return d.updateConfigMap(TemplateForConfigMap(dashboardJSON, namespacedName, owner))
+	case TypeGrafanaDashboard:
+		return d.updateGrafanaDashboard(TemplateForGrafanaDashboard(dashboardJSON, d.Config.InstanceSelector, namespacedName, owner))
+	default:
+		return controllerutil.OperationResultNone, fmt.Errorf("dashboards are disabled")
+	}
+}
+
+// DeleteDashboardDeployment deletes the dashboard referenced by a namespaced name, regardless of its type
+func (d *Dashboard) DeleteDashboardDeployment(dashboardRef v1alpha1.NamespacedName) error {
+	// nothing to delete, stopping early
+	if dashboardRef == (v1alpha1.NamespacedName{}) {
+		return nil
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: dashboardRef.Name, Namespace: dashboardRef.Namespace}}
+	if err := d.Client.Delete(d.Ctx, configMap); err != nil && !k8serrors.IsNotFound(err) {
+		return err
+	}
+	grafanaDashboard := &unstructured.Unstructured{}
+	grafanaDashboard.SetGroupVersionKind(GrafanaDashboardGVK)
+	grafanaDashboard.SetName(dashboardRef.Name)
+	grafanaDashboard.SetNamespace(dashboardRef.Namespace)
+	// the GrafanaDashboard CRD is only installed alongside the grafana-operator
+	if err := d.Client.Delete(d.Ctx, grafanaDashboard); err != nil && !k8serrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return err
+	}
+	return nil
+}
+
+func (d *Dashboard) updateConfigMap(template *corev1.ConfigMap) (controllerutil.OperationResult, error) {
+	deployed := &corev1.ConfigMap{}
+	err := d.Client.Get(d.Ctx, client.ObjectKeyFromObject(template), deployed)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+		return controllerutil.OperationResultCreated, d.Client.Create(d.Ctx, template)
+	}
+	if err := ensureControlledBySameOwner(deployed, template); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if d.Comparer.DeepEqual(template.Data, deployed.Data) && deployed.Labels[SidecarLabelName] == SidecarLabelValue {
+		return controllerutil.OperationResultNone, nil
+	}
+	deployed.Data = template.Data
+	if deployed.Labels == nil {
+		deployed.Labels = map[string]string{}
+	}
+	deployed.Labels[SidecarLabelName] = SidecarLabelValue
+	return controllerutil.OperationResultUpdated, d.Client.Update(d.Ctx, deployed)
+}
+
+func (d *Dashboard) updateGrafanaDashboard(template *unstructured.Unstructured) (controllerutil.OperationResult, error) {
+	deployed := &unstructured.Unstructured{}
+	deployed.SetGroupVersionKind(GrafanaDashboardGVK)
+	err := d.Client.Get(d.Ctx, client.ObjectKeyFromObject(template), deployed)
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			return controllerutil.OperationResultNone, err
+		}
+		return controllerutil.OperationResultCreated, d.Client.Create(d.Ctx, template)
+	}
+	if err := ensureControlledBySameOwner(deployed, template); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+
+	// only compare the fields set by the template, as the grafana-operator defaults others
+	templateJSON, _, _ := unstructured.NestedString(template.Object, "spec", "json")
+	templateSelector, _, _ := unstructured.NestedStringMap(template.Object, "spec", "instanceSelector", "matchLabels")
+	deployedJSON, _, _ := unstructured.NestedString(deployed.Object, "spec", "json")
+	deployedSelector, _, _ := unstructured.NestedStringMap(deployed.Object, "spec", "instanceSelector", "matchLabels")
+	if templateJSON == deployedJSON && d.Comparer.DeepEqual(templateSelector, deployedSelector) {
+		return controllerutil.OperationResultNone, nil
+	}
+	if err := unstructured.SetNestedField(deployed.Object, templateJSON, "spec", "json"); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	if err := unstructured.SetNestedStringMap(deployed.Object, templateSelector, "spec", "instanceSelector", "matchLabels"); err != nil {
+		return controllerutil.OperationResultNone, err
+	}
+	return controllerutil.OperationResultUpdated, d.Client.Update(d.Ctx, deployed)
+}
+
+// ensureControlledBySameOwner refuses to take over a dashboard which was not generated for the monitor
+func ensureControlledBySameOwner(deployed, template metav1.Object) error {
+	deployedOwner := metav1.GetControllerOf(deployed)
+	templateOwner := metav1.GetControllerOf(template)
+	if templateOwner == nil {
+		return nil
+	}
+	if deployedOwner == nil || deployedOwner.UID != templateOwner.UID {
+		return fmt.Errorf("%s/%s already exists and is not owned by %s %s", deployed.GetNamespace(), deployed.GetName(), templateOwner.Kind, templateOwner.Name)
+	}
+	return nil
+}
+
+// TemplateForConfigMap returns a dashboard ConfigMap labelled for the Grafana dashboard sidecar
+func TemplateForConfigMap(dashboardJSON string, namespacedName types.NamespacedName, owner *metav1.OwnerReference) *corev1.ConfigMap {
+	name := NamespacedNameFor(namespacedName)
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+			Labels: map[string]string{
+				SidecarLabelName: SidecarLabelValue,
+			},
+		},
+		Data: map[string]string{
+			// the sidecar writes all dashboards into the same directory
+			strings.Join([]string{namespacedName.Namespace, namespacedName.Name}, "-") + ".json": dashboardJSON,
+		},
+	}
+	if owner != nil {
+		configMap.OwnerReferences = []metav1.OwnerReference{*owner}
+	}
+	return configMap
+}
+
+// TemplateForGrafanaDashboard returns a GrafanaDashboard CR importing the dashboard into the selected Grafana instances
+func TemplateForGrafanaDashboard(dashboardJSON string, instanceSelector map[string]string, namespacedName types.NamespacedName, owner *metav1.OwnerReference) *unstructured.Unstructured {
+	name := NamespacedNameFor(namespacedName)
+	grafanaDashboard := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"json": dashboardJSON,
+		},
+	}}
+	grafanaDashboard.SetGroupVersionKind(GrafanaDashboardGVK)
+	grafanaDashboard.SetName(name.Name)
+	grafanaDashboard.SetNamespace(name.Namespace)
+	if owner != nil {
+		grafanaDashboard.SetOwnerReferences([]metav1.OwnerReference{*owner})
+	}
+	_ = unstructured.SetNestedStringMap(grafanaDashboard.Object, instanceSelector, "spec", "instanceSelector", "matchLabels")
+	return grafanaDashboard
+}
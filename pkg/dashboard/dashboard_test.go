@@ -0,0 +1,231 @@
+package dashboard
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/alert"
+)
+
+var (
+	testMonitor = types.NamespacedName{Name: "console", Namespace: "openshift-console"}
+	testOwner   = &metav1.OwnerReference{
+		APIVersion: v1alpha1.GroupVersion.String(),
+		Kind:       "RouteMonitor",
+		Name:       testMonitor.Name,
+		UID:        "1234",
+		Controller: func() *bool { b := true; return &b }(),
+	}
+)
+
+func newTestClient(t *testing.T, objs ...client.Object) client.Client {
+	s := runtime.NewScheme()
+	if err := corev1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add corev1 to scheme: %v", err)
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(GrafanaDashboardGVK, meta.RESTScopeNamespace)
+	return fake.NewClientBuilder().WithScheme(s).WithRESTMapper(mapper).WithObjects(objs...).Build()
+}
+
+func TestTemplateForDashboardJSON(t *testing.T) {
+	out, err := TemplateForDashboardJSON("https://console.example.com/health", "0.995", testMonitor)
+	if err != nil {
+		t.Fatalf("failed to template dashboard: %v", err)
+	}
+
+	var dashboard grafanaDashboard
+	if err := json.Unmarshal([]byte(out), &dashboard); err != nil {
+		t.Fatalf("dashboard is not valid JSON: %v", err)
+	}
+	if len(dashboard.UID) > 40 {
+		t.Errorf("expected UID to be at most 40 characters, got %q", dashboard.UID)
+	}
+
+	titles := map[string]panel{}
+	for _, p := range dashboard.Panels {
+		titles[p.Title] = p
+		for _, target := range p.Targets {
+			if strings.Contains(target.Expr, "probe_") && !strings.Contains(target.Expr, `probe_url="https://console.example.com/health"`) {
+				t.Errorf("expected panel %q to select the monitor's probes, got %q", p.Title, target.Expr)
+			}
+		}
+	}
+	for _, title := range []string{"Availability", "Error budget remaining", "Probe success", "Probe latency"} {
+		if _, ok := titles[title]; !ok {
+			t.Errorf("expected a %q panel", title)
+		}
+	}
+	for _, window := range alert.BurnRateWindows() {
+		p, ok := titles["Burn rate "+window.LongWindow+"/"+window.ShortWindow+" ("+window.Severity+")"]
+		if !ok {
+			t.Errorf("expected a burn rate panel for the %s/%s windows", window.LongWindow, window.ShortWindow)
+			continue
+		}
+		if got := p.Targets[2].Expr; got != "vector("+window.BurnRate+")" {
+			t.Errorf("expected the %s/%s burn rate threshold to match the alert, got %q", window.LongWindow, window.ShortWindow, got)
+		}
+	}
+
+	if _, err := TemplateForDashboardJSON("https://console.example.com/health", "not-a-number", testMonitor); err == nil {
+		t.Errorf("expected an error for an invalid SLO target")
+	}
+}
+
+func TestDashboard_TemplateAndUpdateDashboardDeployment_ConfigMap(t *testing.T) {
+	c := newTestClient(t)
+	d := NewDashboard(context.Background(), c, Config{Type: TypeConfigMap})
+
+	result, err := d.TemplateAndUpdateDashboardDeployment("https://console.example.com/health", "0.995", testMonitor, testOwner)
+	if err != nil || result != controllerutil.OperationResultCreated {
+		t.Fatalf("expected dashboard to be created, got %q: %v", result, err)
+	}
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.Background(), NamespacedNameFor(testMonitor), configMap); err != nil {
+		t.Fatalf("failed to get dashboard ConfigMap: %v", err)
+	}
+	if configMap.Labels[SidecarLabelName] != SidecarLabelValue {
+		t.Errorf("expected dashboard ConfigMap to be labelled for the Grafana sidecar, got %v", configMap.Labels)
+	}
+	if owner := metav1.GetControllerOf(configMap); owner == nil || owner.UID != testOwner.UID {
+		t.Errorf("expected dashboard ConfigMap to be owned by the monitor, got %v", owner)
+	}
+
+	result, err = d.TemplateAndUpdateDashboardDeployment("https://console.example.com/health", "0.995", testMonitor, testOwner)
+	if err != nil || result != controllerutil.OperationResultNone {
+		t.Errorf("expected unchanged dashboard to be left alone, got %q: %v", result, err)
+	}
+
+	result, err = d.TemplateAndUpdateDashboardDeployment("https://console.example.com/health", "0.999", testMonitor, testOwner)
+	if err != nil || result != controllerutil.OperationResultUpdated {
+		t.Errorf("expected dashboard to be updated for a new SLO, got %q: %v", result, err)
+	}
+}
+
+func TestDashboard_TemplateAndUpdateDashboardDeployment_foreignConfigMap(t *testing.T) {
+	foreign := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name:      NamespacedNameFor(testMonitor).Name,
+		Namespace: testMonitor.Namespace,
+		Labels:    map[string]string{SidecarLabelName: SidecarLabelValue},
+	}}
+	c := newTestClient(t, foreign)
+	d := NewDashboard(context.Background(), c, Config{Type: TypeConfigMap})
+
+	if _, err := d.TemplateAndUpdateDashboardDeployment("https://console.example.com/health", "0.995", testMonitor, testOwner); err == nil {
+		t.Errorf("expected an error when the ConfigMap is not owned by the monitor")
+	}
+}
+
+func TestDashboard_TemplateAndUpdateDashboardDeployment_GrafanaDashboard(t *testing.T) {
+	c := newTestClient(t)
+	d := NewDashboard(context.Background(), c, Config{Type: TypeGrafanaDashboard, InstanceSelector: map[string]string{"dashboards": "grafana"}})
+
+	result, err := d.TemplateAndUpdateDashboardDeployment("https://console.example.com/health", "0.995", testMonitor, testOwner)
+	if err != nil || result != controllerutil.OperationResultCreated {
+		t.Fatalf("expected dashboard to be created, got %q: %v", result, err)
+	}
+	grafanaDashboard := &unstructured.Unstructured{}
+	grafanaDashboard.SetGroupVersionKind(GrafanaDashboardGVK)
+	if err := c.Get(context.Background(), NamespacedNameFor(testMonitor), grafanaDashboard); err != nil {
+		t.Fatalf("failed to get GrafanaDashboard: %v", err)
+	}
+	selector, _, _ := unstructured.NestedStringMap(grafanaDashboard.Object, "spec", "instanceSelector", "matchLabels")
+	if selector["dashboards"] != "grafana" {
+		t.Errorf("expected GrafanaDashboard to select the configured instances, got %v", selector)
+	}
+
+	// fields defaulted by the grafana-operator must not cause updates
+	if err := unstructured.SetNestedField(grafanaDashboard.Object, "10m", "spec", "resyncPeriod"); err != nil {
+		t.Fatalf("failed to set field: %v", err)
+	}
+	if err := c.Update(context.Background(), grafanaDashboard); err != nil {
+		t.Fatalf("failed to update GrafanaDashboard: %v", err)
+	}
+	result, err = d.TemplateAndUpdateDashboardDeployment("https://console.example.com/health", "0.995", testMonitor, testOwner)
+	if err != nil || result != controllerutil.OperationResultNone {
+		t.Errorf("expected unchanged dashboard to be left alone, got %q: %v", result, err)
+	}
+
+	ref := NamespacedNameFor(testMonitor)
+	if err := d.DeleteDashboardDeployment(v1alpha1.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}); err != nil {
+		t.Fatalf("failed to delete dashboard: %v", err)
+	}
+	if err := c.Get(context.Background(), ref, grafanaDashboard); !k8serrors.IsNotFound(err) {
+		t.Errorf("expected GrafanaDashboard to be deleted, got %v", err)
+	}
+}
+
+func TestDashboard_DeleteDashboardDeployment(t *testing.T) {
+	ref := NamespacedNameFor(testMonitor)
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: ref.Name, Namespace: ref.Namespace}}
+	c := newTestClient(t, configMap)
+	d := NewDashboard(context.Background(), c, DefaultConfig())
+
+	if err := d.DeleteDashboardDeployment(v1alpha1.NamespacedName{}); err != nil {
+		t.Errorf("expected no error without a dashboard reference, got %v", err)
+	}
+	if err := d.DeleteDashboardDeployment(v1alpha1.NamespacedName{Name: ref.Name, Namespace: ref.Namespace}); err != nil {
+		t.Fatalf("failed to delete dashboard: %v", err)
+	}
+	if err := c.Get(context.Background(), ref, &corev1.ConfigMap{}); !k8serrors.IsNotFound(err) {
+		t.Errorf("expected dashboard ConfigMap to be deleted, got %v", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{
+			name:    "disabled by default",
+			cfg:     DefaultConfig(),
+			wantErr: false,
+		},
+		{
+			name:    "ConfigMap dashboards",
+			cfg:     Config{Type: TypeConfigMap},
+			wantErr: false,
+		},
+		{
+			name:    "GrafanaDashboard dashboards",
+			cfg:     Config{Type: TypeGrafanaDashboard, InstanceSelector: map[string]string{"dashboards": "grafana"}},
+			wantErr: false,
+		},
+		{
+			name:    "GrafanaDashboard dashboards without instance selector",
+			cfg:     Config{Type: TypeGrafanaDashboard},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			cfg:     Config{Type: "Perses"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
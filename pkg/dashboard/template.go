@@ -0,0 +1,192 @@
+package dashboard
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/openshift/route-monitor-operator/pkg/alert"
+	"github.com/openshift/route-monitor-operator/pkg/slo"
+)
+
+const (
+	// schemaVersion is the Grafana dashboard JSON model version the dashboards are generated for
+	schemaVersion = 39
+
+	panelWidth  = 12
+	panelHeight = 8
+)
+
+// grafanaDashboard is the subset of the Grafana dashboard JSON model used by the generated dashboards
+type grafanaDashboard struct {
+	UID           string    `json:"uid"`
+	Title         string    `json:"title"`
+	Tags          []string  `json:"tags"`
+	Editable      bool      `json:"editable"`
+	Refresh       string    `json:"refresh"`
+	SchemaVersion int       `json:"schemaVersion"`
+	Time          timeRange `json:"time"`
+	Panels        []panel   `json:"panels"`
+}
+
+type timeRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type panel struct {
+	ID          int         `json:"id"`
+	Title       string      `json:"title"`
+	Description string      `json:"description,omitempty"`
+	Type        string      `json:"type"`
+	GridPos     gridPos     `json:"gridPos"`
+	FieldConfig fieldConfig `json:"fieldConfig"`
+	Targets     []target    `json:"targets"`
+}
+
+type gridPos struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+	W int `json:"w"`
+	H int `json:"h"`
+}
+
+type fieldConfig struct {
+	Defaults fieldDefaults `json:"defaults"`
+}
+
+type fieldDefaults struct {
+	Unit       string      `json:"unit,omitempty"`
+	Decimals   int         `json:"decimals,omitempty"`
+	Thresholds *thresholds `json:"thresholds,omitempty"`
+}
+
+type thresholds struct {
+	Mode  string          `json:"mode"`
+	Steps []thresholdStep `json:"steps"`
+}
+
+type thresholdStep struct {
+	Color string   `json:"color"`
+	Value *float64 `json:"value"`
+}
+
+type target struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat,omitempty"`
+}
+
+// UID returns the Grafana dashboard UID for a monitor, which stays within Grafana's 40 character limit
+func UID(namespacedName types.NamespacedName) string {
+	sum := sha256.Sum256([]byte(namespacedName.String()))
+	return "rmo-" + hex.EncodeToString(sum[:])[:32]
+}
+
+// burnRate returns the rate at which the error budget was consumed over the window
+func burnRate(selector, window, percent string) string {
+	return fmt.Sprintf("(1 - %s) / (1 - %s)", slo.AvailabilityQuery(selector, window), percent)
+}
+
+// TemplateForDashboardJSON returns the Grafana dashboard JSON model showing the availability, error budget,
+// burn rates and latency of the probes for url, where percent is the SLO target ratio (e.g. "0.995")
+func TemplateForDashboardJSON(url, percent string, namespacedName types.NamespacedName) (string, error) {
+	targetRatio, err := strconv.ParseFloat(percent, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid SLO target %q: %w", percent, err)
+	}
+	selector := slo.ProbeSelector(url)
+	zero := 0.0
+
+	panels := []panel{
+		{
+			Title:       "Availability",
+			Description: fmt.Sprintf("Percentage of successful probes of %s over the selected time range", url),
+			Type:        "stat",
+			FieldConfig: fieldConfig{Defaults: fieldDefaults{
+				Unit:     "percentunit",
+				Decimals: 3,
+				Thresholds: &thresholds{Mode: "absolute", Steps: []thresholdStep{
+					{Color: "red", Value: nil},
+					{Color: "green", Value: &targetRatio},
+				}},
+			}},
+			Targets: []target{{RefID: "A", Expr: slo.AvailabilityQuery(selector, "$__range")}},
+		},
+		{
+			Title:       "Error budget remaining",
+			Description: fmt.Sprintf("Percentage of the error budget of the %s%% SLO left over the selected time range", strconv.FormatFloat(targetRatio*100, 'f', -1, 64)),
+			Type:        "stat",
+			FieldConfig: fieldConfig{Defaults: fieldDefaults{
+				Unit:     "percentunit",
+				Decimals: 2,
+				Thresholds: &thresholds{Mode: "absolute", Steps: []thresholdStep{
+					{Color: "red", Value: nil},
+					{Color: "green", Value: &zero},
+				}},
+			}},
+			Targets: []target{{RefID: "A", Expr: fmt.Sprintf("1 - %s", burnRate(selector, "$__range", percent))}},
+		},
+		{
+			Title: "Probe success",
+			Type:  "timeseries",
+			FieldConfig: fieldConfig{Defaults: fieldDefaults{
+				Unit: "percentunit",
+			}},
+			Targets: []target{
+				{RefID: "A", Expr: fmt.Sprintf("avg(avg_over_time(probe_success{%s}[$__rate_interval]))", selector), LegendFormat: "availability"},
+				{RefID: "B", Expr: fmt.Sprintf("vector(%s)", percent), LegendFormat: "SLO target"},
+			},
+		},
+		{
+			Title: "Probe latency",
+			Type:  "timeseries",
+			FieldConfig: fieldConfig{Defaults: fieldDefaults{
+				Unit: "s",
+			}},
+			Targets: []target{
+				{RefID: "A", Expr: fmt.Sprintf("max(probe_duration_seconds{%s})", selector), LegendFormat: "total"},
+				{RefID: "B", Expr: fmt.Sprintf("max by (phase) (probe_http_duration_seconds{%s})", selector), LegendFormat: "{{phase}}"},
+			},
+		},
+	}
+
+	// one panel for each of the ErrorBudgetBurn alerts, showing both of its windows against its threshold
+	for _, window := range alert.BurnRateWindows() {
+		panels = append(panels, panel{
+			Title:       fmt.Sprintf("Burn rate %s/%s (%s)", window.LongWindow, window.ShortWindow, window.Severity),
+			Description: fmt.Sprintf("The %s ErrorBudgetBurn alert fires when the burn rate over both windows exceeds %s", window.Severity, window.BurnRate),
+			Type:        "timeseries",
+			Targets: []target{
+				{RefID: "A", Expr: burnRate(selector, window.LongWindow, percent), LegendFormat: window.LongWindow},
+				{RefID: "B", Expr: burnRate(selector, window.ShortWindow, percent), LegendFormat: window.ShortWindow},
+				{RefID: "C", Expr: fmt.Sprintf("vector(%s)", window.BurnRate), LegendFormat: "threshold"},
+			},
+		})
+	}
+
+	for i := range panels {
+		panels[i].ID = i + 1
+		panels[i].GridPos = gridPos{X: (i % 2) * panelWidth, Y: (i / 2) * panelHeight, W: panelWidth, H: panelHeight}
+	}
+
+	dashboard := grafanaDashboard{
+		UID:           UID(namespacedName),
+		Title:         fmt.Sprintf("Probes / %s / %s", namespacedName.Namespace, namespacedName.Name),
+		Tags:          []string{"route-monitor-operator", namespacedName.Namespace},
+		Editable:      false,
+		Refresh:       "1m",
+		SchemaVersion: schemaVersion,
+		Time:          timeRange{From: "now-7d", To: "now"},
+		Panels:        panels,
+	}
+	out, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
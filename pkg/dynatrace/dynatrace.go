@@ -24,8 +24,11 @@ import (
 	"io"
 	"net/http"
 	"strings"
+	"time"
 
 	hypershiftv1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
+
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
 )
 
 // ------------------------------synthetic-monitoring--------------------------
@@ -43,6 +46,12 @@ func NewDynatraceApiClient(baseURL, apiToken string) *DynatraceApiClient {
 	}
 }
 
+// WithTransport sets the transport of the client's requests, e.g. to send them through the cluster-wide proxy
+func (dynatraceApiClient *DynatraceApiClient) WithTransport(transport http.RoundTripper) *DynatraceApiClient {
+	dynatraceApiClient.httpClient.Transport = transport
+	return dynatraceApiClient
+}
+
 var publicMonitorTemplate = `
 {
     "name": "{{.MonitorName}}",
@@ -151,11 +160,23 @@ func (dynatraceApiClient *DynatraceApiClient) MakeRequest(method, path string, r
 	return dynatraceApiClient.httpClient.Do(req)
 }
 
+// makeInstrumentedRequest makes a Dynatrace api request, recording the request's outcome and latency under the given operation
+func (dynatraceApiClient *DynatraceApiClient) makeInstrumentedRequest(operation, method, path string, renderedJSON string) (*http.Response, error) {
+	start := time.Now()
+	resp, err := dynatraceApiClient.MakeRequest(method, path, renderedJSON)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	metrics.ObserveAPIRequest(metrics.APIDynatrace, operation, start, statusCode, err)
+	return resp, err
+}
+
 func (dynatraceApiClient *DynatraceApiClient) GetDynatraceHttpMonitors(clusterId string) (*ExistsHttpMonitorInDynatraceResponse, error) {
 	var existsHttpMonitorResponse ExistsHttpMonitorInDynatraceResponse
 
 	path := fmt.Sprintf("/synthetic/monitors/?tag=cluster-id:%s", clusterId)
-	resp, err := dynatraceApiClient.MakeRequest(http.MethodGet, path, "")
+	resp, err := dynatraceApiClient.makeInstrumentedRequest("get-monitors", http.MethodGet, path, "")
 	if err != nil {
 		return nil, err
 	}
@@ -174,7 +195,7 @@ func (dynatraceApiClient *DynatraceApiClient) GetDynatraceHttpMonitors(clusterId
 
 func (dynatraceApiClient *DynatraceApiClient) GetLocationEntityIdFromDynatrace(locationName string, locationType hypershiftv1beta1.AWSEndpointAccessType) (string, error) {
 	// Fetch Dynatrace locations using Dynatrace API
-	resp, err := dynatraceApiClient.MakeRequest(http.MethodGet, "/synthetic/locations", "")
+	resp, err := dynatraceApiClient.makeInstrumentedRequest("get-locations", http.MethodGet, "/synthetic/locations", "")
 	if err != nil {
 		return "", err
 	}
@@ -211,7 +232,7 @@ func (dynatraceApiClient *DynatraceApiClient) GetLocationEntityIdFromDynatrace(l
 		return "", err
 	}
 
-	if locationType == hypershiftv1beta1.PublicAndPrivate {
+	if locationType == hypershiftv1beta1.Public || locationType == hypershiftv1beta1.PublicAndPrivate {
 		for _, loc := range locationResponse.Locations {
 			if loc.Name == locationName && loc.Type == "PUBLIC" && loc.CloudPlatform == "AMAZON_EC2" && loc.Status == "ENABLED" {
 				return loc.EntityId, nil
@@ -248,7 +269,7 @@ func (dynatraceApiClient *DynatraceApiClient) CreateDynatraceHttpMonitor(monitor
 	}
 	renderedJSON := tplBuffer.String()
 
-	resp, err := dynatraceApiClient.MakeRequest(http.MethodPost, "/synthetic/monitors", renderedJSON)
+	resp, err := dynatraceApiClient.makeInstrumentedRequest("create-monitor", http.MethodPost, "/synthetic/monitors", renderedJSON)
 	if err != nil {
 		return "", err
 	}
@@ -270,7 +291,7 @@ func (dynatraceApiClient *DynatraceApiClient) CreateDynatraceHttpMonitor(monitor
 
 func (dynatraceApiClient *DynatraceApiClient) DeleteSingleMonitor(monitorId string) error {
 	path := fmt.Sprintf("/synthetic/monitors/%s", monitorId)
-	resp, err := dynatraceApiClient.MakeRequest(http.MethodDelete, path, "")
+	resp, err := dynatraceApiClient.makeInstrumentedRequest("delete-monitor", http.MethodDelete, path, "")
 	if err != nil {
 		return err
 	}
@@ -371,6 +371,15 @@ func TestAPIClient_GetLocationEntityIdFromDynatrace(t *testing.T) {
 			expectId:       "exampleLocationId",
 			expectError:    false,
 		},
+		{
+			name:           "Public location found for Public endpoint access",
+			locationName:   "N. Virginia",
+			locationType:   hypershiftv1beta1.Public,
+			mockResponse:   `{"locations":[{"name":"N. Virginia","entityId":"exampleLocationId","type":"PUBLIC","cloudPlatform":"AMAZON_EC2","status":"ENABLED"}]}`,
+			mockStatusCode: http.StatusOK,
+			expectId:       "exampleLocationId",
+			expectError:    false,
+		},
 		{
 			name:           "Private location found",
 			locationName:   "backplane",
@@ -0,0 +1,111 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+)
+
+const (
+	// inventoryListTimeout bounds the time spent listing monitors while collecting the inventory
+	inventoryListTimeout = 10 * time.Second
+
+	kindRouteMonitor      = "RouteMonitor"
+	kindClusterUrlMonitor = "ClusterUrlMonitor"
+)
+
+var monitorInventoryDesc = prometheus.NewDesc(
+	metricsPrefix+"monitors",
+	"Number of monitors managed by the operator, by kind, namespace and ServiceMonitor type",
+	[]string{"kind", "namespace", "type"},
+	nil,
+)
+
+// monitorInventoryCollector reports the number of RouteMonitors and ClusterUrlMonitors on the cluster each time metrics are scraped
+type monitorInventoryCollector struct {
+	client client.Reader
+	log    logr.Logger
+}
+
+var _ prometheus.Collector = &monitorInventoryCollector{}
+
+// NewMonitorInventoryCollector returns a prometheus.Collector reporting the monitor inventory. The provided client should be backed
+// by the manager's cache, so that scrapes do not result in requests against the kube-apiserver
+func NewMonitorInventoryCollector(c client.Reader, log logr.Logger) prometheus.Collector {
+	return &monitorInventoryCollector{client: c, log: log}
+}
+
+// RegisterMonitorInventoryCollector registers the monitor inventory collector with the controller-runtime metrics registry
+func RegisterMonitorInventoryCollector(c client.Reader, log logr.Logger) error {
+	return ctrlmetrics.Registry.Register(NewMonitorInventoryCollector(c, log))
+}
+
+func (c *monitorInventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- monitorInventoryDesc
+}
+
+func (c *monitorInventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), inventoryListTimeout)
+	defer cancel()
+
+	type inventoryKey struct {
+		kind, namespace, monitorType string
+	}
+	inventory := map[inventoryKey]int{}
+
+	routeMonitors := &v1alpha1.RouteMonitorList{}
+	if err := c.client.List(ctx, routeMonitors); err != nil {
+		c.log.Error(err, "failed to list RouteMonitors for inventory metric")
+	}
+	for _, routeMonitor := range routeMonitors.Items {
+		inventory[inventoryKey{kindRouteMonitor, routeMonitor.Namespace, RouteMonitorType(routeMonitor)}]++
+	}
+
+	clusterUrlMonitors := &v1alpha1.ClusterUrlMonitorList{}
+	if err := c.client.List(ctx, clusterUrlMonitors); err != nil {
+		c.log.Error(err, "failed to list ClusterUrlMonitors for inventory metric")
+	}
+	for _, clusterUrlMonitor := range clusterUrlMonitors.Items {
+		inventory[inventoryKey{kindClusterUrlMonitor, clusterUrlMonitor.Namespace, ClusterUrlMonitorType(clusterUrlMonitor)}]++
+	}
+
+	for key, count := range inventory {
+		ch <- prometheus.MustNewConstMetric(monitorInventoryDesc, prometheus.GaugeValue, float64(count), key.kind, key.namespace, key.monitorType)
+	}
+}
+
+// RouteMonitorType returns the type of ServiceMonitor created for the RouteMonitor
+func RouteMonitorType(routeMonitor v1alpha1.RouteMonitor) string {
+	if routeMonitor.Spec.ServiceMonitorType == v1alpha1.ServiceMonitorTypeRHOBS {
+		return v1alpha1.ServiceMonitorTypeRHOBS
+	}
+	return v1alpha1.ServiceMonitorTypeCoreOS
+}
+
+// ClusterUrlMonitorType returns the type of ServiceMonitor created for the ClusterUrlMonitor
+func ClusterUrlMonitorType(clusterUrlMonitor v1alpha1.ClusterUrlMonitor) string {
+	if clusterUrlMonitor.Spec.DomainRef == v1alpha1.ClusterDomainRefHCP {
+		return v1alpha1.ServiceMonitorTypeRHOBS
+	}
+	return v1alpha1.ServiceMonitorTypeCoreOS
+}
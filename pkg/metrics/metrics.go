@@ -0,0 +1,151 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics defines the operator-specific Prometheus metrics exposed alongside the default controller-runtime metrics.
+//
+// These metrics are intended to help distinguish between probes which are missing because the operator failed to create them,
+// and probes which exist but are failing because of their target
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// metricsPrefix is prepended to all metrics defined by the operator
+	metricsPrefix = "route_monitor_operator_"
+
+	// APIRHOBS identifies requests made to the RHOBS synthetics API
+	APIRHOBS = "rhobs"
+	// APIDynatrace identifies requests made to the Dynatrace API
+	APIDynatrace = "dynatrace"
+	// APIPrometheus identifies queries made to Prometheus to report the SLO status of monitors
+	APIPrometheus = "prometheus"
+
+	// statusCodeError is used as the status_code label for requests which did not receive a response
+	statusCodeError = "error"
+)
+
+var (
+	// ReconcileStepFailures counts the failures of each step of a controller's reconcile loop
+	ReconcileStepFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricsPrefix + "reconcile_step_failures_total",
+			Help: "Number of times a step of a controller's reconcile loop returned an error",
+		},
+		[]string{"controller", "step"},
+	)
+
+	// APIRequests counts the requests made to external APIs
+	APIRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricsPrefix + "api_requests_total",
+			Help: "Number of requests made to external APIs, by API, operation and response status code",
+		},
+		[]string{"api", "operation", "status_code"},
+	)
+
+	// APIRequestDuration observes the latency of requests made to external APIs
+	APIRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    metricsPrefix + "api_request_duration_seconds",
+			Help:    "Latency of requests made to external APIs, by API and operation",
+			Buckets: prometheus.DefBuckets,
+		},
+		[]string{"api", "operation"},
+	)
+
+	// OIDCTokenRefreshes counts the attempts to obtain a new OIDC access token
+	OIDCTokenRefreshes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: metricsPrefix + "oidc_token_refreshes_total",
+			Help: "Number of attempts to obtain a new OIDC access token, by result",
+		},
+		[]string{"result"},
+	)
+
+	// HCPHealthGatePassed reports whether a HostedControlPlane has passed the healthchecks required before it is monitored
+	HCPHealthGatePassed = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: metricsPrefix + "hcp_health_gate_passed",
+			Help: "Whether a HostedControlPlane has passed the healthchecks required before its monitoring objects are deployed (1) or not (0)",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// HCPHealthGateSuccesses reports the consecutive successful healthchecks recorded for a HostedControlPlane
+	HCPHealthGateSuccesses = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: metricsPrefix + "hcp_health_gate_consecutive_successes",
+			Help: "Number of consecutive successful healthchecks recorded for a HostedControlPlane",
+		},
+		[]string{"namespace", "name"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(
+		ReconcileStepFailures,
+		APIRequests,
+		APIRequestDuration,
+		OIDCTokenRefreshes,
+		HCPHealthGatePassed,
+		HCPHealthGateSuccesses,
+	)
+}
+
+// RecordReconcileStepFailure records a failure of the given step in the given controller's reconcile loop
+func RecordReconcileStepFailure(controller, step string) {
+	ReconcileStepFailures.WithLabelValues(controller, step).Inc()
+}
+
+// ObserveAPIRequest records a request made to an external API which started at the given time. A non-nil error indicates
+// no response was received, in which case statusCode is ignored
+func ObserveAPIRequest(api, operation string, start time.Time, statusCode int, err error) {
+	code := strconv.Itoa(statusCode)
+	if err != nil {
+		code = statusCodeError
+	}
+	APIRequests.WithLabelValues(api, operation, code).Inc()
+	APIRequestDuration.WithLabelValues(api, operation).Observe(time.Since(start).Seconds())
+}
+
+// RecordOIDCTokenRefresh records an attempt to obtain a new OIDC access token
+func RecordOIDCTokenRefresh(err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	OIDCTokenRefreshes.WithLabelValues(result).Inc()
+}
+
+// SetHCPHealthGate records the healthchecking state of a HostedControlPlane
+func SetHCPHealthGate(namespace, name string, successes int, passed bool) {
+	value := 0.0
+	if passed {
+		value = 1.0
+	}
+	HCPHealthGatePassed.WithLabelValues(namespace, name).Set(value)
+	HCPHealthGateSuccesses.WithLabelValues(namespace, name).Set(float64(successes))
+}
+
+// DeleteHCPHealthGate removes the healthchecking state of a HostedControlPlane, once it no longer exists
+func DeleteHCPHealthGate(namespace, name string) {
+	HCPHealthGatePassed.DeleteLabelValues(namespace, name)
+	HCPHealthGateSuccesses.DeleteLabelValues(namespace, name)
+}
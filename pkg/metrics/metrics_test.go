@@ -0,0 +1,129 @@
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr/testr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+)
+
+func TestObserveAPIRequest(t *testing.T) {
+	tests := []struct {
+		name       string
+		operation  string
+		statusCode int
+		err        error
+		wantCode   string
+	}{
+		{
+			name:       "response received",
+			operation:  "test-success",
+			statusCode: 200,
+			wantCode:   "200",
+		},
+		{
+			name:       "non-200 response received",
+			operation:  "test-non-200",
+			statusCode: 503,
+			wantCode:   "503",
+		},
+		{
+			name:       "no response received",
+			operation:  "test-error",
+			statusCode: 0,
+			err:        errors.New("connection refused"),
+			wantCode:   statusCodeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ObserveAPIRequest(APIRHOBS, tt.operation, time.Now(), tt.statusCode, tt.err)
+			if got := testutil.ToFloat64(APIRequests.WithLabelValues(APIRHOBS, tt.operation, tt.wantCode)); got != 1 {
+				t.Errorf("expected 1 request with status code %q, got %v", tt.wantCode, got)
+			}
+		})
+	}
+}
+
+func TestRecordOIDCTokenRefresh(t *testing.T) {
+	success := testutil.ToFloat64(OIDCTokenRefreshes.WithLabelValues("success"))
+	failure := testutil.ToFloat64(OIDCTokenRefreshes.WithLabelValues("failure"))
+
+	RecordOIDCTokenRefresh(nil)
+	RecordOIDCTokenRefresh(errors.New("invalid_client"))
+	RecordOIDCTokenRefresh(errors.New("invalid_client"))
+
+	if got := testutil.ToFloat64(OIDCTokenRefreshes.WithLabelValues("success")) - success; got != 1 {
+		t.Errorf("expected 1 successful refresh, got %v", got)
+	}
+	if got := testutil.ToFloat64(OIDCTokenRefreshes.WithLabelValues("failure")) - failure; got != 2 {
+		t.Errorf("expected 2 failed refreshes, got %v", got)
+	}
+}
+
+func TestHCPHealthGate(t *testing.T) {
+	SetHCPHealthGate("ns", "in-progress", 2, false)
+	SetHCPHealthGate("ns", "passed", 5, true)
+
+	if got := testutil.ToFloat64(HCPHealthGatePassed.WithLabelValues("ns", "in-progress")); got != 0 {
+		t.Errorf("expected in-progress HostedControlPlane to not have passed, got %v", got)
+	}
+	if got := testutil.ToFloat64(HCPHealthGateSuccesses.WithLabelValues("ns", "in-progress")); got != 2 {
+		t.Errorf("expected 2 successes, got %v", got)
+	}
+	if got := testutil.ToFloat64(HCPHealthGatePassed.WithLabelValues("ns", "passed")); got != 1 {
+		t.Errorf("expected passed HostedControlPlane to have passed, got %v", got)
+	}
+
+	DeleteHCPHealthGate("ns", "passed")
+	if HCPHealthGatePassed.DeleteLabelValues("ns", "passed") {
+		t.Errorf("expected health gate series to have been deleted")
+	}
+	if !HCPHealthGatePassed.DeleteLabelValues("ns", "in-progress") {
+		t.Errorf("expected other HostedControlPlanes' series to be kept")
+	}
+}
+
+func TestMonitorInventoryCollector(t *testing.T) {
+	s := runtime.NewScheme()
+	if err := v1alpha1.AddToScheme(s); err != nil {
+		t.Fatalf("failed to add v1alpha1 to scheme: %v", err)
+	}
+	c := fake.NewClientBuilder().WithScheme(s).WithObjects(
+		&v1alpha1.RouteMonitor{ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "a"}},
+		&v1alpha1.RouteMonitor{ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "a"}},
+		&v1alpha1.RouteMonitor{
+			ObjectMeta: metav1.ObjectMeta{Name: "rhobs", Namespace: "b"},
+			Spec:       v1alpha1.RouteMonitorSpec{ServiceMonitorType: v1alpha1.ServiceMonitorTypeRHOBS},
+		},
+		&v1alpha1.ClusterUrlMonitor{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "hcp"},
+			Spec:       v1alpha1.ClusterUrlMonitorSpec{DomainRef: v1alpha1.ClusterDomainRefHCP},
+		},
+	).Build()
+
+	collector := NewMonitorInventoryCollector(c, testr.New(t))
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(collector)
+
+	expected := `
+# HELP route_monitor_operator_monitors Number of monitors managed by the operator, by kind, namespace and ServiceMonitor type
+# TYPE route_monitor_operator_monitors gauge
+route_monitor_operator_monitors{kind="ClusterUrlMonitor",namespace="hcp",type="monitoring.rhobs"} 1
+route_monitor_operator_monitors{kind="RouteMonitor",namespace="a",type="monitoring.coreos.com"} 2
+route_monitor_operator_monitors{kind="RouteMonitor",namespace="b",type="monitoring.rhobs"} 1
+`
+	if err := testutil.GatherAndCompare(registry, strings.NewReader(expected)); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,381 @@
+package openslo
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/inf.v0"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/servicemonitor"
+)
+
+const (
+	// annotationPrefix prefixes the annotations which record the exported monitor on its SLO
+	annotationPrefix = "route-monitor-operator.openshift.io/"
+	// KindAnnotation records the kind of the exported monitor
+	KindAnnotation = annotationPrefix + "kind"
+	// NameAnnotation records the name of the exported monitor
+	NameAnnotation = annotationPrefix + "name"
+	// NamespaceAnnotation records the namespace of the exported monitor
+	NamespaceAnnotation = annotationPrefix + "namespace"
+	// SpecAnnotation records the spec of the exported monitor, without its SLO, as JSON
+	SpecAnnotation = annotationPrefix + "spec"
+
+	KindRouteMonitor      = "RouteMonitor"
+	KindClusterUrlMonitor = "ClusterUrlMonitor"
+
+	// MetricSourcePrometheus is the only metric source SLIs can be imported from
+	MetricSourcePrometheus = "Prometheus"
+	// BudgetingMethodOccurrences counts each probe as an event
+	BudgetingMethodOccurrences = "Occurrences"
+
+	probeSuccessMetric = "probe_success"
+)
+
+// probeSuccessRatio matches the queries of the good and total events of a probe_success ratio SLI
+var probeSuccessRatio = regexp.MustCompile(`\b` + probeSuccessMetric + `\b`)
+
+// probeURLMatcher matches the quoted probed URL in a probe_success{probe_url="..."} selector
+var probeURLMatcher = regexp.MustCompile(`\b` + probeSuccessMetric + `\{[^}]*\b` + servicemonitor.UrlLabelName + `\s*=\s*("(?:[^"\\]|\\.)*")`)
+
+// Export returns the OpenSLO SLO and SLI documents describing the availability objective of a RouteMonitor
+// or ClusterUrlMonitor, where url is the monitor's probed URL and window the SLO's rolling time window
+func Export(monitor client.Object, url, window string) (*SLO, *SLI, error) {
+	var kind string
+	var sloSpec v1alpha1.SloSpec
+	var spec map[string]any
+	var err error
+	switch m := monitor.(type) {
+	case *v1alpha1.RouteMonitor:
+		kind, sloSpec = KindRouteMonitor, m.Spec.Slo
+		spec, err = specWithoutSLO(m.Spec)
+	case *v1alpha1.ClusterUrlMonitor:
+		kind, sloSpec = KindClusterUrlMonitor, m.Spec.Slo
+		spec, err = specWithoutSLO(m.Spec)
+	default:
+		return nil, nil, fmt.Errorf("unsupported monitor type %T", monitor)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	specJSON, err := json.Marshal(spec)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	valid, ratio := sloSpec.IsValid()
+	if !valid {
+		return nil, nil, fmt.Errorf("%s %s/%s has no valid SLO", kind, monitor.GetNamespace(), monitor.GetName())
+	}
+	target, err := strconv.ParseFloat(ratio, 64)
+	if err != nil {
+		return nil, nil, err
+	}
+	if url == "" {
+		return nil, nil, fmt.Errorf("the URL probed for %s %s/%s is unknown", kind, monitor.GetNamespace(), monitor.GetName())
+	}
+
+	name := monitor.GetNamespace() + "-" + monitor.GetName()
+	selector := fmt.Sprintf("%s{%s=%s}", probeSuccessMetric, servicemonitor.UrlLabelName, strconv.Quote(url))
+	sli := &SLI{
+		TypeMeta: TypeMeta{APIVersion: APIVersion, Kind: KindSLI},
+		Metadata: Metadata{
+			Name:        name + "-availability",
+			DisplayName: fmt.Sprintf("Availability of %s", url),
+		},
+		Spec: SLISpec{
+			Description: fmt.Sprintf("Ratio of successful blackbox exporter probes of %s", url),
+			RatioMetric: &RatioMetric{
+				Counter: false,
+				Good:    prometheusQuery(fmt.Sprintf("sum(%s)", selector)),
+				Total:   prometheusQuery(fmt.Sprintf("count(%s)", selector)),
+			},
+		},
+	}
+	slo := &SLO{
+		TypeMeta: TypeMeta{APIVersion: APIVersion, Kind: KindSLO},
+		Metadata: Metadata{
+			Name:        name,
+			DisplayName: fmt.Sprintf("%s %s/%s", kind, monitor.GetNamespace(), monitor.GetName()),
+			Annotations: map[string]string{
+				KindAnnotation:      kind,
+				NameAnnotation:      monitor.GetName(),
+				NamespaceAnnotation: monitor.GetNamespace(),
+				SpecAnnotation:      string(specJSON),
+			},
+		},
+		Spec: SLOSpec{
+			Description:     fmt.Sprintf("Availability of %s as observed by the route-monitor-operator", url),
+			Service:         monitor.GetNamespace(),
+			IndicatorRef:    sli.Name,
+			TimeWindow:      []TimeWindow{{Duration: window, IsRolling: true}},
+			BudgetingMethod: BudgetingMethodOccurrences,
+			Objectives:      []SLOObjective{{DisplayName: "availability", Target: &target}},
+		},
+	}
+	return slo, sli, nil
+}
+
+// specWithoutSLO returns a monitor's spec as generic map, without its SLO which is exported as objective
+func specWithoutSLO(spec any) (map[string]any, error) {
+	raw, err := json.Marshal(spec)
+	if err != nil {
+		return nil, err
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, err
+	}
+	delete(out, "slo")
+	return out, nil
+}
+
+func prometheusQuery(query string) *MetricQuery {
+	return &MetricQuery{MetricSource: MetricSource{Type: MetricSourcePrometheus, Spec: MetricSourceQuery{Query: query}}}
+}
+
+// Import returns the RouteMonitor or ClusterUrlMonitor described by an SLO.
+// sli must be the SLI referenced by the SLO, unless it is defined inline. Monitors without a recorded
+// namespace are created in defaultNamespace. SLOs exported by Export are imported from the monitor recorded
+// in their annotations, other SLOs as a ClusterUrlMonitor of the URL selected by the SLI, which must be
+// within clusterDomain
+func Import(slo SLO, sli *SLI, defaultNamespace, clusterDomain string) (client.Object, error) {
+	if slo.APIVersion != APIVersion || slo.Kind != KindSLO {
+		return nil, fmt.Errorf("%s is not an %s %s", slo.Name, APIVersion, KindSLO)
+	}
+	indicator := slo.Spec.Indicator
+	if indicator == nil {
+		if sli == nil || sli.Name != slo.Spec.IndicatorRef {
+			return nil, fmt.Errorf("SLO %s: SLI %q not found", slo.Name, slo.Spec.IndicatorRef)
+		}
+		indicator = &InlineSLI{Metadata: sli.Metadata, Spec: sli.Spec}
+	}
+	if err := validateProbeSuccessRatio(indicator.Spec); err != nil {
+		return nil, fmt.Errorf("SLO %s: SLI %s: %w", slo.Name, indicator.Name, err)
+	}
+	sloSpec, err := importObjective(slo.Spec.Objectives)
+	if err != nil {
+		return nil, fmt.Errorf("SLO %s: %w", slo.Name, err)
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		Name:      slo.Annotations[NameAnnotation],
+		Namespace: slo.Annotations[NamespaceAnnotation],
+	}
+	if objectMeta.Name == "" {
+		objectMeta.Name = slo.Name
+	}
+	if objectMeta.Namespace == "" {
+		objectMeta.Namespace = defaultNamespace
+	}
+	spec, ok := slo.Annotations[SpecAnnotation]
+	if !ok {
+		probeURL, err := selectedProbeURL(indicator.Spec)
+		if err != nil {
+			return nil, fmt.Errorf("SLO %s: SLI %s: %w", slo.Name, indicator.Name, err)
+		}
+		clusterUrlMonitorSpec, err := clusterUrlMonitorSpecFor(probeURL, clusterDomain)
+		if err != nil {
+			return nil, fmt.Errorf("SLO %s: %w", slo.Name, err)
+		}
+		clusterUrlMonitorSpec.Slo = sloSpec
+		return &v1alpha1.ClusterUrlMonitor{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: KindClusterUrlMonitor},
+			ObjectMeta: objectMeta,
+			Spec:       clusterUrlMonitorSpec,
+		}, nil
+	}
+
+	switch slo.Annotations[KindAnnotation] {
+	case KindRouteMonitor:
+		routeMonitor := &v1alpha1.RouteMonitor{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: KindRouteMonitor},
+			ObjectMeta: objectMeta,
+		}
+		if err := json.Unmarshal([]byte(spec), &routeMonitor.Spec); err != nil {
+			return nil, fmt.Errorf("SLO %s: invalid %s annotation: %w", slo.Name, SpecAnnotation, err)
+		}
+		routeMonitor.Spec.Slo = sloSpec
+		return routeMonitor, nil
+	case KindClusterUrlMonitor:
+		clusterUrlMonitor := &v1alpha1.ClusterUrlMonitor{
+			TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: KindClusterUrlMonitor},
+			ObjectMeta: objectMeta,
+		}
+		if err := json.Unmarshal([]byte(spec), &clusterUrlMonitor.Spec); err != nil {
+			return nil, fmt.Errorf("SLO %s: invalid %s annotation: %w", slo.Name, SpecAnnotation, err)
+		}
+		clusterUrlMonitor.Spec.Slo = sloSpec
+		return clusterUrlMonitor, nil
+	default:
+		return nil, fmt.Errorf("SLO %s: %s annotation must be one of '%s' or '%s', got %q", slo.Name, KindAnnotation, KindRouteMonitor, KindClusterUrlMonitor, slo.Annotations[KindAnnotation])
+	}
+}
+
+// validateProbeSuccessRatio returns an error unless the SLI is the ratio of successful probes queried from Prometheus
+func validateProbeSuccessRatio(spec SLISpec) error {
+	ratio := spec.RatioMetric
+	if ratio == nil || ratio.Good == nil || ratio.Total == nil {
+		return errors.New("only ratio metrics with good and total queries are supported")
+	}
+	for _, query := range []*MetricQuery{ratio.Good, ratio.Total} {
+		if !strings.EqualFold(query.MetricSource.Type, MetricSourcePrometheus) {
+			return fmt.Errorf("unsupported metric source %q: must be %s", query.MetricSource.Type, MetricSourcePrometheus)
+		}
+		if !probeSuccessRatio.MatchString(query.MetricSource.Spec.Query) {
+			return fmt.Errorf("query %q does not use the %s metric", query.MetricSource.Spec.Query, probeSuccessMetric)
+		}
+	}
+	return nil
+}
+
+// selectedProbeURL returns the URL selected by the probe_url label of both queries of a probe_success ratio SLI
+func selectedProbeURL(spec SLISpec) (string, error) {
+	probeURL := ""
+	for _, query := range []*MetricQuery{spec.RatioMetric.Good, spec.RatioMetric.Total} {
+		match := probeURLMatcher.FindStringSubmatch(query.MetricSource.Spec.Query)
+		if match == nil {
+			return "", fmt.Errorf("query %q does not select a %s", query.MetricSource.Spec.Query, servicemonitor.UrlLabelName)
+		}
+		selected, err := strconv.Unquote(match[1])
+		if err != nil {
+			return "", fmt.Errorf("query %q: invalid %s: %w", query.MetricSource.Spec.Query, servicemonitor.UrlLabelName, err)
+		}
+		if probeURL != "" && probeURL != selected {
+			return "", fmt.Errorf("the good and total queries select different URLs: %q and %q", probeURL, selected)
+		}
+		probeURL = selected
+	}
+	return probeURL, nil
+}
+
+// clusterUrlMonitorSpecFor returns the spec of the ClusterUrlMonitor probing probeURL, which is split into the
+// prefix, clusterDomain, port and suffix. The port defaults to the one of the URL's scheme
+func clusterUrlMonitorSpecFor(probeURL, clusterDomain string) (v1alpha1.ClusterUrlMonitorSpec, error) {
+	if clusterDomain == "" {
+		return v1alpha1.ClusterUrlMonitorSpec{}, fmt.Errorf("the cluster domain is required to import SLOs without the %s annotation", SpecAnnotation)
+	}
+	u, err := url.Parse(probeURL)
+	if err != nil || u.Host == "" || u.User != nil {
+		return v1alpha1.ClusterUrlMonitorSpec{}, fmt.Errorf("%s %q is not an absolute URL without credentials", servicemonitor.UrlLabelName, probeURL)
+	}
+	host := u.Hostname()
+	if !strings.HasSuffix(host, "."+clusterDomain) {
+		return v1alpha1.ClusterUrlMonitorSpec{}, fmt.Errorf("%s is not within the cluster domain %s", probeURL, clusterDomain)
+	}
+	port := u.Port()
+	if port == "" {
+		switch u.Scheme {
+		case "http":
+			port = "80"
+		case "https":
+			port = "443"
+		default:
+			return v1alpha1.ClusterUrlMonitorSpec{}, fmt.Errorf("%s has neither a port nor an http(s) scheme", probeURL)
+		}
+	}
+	return v1alpha1.ClusterUrlMonitorSpec{
+		Prefix:    u.Scheme + "://" + strings.TrimSuffix(host, clusterDomain),
+		Port:      port,
+		Suffix:    strings.TrimPrefix(probeURL, u.Scheme+"://"+u.Host),
+		DomainRef: v1alpha1.ClusterDomainRefInfra,
+	}, nil
+}
+
+// importObjective converts the SLO's single objective into the monitor's SloSpec
+func importObjective(objectives []SLOObjective) (v1alpha1.SloSpec, error) {
+	if len(objectives) != 1 {
+		return v1alpha1.SloSpec{}, fmt.Errorf("expected exactly one objective, got %d", len(objectives))
+	}
+	percent := new(inf.Dec)
+	switch objective := objectives[0]; {
+	case objective.Target != nil:
+		if _, ok := percent.SetString(strconv.FormatFloat(*objective.Target, 'f', -1, 64)); !ok {
+			return v1alpha1.SloSpec{}, fmt.Errorf("invalid target %v", *objective.Target)
+		}
+		percent.Mul(percent, inf.NewDec(100, 0))
+	case objective.TargetPercent != nil:
+		if _, ok := percent.SetString(strconv.FormatFloat(*objective.TargetPercent, 'f', -1, 64)); !ok {
+			return v1alpha1.SloSpec{}, fmt.Errorf("invalid target percent %v", *objective.TargetPercent)
+		}
+	default:
+		return v1alpha1.SloSpec{}, errors.New("objective has no target")
+	}
+
+	sloSpec := v1alpha1.SloSpec{TargetAvailabilityPercent: trimTrailingZeros(percent.String())}
+	if valid, _ := sloSpec.IsValid(); !valid {
+		return v1alpha1.SloSpec{}, fmt.Errorf("target availability of %s%% is not supported: must be between 90%% and 100%%", sloSpec.TargetAvailabilityPercent)
+	}
+	return sloSpec, nil
+}
+
+func trimTrailingZeros(decimal string) string {
+	if !strings.Contains(decimal, ".") {
+		return decimal
+	}
+	return strings.TrimSuffix(strings.TrimRight(decimal, "0"), ".")
+}
+
+// Marshal encodes OpenSLO documents as multi-document YAML
+func Marshal(docs []Document) ([]byte, error) {
+	out := bytes.Buffer{}
+	for _, doc := range docs {
+		raw, err := yaml.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s %s: %w", doc.GetKind(), doc.GetName(), err)
+		}
+		out.WriteString("---\n")
+		out.Write(raw)
+	}
+	return out.Bytes(), nil
+}
+
+// Unmarshal decodes the SLO and SLI documents from multi-document YAML, skipping documents of other kinds
+func Unmarshal(r io.Reader) ([]SLO, map[string]SLI, error) {
+	slos := []SLO{}
+	slis := map[string]SLI{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		raw, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return slos, slis, nil
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read document: %w", err)
+		}
+		typeMeta := TypeMeta{}
+		if err := yaml.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+		if typeMeta.APIVersion != APIVersion {
+			continue
+		}
+		switch typeMeta.Kind {
+		case KindSLO:
+			slo := SLO{}
+			if err := yaml.Unmarshal(raw, &slo); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse %s: %w", KindSLO, err)
+			}
+			slos = append(slos, slo)
+		case KindSLI:
+			sli := SLI{}
+			if err := yaml.Unmarshal(raw, &sli); err != nil {
+				return nil, nil, fmt.Errorf("failed to parse %s: %w", KindSLI, err)
+			}
+			slis[sli.Name] = sli
+		}
+	}
+}
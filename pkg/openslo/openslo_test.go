@@ -0,0 +1,343 @@
+package openslo
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+)
+
+var (
+	testRouteMonitor = &v1alpha1.RouteMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"},
+		Spec: v1alpha1.RouteMonitorSpec{
+			Route: v1alpha1.RouteMonitorRouteSpec{
+				Name:      "console",
+				Namespace: "openshift-console",
+				Suffix:    "/health",
+			},
+			Slo:                v1alpha1.SloSpec{TargetAvailabilityPercent: "99.5"},
+			ServiceMonitorType: v1alpha1.ServiceMonitorTypeCoreOS,
+		},
+	}
+	testClusterUrlMonitor = &v1alpha1.ClusterUrlMonitor{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "openshift-route-monitor-operator"},
+		Spec: v1alpha1.ClusterUrlMonitorSpec{
+			Prefix:             "api.",
+			Port:               "6443",
+			Suffix:             "/livez",
+			Slo:                v1alpha1.SloSpec{TargetAvailabilityPercent: "99.95"},
+			DomainRef:          v1alpha1.ClusterDomainRefInfra,
+			SkipPrometheusRule: true,
+		},
+	}
+)
+
+func TestExport(t *testing.T) {
+	slo, sli, err := Export(testRouteMonitor, "https://console.example.com/health", "28d")
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if slo.Name != "openshift-console-console" || slo.Spec.IndicatorRef != sli.Name {
+		t.Errorf("expected SLO openshift-console-console referencing its SLI, got %s referencing %s", slo.Name, slo.Spec.IndicatorRef)
+	}
+	if len(slo.Spec.Objectives) != 1 || *slo.Spec.Objectives[0].Target != 0.995 {
+		t.Errorf("expected a single objective with target 0.995, got %+v", slo.Spec.Objectives)
+	}
+	if slo.Spec.TimeWindow[0].Duration != "28d" || !slo.Spec.TimeWindow[0].IsRolling {
+		t.Errorf("expected a rolling 28d time window, got %+v", slo.Spec.TimeWindow)
+	}
+	if strings.Contains(slo.Annotations[SpecAnnotation], `"slo"`) {
+		t.Errorf("expected the SLO to be left out of the spec annotation, got %s", slo.Annotations[SpecAnnotation])
+	}
+	wantGood := `sum(probe_success{probe_url="https://console.example.com/health"})`
+	wantTotal := `count(probe_success{probe_url="https://console.example.com/health"})`
+	if got := sli.Spec.RatioMetric.Good.MetricSource.Spec.Query; got != wantGood {
+		t.Errorf("expected good query %q, got %q", wantGood, got)
+	}
+	if got := sli.Spec.RatioMetric.Total.MetricSource.Spec.Query; got != wantTotal {
+		t.Errorf("expected total query %q, got %q", wantTotal, got)
+	}
+
+	invalid := testRouteMonitor.DeepCopy()
+	invalid.Spec.Slo = v1alpha1.SloSpec{}
+	if _, _, err := Export(invalid, "https://console.example.com/health", "28d"); err == nil {
+		t.Errorf("expected an error for a monitor without SLO")
+	}
+	if _, _, err := Export(testRouteMonitor, "", "28d"); err == nil {
+		t.Errorf("expected an error for a monitor without URL")
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		monitor client.Object
+		url     string
+	}{
+		{
+			name:    "RouteMonitor",
+			monitor: testRouteMonitor,
+			url:     "https://console.example.com/health",
+		},
+		{
+			name:    "ClusterUrlMonitor",
+			monitor: testClusterUrlMonitor,
+			url:     "https://api.example.com:6443/livez",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slo, sli, err := Export(tt.monitor, tt.url, "28d")
+			if err != nil {
+				t.Fatalf("failed to export: %v", err)
+			}
+			raw, err := Marshal([]Document{slo, sli})
+			if err != nil {
+				t.Fatalf("failed to marshal: %v", err)
+			}
+			slos, slis, err := Unmarshal(bytes.NewReader(raw))
+			if err != nil {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			if len(slos) != 1 || len(slis) != 1 {
+				t.Fatalf("expected one SLO and one SLI, got %d and %d", len(slos), len(slis))
+			}
+			if !reflect.DeepEqual(slos[0], *slo) {
+				t.Errorf("expected SLO %+v, got %+v", *slo, slos[0])
+			}
+			referenced := slis[slos[0].Spec.IndicatorRef]
+			if !reflect.DeepEqual(referenced, *sli) {
+				t.Errorf("expected SLI %+v, got %+v", *sli, referenced)
+			}
+
+			imported, err := Import(slos[0], &referenced, "default", "")
+			if err != nil {
+				t.Fatalf("failed to import: %v", err)
+			}
+			if imported.GetName() != tt.monitor.GetName() || imported.GetNamespace() != tt.monitor.GetNamespace() {
+				t.Errorf("expected %s/%s, got %s/%s", tt.monitor.GetNamespace(), tt.monitor.GetName(), imported.GetNamespace(), imported.GetName())
+			}
+			switch want := tt.monitor.(type) {
+			case *v1alpha1.RouteMonitor:
+				got, ok := imported.(*v1alpha1.RouteMonitor)
+				if !ok || !reflect.DeepEqual(got.Spec, want.Spec) {
+					t.Errorf("expected RouteMonitor spec %+v, got %+v", want.Spec, imported)
+				}
+			case *v1alpha1.ClusterUrlMonitor:
+				got, ok := imported.(*v1alpha1.ClusterUrlMonitor)
+				if !ok || !reflect.DeepEqual(got.Spec, want.Spec) {
+					t.Errorf("expected ClusterUrlMonitor spec %+v, got %+v", want.Spec, imported)
+				}
+			}
+		})
+	}
+}
+
+func TestImport(t *testing.T) {
+	const inlineSLO = `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: console
+  annotations:
+    route-monitor-operator.openshift.io/kind: RouteMonitor
+    route-monitor-operator.openshift.io/spec: '{"route":{"name":"console","namespace":"openshift-console"}}'
+spec:
+  service: console
+  budgetingMethod: Occurrences
+  indicator:
+    metadata:
+      name: console-availability
+    spec:
+      ratioMetric:
+        counter: false
+        good:
+          metricSource:
+            type: prometheus
+            spec:
+              query: sum(probe_success{probe_url="https://console.example.com"})
+        total:
+          metricSource:
+            type: prometheus
+            spec:
+              query: count(probe_success{probe_url="https://console.example.com"})
+  objectives:
+  - targetPercent: 99.9
+`
+
+	tests := []struct {
+		name    string
+		modify  func(string) string
+		wantErr bool
+	}{
+		{
+			name:   "inline SLI and target percent",
+			modify: func(s string) string { return s },
+		},
+		{
+			name:    "not a probe_success ratio",
+			modify:  func(s string) string { return strings.ReplaceAll(s, "probe_success", "up") },
+			wantErr: true,
+		},
+		{
+			name:    "unsupported metric source",
+			modify:  func(s string) string { return strings.ReplaceAll(s, "type: prometheus", "type: Datadog") },
+			wantErr: true,
+		},
+		{
+			name:    "unsupported target",
+			modify:  func(s string) string { return strings.Replace(s, "targetPercent: 99.9", "targetPercent: 80", 1) },
+			wantErr: true,
+		},
+		{
+			name: "unsupported monitor kind",
+			modify: func(s string) string {
+				return strings.Replace(s, "kind: RouteMonitor", "kind: Probe", 1)
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slos, _, err := Unmarshal(strings.NewReader(tt.modify(inlineSLO)))
+			if err != nil || len(slos) != 1 {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			imported, err := Import(slos[0], nil, "openshift-console", "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			routeMonitor, ok := imported.(*v1alpha1.RouteMonitor)
+			if !ok {
+				t.Fatalf("expected a RouteMonitor, got %T", imported)
+			}
+			if routeMonitor.Name != "console" || routeMonitor.Namespace != "openshift-console" {
+				t.Errorf("expected openshift-console/console, got %s/%s", routeMonitor.Namespace, routeMonitor.Name)
+			}
+			if routeMonitor.Spec.Slo.TargetAvailabilityPercent != "99.9" {
+				t.Errorf("expected a target availability of 99.9, got %s", routeMonitor.Spec.Slo.TargetAvailabilityPercent)
+			}
+		})
+	}
+}
+
+func TestImport_missingSLI(t *testing.T) {
+	slo, _, err := Export(testRouteMonitor, "https://console.example.com/health", "28d")
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	if _, err := Import(*slo, nil, "default", ""); err == nil {
+		t.Errorf("expected an error when the referenced SLI is missing")
+	}
+}
+
+func TestImport_withoutAnnotations(t *testing.T) {
+	const thirdPartySLO = `
+apiVersion: openslo/v1
+kind: SLO
+metadata:
+  name: api
+spec:
+  service: api
+  budgetingMethod: Occurrences
+  indicator:
+    metadata:
+      name: api-availability
+    spec:
+      ratioMetric:
+        counter: false
+        good:
+          metricSource:
+            type: prometheus
+            spec:
+              query: sum(rate(probe_success{job="blackbox", probe_url="URL"}[5m]))
+        total:
+          metricSource:
+            type: prometheus
+            spec:
+              query: count(probe_success{probe_url="URL"})
+  objectives:
+  - target: 0.995
+`
+
+	tests := []struct {
+		name          string
+		url           string
+		clusterDomain string
+		want          v1alpha1.ClusterUrlMonitorSpec
+		wantErr       bool
+	}{
+		{
+			name:          "URL with port and path",
+			url:           "https://api.example.devshift.org:6443/livez",
+			clusterDomain: "example.devshift.org",
+			want:          v1alpha1.ClusterUrlMonitorSpec{Prefix: "https://api.", Port: "6443", Suffix: "/livez"},
+		},
+		{
+			name:          "port of the scheme",
+			url:           "http://console.apps.example.devshift.org",
+			clusterDomain: "example.devshift.org",
+			want:          v1alpha1.ClusterUrlMonitorSpec{Prefix: "http://console.apps.", Port: "80"},
+		},
+		{
+			name:          "outside the cluster domain",
+			url:           "https://api.example.com/livez",
+			clusterDomain: "example.devshift.org",
+			wantErr:       true,
+		},
+		{
+			name:    "without a cluster domain",
+			url:     "https://api.example.devshift.org/livez",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slos, _, err := Unmarshal(strings.NewReader(strings.ReplaceAll(thirdPartySLO, "URL", tt.url)))
+			if err != nil || len(slos) != 1 {
+				t.Fatalf("failed to unmarshal: %v", err)
+			}
+			imported, err := Import(slos[0], nil, "openshift-monitoring", tt.clusterDomain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if tt.wantErr {
+				return
+			}
+			clusterUrlMonitor, ok := imported.(*v1alpha1.ClusterUrlMonitor)
+			if !ok {
+				t.Fatalf("expected a ClusterUrlMonitor, got %T", imported)
+			}
+			if clusterUrlMonitor.Name != "api" || clusterUrlMonitor.Namespace != "openshift-monitoring" {
+				t.Errorf("expected openshift-monitoring/api, got %s/%s", clusterUrlMonitor.Namespace, clusterUrlMonitor.Name)
+			}
+			tt.want.DomainRef = v1alpha1.ClusterDomainRefInfra
+			tt.want.Slo = v1alpha1.SloSpec{TargetAvailabilityPercent: "99.5"}
+			if !reflect.DeepEqual(clusterUrlMonitor.Spec, tt.want) {
+				t.Errorf("expected ClusterUrlMonitor spec %+v, got %+v", tt.want, clusterUrlMonitor.Spec)
+			}
+		})
+	}
+}
+
+func TestImport_differentURLs(t *testing.T) {
+	sli := SLISpec{RatioMetric: &RatioMetric{
+		Good:  prometheusQuery(`sum(probe_success{probe_url="https://a.example.com"})`),
+		Total: prometheusQuery(`count(probe_success{probe_url="https://b.example.com"})`),
+	}}
+	if _, err := selectedProbeURL(sli); err == nil {
+		t.Errorf("expected an error when the good and total queries select different URLs")
+	}
+}
@@ -0,0 +1,112 @@
+package openslo
+
+// The following types are the subset of the OpenSLO v1 specification (https://github.com/OpenSLO/OpenSLO)
+// needed to describe the availability SLOs of RouteMonitors and ClusterUrlMonitors
+
+const (
+	APIVersion = "openslo/v1"
+	KindSLO    = "SLO"
+	KindSLI    = "SLI"
+)
+
+// Document is implemented by all OpenSLO documents
+type Document interface {
+	GetKind() string
+	GetName() string
+}
+
+// TypeMeta identifies the version and kind of an OpenSLO document
+type TypeMeta struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+}
+
+// GetKind returns the document's kind
+func (t TypeMeta) GetKind() string {
+	return t.Kind
+}
+
+// Metadata describes an OpenSLO document
+type Metadata struct {
+	Name        string            `json:"name"`
+	DisplayName string            `json:"displayName,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GetName returns the document's name
+func (m Metadata) GetName() string {
+	return m.Name
+}
+
+// SLO is an OpenSLO service level objective
+type SLO struct {
+	TypeMeta `json:",inline"`
+	Metadata `json:"metadata"`
+	Spec     SLOSpec `json:"spec"`
+}
+
+// SLOSpec defines an SLO's indicator, time window and objectives
+type SLOSpec struct {
+	Description     string         `json:"description,omitempty"`
+	Service         string         `json:"service"`
+	IndicatorRef    string         `json:"indicatorRef,omitempty"`
+	Indicator       *InlineSLI     `json:"indicator,omitempty"`
+	TimeWindow      []TimeWindow   `json:"timeWindow,omitempty"`
+	BudgetingMethod string         `json:"budgetingMethod"`
+	Objectives      []SLOObjective `json:"objectives"`
+}
+
+// InlineSLI is an SLI defined within an SLO
+type InlineSLI struct {
+	Metadata `json:"metadata"`
+	Spec     SLISpec `json:"spec"`
+}
+
+// TimeWindow is the period over which an SLO is evaluated
+type TimeWindow struct {
+	Duration  string `json:"duration"`
+	IsRolling bool   `json:"isRolling"`
+}
+
+// SLOObjective is an SLO's target, either as ratio or as percentage
+type SLOObjective struct {
+	DisplayName   string   `json:"displayName,omitempty"`
+	Target        *float64 `json:"target,omitempty"`
+	TargetPercent *float64 `json:"targetPercent,omitempty"`
+}
+
+// SLI is an OpenSLO service level indicator
+type SLI struct {
+	TypeMeta `json:",inline"`
+	Metadata `json:"metadata"`
+	Spec     SLISpec `json:"spec"`
+}
+
+// SLISpec defines how an SLI is measured. Only ratio metrics are supported
+type SLISpec struct {
+	Description string       `json:"description,omitempty"`
+	RatioMetric *RatioMetric `json:"ratioMetric,omitempty"`
+}
+
+// RatioMetric measures an SLI as the ratio of good to total events
+type RatioMetric struct {
+	Counter bool         `json:"counter"`
+	Good    *MetricQuery `json:"good,omitempty"`
+	Total   *MetricQuery `json:"total,omitempty"`
+}
+
+// MetricQuery holds the source of a ratio metric's events
+type MetricQuery struct {
+	MetricSource MetricSource `json:"metricSource"`
+}
+
+// MetricSource is a query against a metrics backend
+type MetricSource struct {
+	Type string            `json:"type"`
+	Spec MetricSourceQuery `json:"spec"`
+}
+
+// MetricSourceQuery is the query of a Prometheus metric source
+type MetricSourceQuery struct {
+	Query string `json:"query"`
+}
@@ -74,18 +74,19 @@ func (u *MonitorResourceCommon) needsErrorStatusToBeSet(errorStatus *string, err
 	return *errorStatus == "" && err != nil
 }
 
-func (u *MonitorResourceCommon) SetResourceReference(reference *v1alpha1.NamespacedName, targetNamespace types.NamespacedName) (bool, error) {
+func (u *MonitorResourceCommon) SetResourceReference(reference *v1alpha1.NamespacedName, targetNamespace types.NamespacedName) bool {
 	desiredRef := v1alpha1.NamespacedName{Name: targetNamespace.Name, Namespace: targetNamespace.Namespace}
-	if *reference == (v1alpha1.NamespacedName{}) ||
-		desiredRef == (v1alpha1.NamespacedName{}) {
-		*reference = desiredRef
-		return true, nil
-	}
-	if *reference != desiredRef {
-		// TODO Check when this is really required
-		return false, customerrors.ErrInvalidReferenceUpdate
+	if *reference == desiredRef {
+		return false
 	}
-	return false, nil
+	*reference = desiredRef
+	return true
+}
+
+// ReferenceChanged returns true if the reference points to a resource other than the target. The resource it points to
+// should be deleted once the target exists, before the reference is switched to the target with SetResourceReference
+func ReferenceChanged(reference v1alpha1.NamespacedName, target types.NamespacedName) bool {
+	return reference != (v1alpha1.NamespacedName{}) && reference != v1alpha1.NamespacedName{Name: target.Name, Namespace: target.Namespace}
 }
 
 // remove boolean
@@ -225,7 +225,6 @@ var _ = Describe("CR Deployment Handling", func() {
 			reference v1alpha1.NamespacedName
 			target    types.NamespacedName
 			res       bool
-			err       error
 		)
 		BeforeEach(func() {
 			reference = v1alpha1.NamespacedName{}
@@ -233,7 +232,7 @@ var _ = Describe("CR Deployment Handling", func() {
 
 		})
 		JustBeforeEach(func() {
-			res, err = rc.SetResourceReference(&reference, target)
+			res = rc.SetResourceReference(&reference, target)
 
 		})
 		When("when existing reference is flushed", func() {
@@ -243,7 +242,7 @@ var _ = Describe("CR Deployment Handling", func() {
 			})
 			It("should indicate that the references has been altered", func() {
 				Expect(res).To(Equal(true))
-				Expect(err).To(Not(HaveOccurred()))
+				Expect(reference).To(BeZero())
 			})
 		})
 		When("when empty reference is filled", func() {
@@ -253,7 +252,7 @@ var _ = Describe("CR Deployment Handling", func() {
 			})
 			It("should indicate that the references has been altered", func() {
 				Expect(res).To(Equal(true))
-				Expect(err).To(Not(HaveOccurred()))
+				Expect(reference).To(Equal(v1alpha1.NamespacedName{Name: "fake", Namespace: "fake-namespace"}))
 			})
 		})
 		When("when reference is already set according to the target", func() {
@@ -263,20 +262,31 @@ var _ = Describe("CR Deployment Handling", func() {
 			})
 			It("should indicate that the references has not been altered", func() {
 				Expect(res).To(Equal(false))
-				Expect(err).To(Not(HaveOccurred()))
 			})
 		})
-		When("it trys to fill reference with new values, which should never happen and is not supported", func() {
+		When("when reference is retargeted to a resource with a new name", func() {
 			BeforeEach(func() {
 				reference = v1alpha1.NamespacedName{Name: "fake", Namespace: "fake-namespace"}
 				target = types.NamespacedName{Name: "fake2", Namespace: "fake-namespace2"}
 			})
-			It("should indicate error", func() {
-				Expect(res).To(Equal(false))
-				Expect(err).To(Equal(customerrors.ErrInvalidReferenceUpdate))
+			It("should switch the reference to the target", func() {
+				Expect(res).To(Equal(true))
+				Expect(reference).To(Equal(v1alpha1.NamespacedName{Name: "fake2", Namespace: "fake-namespace2"}))
 			})
 		})
 	})
+	Describe("ReferenceChanged", func() {
+		target := types.NamespacedName{Name: "fake", Namespace: "fake-namespace"}
+		It("is false for an empty reference", func() {
+			Expect(reconcilecommon.ReferenceChanged(v1alpha1.NamespacedName{}, target)).To(BeFalse())
+		})
+		It("is false for a reference to the target", func() {
+			Expect(reconcilecommon.ReferenceChanged(v1alpha1.NamespacedName{Name: "fake", Namespace: "fake-namespace"}, target)).To(BeFalse())
+		})
+		It("is true for a reference to another resource", func() {
+			Expect(reconcilecommon.ReferenceChanged(v1alpha1.NamespacedName{Name: "fake", Namespace: "old-namespace"}, target)).To(BeTrue())
+		})
+	})
 	Describe("UpdateMonitorResourceStatus", func() {
 		var (
 			routeMonitor     v1alpha1.RouteMonitor
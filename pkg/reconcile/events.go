@@ -0,0 +1,60 @@
+package reconcileCommon
+
+import (
+	"github.com/openshift/route-monitor-operator/pkg/consts"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// RecordServiceMonitorEvent emits a Normal Event on the monitor when its ServiceMonitor was created or updated
+func RecordServiceMonitorEvent(recorder record.EventRecorder, monitor runtime.Object, result controllerutil.OperationResult, serviceMonitor types.NamespacedName) {
+	switch result {
+	case controllerutil.OperationResultCreated:
+		recorder.Eventf(monitor, corev1.EventTypeNormal, consts.EventReasonServiceMonitorCreated, "Created ServiceMonitor %s", serviceMonitor)
+	case controllerutil.OperationResultUpdated:
+		recorder.Eventf(monitor, corev1.EventTypeNormal, consts.EventReasonServiceMonitorUpdated, "Updated ServiceMonitor %s", serviceMonitor)
+	}
+}
+
+// RecordPrometheusRuleEvent emits a Normal Event on the monitor when its PrometheusRule was created or updated
+func RecordPrometheusRuleEvent(recorder record.EventRecorder, monitor runtime.Object, result controllerutil.OperationResult, prometheusRule types.NamespacedName) {
+	switch result {
+	case controllerutil.OperationResultCreated:
+		recorder.Eventf(monitor, corev1.EventTypeNormal, consts.EventReasonPrometheusRuleCreated, "Created PrometheusRule %s", prometheusRule)
+	case controllerutil.OperationResultUpdated:
+		recorder.Eventf(monitor, corev1.EventTypeNormal, consts.EventReasonPrometheusRuleUpdated, "Updated PrometheusRule %s", prometheusRule)
+	}
+}
+
+// RecordDashboardEvent emits a Normal Event on the monitor when its Grafana dashboard was created or updated
+func RecordDashboardEvent(recorder record.EventRecorder, monitor runtime.Object, result controllerutil.OperationResult, dashboard types.NamespacedName) {
+	switch result {
+	case controllerutil.OperationResultCreated:
+		recorder.Eventf(monitor, corev1.EventTypeNormal, consts.EventReasonDashboardCreated, "Created dashboard %s", dashboard)
+	case controllerutil.OperationResultUpdated:
+		recorder.Eventf(monitor, corev1.EventTypeNormal, consts.EventReasonDashboardUpdated, "Updated dashboard %s", dashboard)
+	}
+}
+
+// RecordInvalidSLOEvent emits a Warning Event on the monitor when its SLO could not be parsed
+func RecordInvalidSLOEvent(recorder record.EventRecorder, monitor runtime.Object, err error) {
+	recorder.Eventf(monitor, corev1.EventTypeWarning, consts.EventReasonInvalidSLO, "Invalid SLO: %v", err)
+}
+
+// RecordInvalidAlertingEvent emits a Warning Event on the monitor when the labels or annotations of its alerts could not be rendered
+func RecordInvalidAlertingEvent(recorder record.EventRecorder, monitor runtime.Object, err error) {
+	recorder.Eventf(monitor, corev1.EventTypeWarning, consts.EventReasonInvalidAlerting, "Invalid alerting: %v", err)
+}
+
+// RecordInvalidProbeEvent emits a Warning Event on the monitor when its probe settings could not be turned into a blackbox exporter module
+func RecordInvalidProbeEvent(recorder record.EventRecorder, monitor runtime.Object, err error) {
+	recorder.Eventf(monitor, corev1.EventTypeWarning, consts.EventReasonInvalidProbe, "Invalid probe: %v", err)
+}
+
+// RecordSLOQueryFailedEvent emits a Warning Event on the monitor when its SLO status could not be queried
+func RecordSLOQueryFailedEvent(recorder record.EventRecorder, monitor runtime.Object, err error) {
+	recorder.Eventf(monitor, corev1.EventTypeWarning, consts.EventReasonSLOQueryFailed, "Failed to query the SLO status: %v", err)
+}
@@ -0,0 +1,234 @@
+package render
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/config"
+	"github.com/openshift/route-monitor-operator/pkg/alert"
+	"github.com/openshift/route-monitor-operator/pkg/blackboxexporter"
+	consts "github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
+	"github.com/openshift/route-monitor-operator/pkg/servicemonitor"
+	customerrors "github.com/openshift/route-monitor-operator/pkg/util/errors"
+)
+
+const (
+	KindRouteMonitor      = "RouteMonitor"
+	KindClusterUrlMonitor = "ClusterUrlMonitor"
+)
+
+// Context describes the cluster the monitors are rendered for, standing in for the resources the operator looks up
+type Context struct {
+	// Routes maps the Routes of RouteMonitors, as <namespace>/<name>, to what the operator reads from them
+	Routes map[string]Route `json:"routes,omitempty"`
+	// ClusterID is the ID added to the probe metrics as the _id label
+	ClusterID string `json:"clusterID"`
+	// ClusterDomain is the domain of the URLs of ClusterUrlMonitors, e.g. example.devshift.org
+	ClusterDomain string `json:"clusterDomain"`
+	// ExporterNamespace is the namespace of the operator's blackbox exporter. Defaults to the operator's namespace
+	ExporterNamespace string `json:"exporterNamespace,omitempty"`
+	// ProbeExporterNamespaces maps the names of the ProbeExporters referenced by exporterRef to their spec.namespace
+	ProbeExporterNamespaces map[string]string `json:"probeExporterNamespaces,omitempty"`
+}
+
+// Route stands in for a Route probed by RouteMonitors
+type Route struct {
+	// Host is the host of the Route's first ingress
+	Host string `json:"host"`
+	// Insecure marks Routes without TLS, which are probed over http
+	Insecure bool `json:"insecure,omitempty"`
+}
+
+// UnmarshalContext decodes a Context from YAML, rejecting unknown fields
+func UnmarshalContext(data []byte) (Context, error) {
+	renderContext := Context{}
+	if err := yaml.UnmarshalStrict(data, &renderContext); err != nil {
+		return Context{}, fmt.Errorf("failed to parse the render context: %w", err)
+	}
+	return renderContext, nil
+}
+
+// Unmarshal decodes the RouteMonitors and ClusterUrlMonitors from multi-document YAML, skipping documents of other kinds
+func Unmarshal(r io.Reader) ([]client.Object, error) {
+	monitors := []client.Object{}
+	reader := utilyaml.NewYAMLReader(bufio.NewReader(r))
+	for {
+		raw, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return monitors, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read document: %w", err)
+		}
+		typeMeta := metav1.TypeMeta{}
+		if err := yaml.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, fmt.Errorf("failed to parse document: %w", err)
+		}
+		if typeMeta.APIVersion != v1alpha1.GroupVersion.String() {
+			continue
+		}
+		var monitor client.Object
+		switch typeMeta.Kind {
+		case KindRouteMonitor:
+			monitor = &v1alpha1.RouteMonitor{}
+		case KindClusterUrlMonitor:
+			monitor = &v1alpha1.ClusterUrlMonitor{}
+		default:
+			continue
+		}
+		if err := yaml.UnmarshalStrict(raw, monitor); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", typeMeta.Kind, err)
+		}
+		monitors = append(monitors, monitor)
+	}
+}
+
+// Render returns the ServiceMonitor and, for monitors with an SLO, the PrometheusRule the operator generates for a
+// RouteMonitor or ClusterUrlMonitor. Monitors whose probes are sent to RHOBS get the RHOBS variants of both
+func Render(monitor client.Object, renderContext Context, alertDefaults alert.Defaults) ([]client.Object, error) {
+	switch m := monitor.(type) {
+	case *v1alpha1.RouteMonitor:
+		routeName := m.Spec.Route.Namespace + "/" + m.Spec.Route.Name
+		route := renderContext.Routes[routeName]
+		if route.Host == "" {
+			return nil, fmt.Errorf("%w: the render context has no routes entry for %s", customerrors.ErrNoHost, routeName)
+		}
+		if err := blackboxexporter.ValidateRouteMonitorProbe(*m); err != nil {
+			return nil, err
+		}
+		return render(m, m.Spec.Route.URL(route.Host, !route.Insecure), m.Spec.ServiceMonitorType == v1alpha1.ServiceMonitorTypeRHOBS, renderContext, alertDefaults, monitorSpec{
+			exporterRef:        m.Spec.ExporterRef,
+			probe:              m.Spec.Probe,
+			module:             blackboxexporter.ModuleForRouteMonitor(*m),
+			proxyURL:           m.Spec.ProxyURL,
+			slo:                m.Spec.Slo,
+			skipPrometheusRule: m.Spec.SkipPrometheusRule,
+			alerting:           m.Spec.Alerting,
+			certificateExpiry:  m.Spec.CertificateExpiry,
+		})
+	case *v1alpha1.ClusterUrlMonitor:
+		if renderContext.ClusterDomain == "" {
+			return nil, errors.New("the render context has no clusterDomain")
+		}
+		spec := m.Spec
+		return render(m, spec.URL(renderContext.ClusterDomain), spec.DomainRef == v1alpha1.ClusterDomainRefHCP, renderContext, alertDefaults, monitorSpec{
+			exporterRef:        spec.ExporterRef,
+			probe:              spec.Probe,
+			module:             blackboxexporter.ModuleForClusterUrlMonitor(*m),
+			proxyURL:           spec.ProxyURL,
+			slo:                spec.Slo,
+			skipPrometheusRule: spec.SkipPrometheusRule,
+			alerting:           spec.Alerting,
+			certificateExpiry:  spec.CertificateExpiry,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported monitor %T", monitor)
+	}
+}
+
+// monitorSpec holds the fields shared by the specs of RouteMonitors and ClusterUrlMonitors
+type monitorSpec struct {
+	exporterRef        string
+	probe              v1alpha1.ProbeSpec
+	module             string
+	proxyURL           string
+	slo                v1alpha1.SloSpec
+	skipPrometheusRule bool
+	alerting           v1alpha1.AlertingSpec
+	certificateExpiry  *v1alpha1.CertificateExpirySpec
+}
+
+func render(monitor client.Object, url string, useRHOBS bool, renderContext Context, alertDefaults alert.Defaults, spec monitorSpec) ([]client.Object, error) {
+	if err := blackboxexporter.ValidateProbe(spec.probe); err != nil {
+		return nil, err
+	}
+	exporterService, err := renderContext.exporterService(spec.exporterRef)
+	if err != nil {
+		return nil, err
+	}
+
+	namespacedName := types.NamespacedName{Name: monitor.GetName(), Namespace: monitor.GetNamespace()}
+	owner := metav1.NewControllerRef(monitor, monitor.GetObjectKind().GroupVersionKind())
+	params := servicemonitor.ProbeParams(url, spec.module)
+	serviceMonitors := &servicemonitor.ServiceMonitor{}
+	objects := []client.Object{}
+	if useRHOBS {
+		serviceMonitor := serviceMonitors.HyperShiftTemplateForServiceMonitorResource(url, exporterService, params, namespacedName, renderContext.ClusterID, spec.proxyURL, owner)
+		serviceMonitor.TypeMeta = metav1.TypeMeta{APIVersion: rhobsv1.SchemeGroupVersion.String(), Kind: rhobsv1.ServiceMonitorsKind}
+		objects = append(objects, &serviceMonitor)
+	} else {
+		serviceMonitor := serviceMonitors.TemplateForServiceMonitorResource(url, exporterService, params, namespacedName, renderContext.ClusterID, spec.proxyURL, owner)
+		serviceMonitor.TypeMeta = metav1.TypeMeta{APIVersion: monitoringv1.SchemeGroupVersion.String(), Kind: monitoringv1.ServiceMonitorsKind}
+		objects = append(objects, &serviceMonitor)
+	}
+
+	// like the operator, only monitors with an SLO get a PrometheusRule
+	if spec.skipPrometheusRule || spec.slo == (v1alpha1.SloSpec{}) {
+		return objects, nil
+	}
+	isValid, parsedSlo := spec.slo.IsValid()
+	if !isValid {
+		return nil, customerrors.ErrInvalidSLO
+	}
+	prometheusRule, err := alert.TemplateForPrometheusRuleResource(url, parsedSlo, namespacedName, alertDefaults.Apply(spec.alerting), spec.certificateExpiry)
+	if err != nil {
+		return nil, err
+	}
+	if useRHOBS {
+		rhobsPrometheusRule := alert.HyperShiftTemplateForPrometheusRuleResource(prometheusRule)
+		rhobsPrometheusRule.TypeMeta = metav1.TypeMeta{APIVersion: rhobsv1.SchemeGroupVersion.String(), Kind: rhobsv1.PrometheusRuleKind}
+		return append(objects, &rhobsPrometheusRule), nil
+	}
+	prometheusRule.TypeMeta = metav1.TypeMeta{APIVersion: monitoringv1.SchemeGroupVersion.String(), Kind: monitoringv1.PrometheusRuleKind}
+	return append(objects, &prometheusRule), nil
+}
+
+// exporterService returns the Service of the blackbox exporter selected by exporterRef, like BlackBoxExporter.GetExporterService
+func (c Context) exporterService(exporterRef string) (types.NamespacedName, error) {
+	if exporterRef == "" {
+		namespace := c.ExporterNamespace
+		if namespace == "" {
+			namespace = config.OperatorNamespace
+		}
+		return types.NamespacedName{Name: consts.BlackBoxExporterName, Namespace: namespace}, nil
+	}
+	namespace, ok := c.ProbeExporterNamespaces[exporterRef]
+	if !ok {
+		return types.NamespacedName{}, fmt.Errorf("the namespace of ProbeExporter %q referenced by exporterRef is missing from probeExporterNamespaces", exporterRef)
+	}
+	return types.NamespacedName{Name: consts.ProbeExporterResourceName(exporterRef), Namespace: namespace}, nil
+}
+
+// Marshal encodes the objects as multi-document YAML, leaving out their empty status and creationTimestamp
+func Marshal(objects []client.Object) ([]byte, error) {
+	out := bytes.Buffer{}
+	for _, object := range objects {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+		if err != nil {
+			return nil, err
+		}
+		delete(content, "status")
+		unstructured.RemoveNestedField(content, "metadata", "creationTimestamp")
+		doc, err := yaml.Marshal(content)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---\n")
+		out.Write(doc)
+	}
+	return out.Bytes(), nil
+}
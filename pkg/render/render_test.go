@@ -0,0 +1,226 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/alert"
+)
+
+var testContext = Context{
+	Routes: map[string]Route{
+		"openshift-console/console":              {Host: "console-openshift-console.apps.example.devshift.org"},
+		"openshift-monitoring/alertmanager-main": {Host: "alertmanager-main-openshift-monitoring.apps.example.devshift.org", Insecure: true},
+	},
+	ClusterID:     "test-cluster-id",
+	ClusterDomain: "example.devshift.org",
+}
+
+const testMonitors = `apiVersion: monitoring.openshift.io/v1alpha1
+kind: RouteMonitor
+metadata:
+  name: console
+  namespace: openshift-console
+spec:
+  route:
+    name: console
+    namespace: openshift-console
+    suffix: /health
+  slo:
+    targetAvailabilityPercent: "99.5"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: unrelated
+---
+apiVersion: monitoring.openshift.io/v1alpha1
+kind: ClusterUrlMonitor
+metadata:
+  name: api
+  namespace: ocm-production-test
+spec:
+  prefix: api.
+  port: "443"
+  suffix: /livez
+  domainRef: hcp
+  slo:
+    targetAvailabilityPercent: "99.95"
+`
+
+func TestRender(t *testing.T) {
+	monitors, err := Unmarshal(strings.NewReader(testMonitors))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(monitors) != 2 {
+		t.Fatalf("expected the ConfigMap to be skipped, got %d monitors", len(monitors))
+	}
+
+	objects, err := Render(monitors[0], testContext, alert.Defaults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected a ServiceMonitor and a PrometheusRule, got %d objects", len(objects))
+	}
+	serviceMonitor, ok := objects[0].(*monitoringv1.ServiceMonitor)
+	if !ok {
+		t.Fatalf("expected a ServiceMonitor, got %T", objects[0])
+	}
+	endpoint := serviceMonitor.Spec.Endpoints[0]
+	if target := endpoint.Params["target"]; len(target) != 1 || target[0] != "https://console-openshift-console.apps.example.devshift.org/health" {
+		t.Errorf("unexpected target %v", target)
+	}
+	if namespaces := serviceMonitor.Spec.NamespaceSelector.MatchNames; len(namespaces) != 1 || namespaces[0] != "openshift-route-monitor-operator" {
+		t.Errorf("expected the operator's blackbox exporter, got namespaces %v", namespaces)
+	}
+	if serviceMonitor.OwnerReferences[0].Kind != KindRouteMonitor {
+		t.Errorf("expected the ServiceMonitor to be owned by the RouteMonitor, got %v", serviceMonitor.OwnerReferences)
+	}
+	if _, ok := objects[1].(*monitoringv1.PrometheusRule); !ok {
+		t.Errorf("expected a PrometheusRule, got %T", objects[1])
+	}
+
+	objects, err = Render(monitors[1], testContext, alert.Defaults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 {
+		t.Fatalf("expected a ServiceMonitor and a PrometheusRule, got %d objects", len(objects))
+	}
+	rhobsServiceMonitor, ok := objects[0].(*rhobsv1.ServiceMonitor)
+	if !ok {
+		t.Fatalf("expected a RHOBS ServiceMonitor for a HostedControlPlane monitor, got %T", objects[0])
+	}
+	if target := rhobsServiceMonitor.Spec.Endpoints[0].Params["target"]; len(target) != 1 || target[0] != "api.example.devshift.org:443/livez" {
+		t.Errorf("unexpected target %v", target)
+	}
+	if _, ok := objects[1].(*rhobsv1.PrometheusRule); !ok {
+		t.Errorf("expected a RHOBS PrometheusRule, got %T", objects[1])
+	}
+
+	out, err := Marshal(objects)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, expected := range []string{"kind: ServiceMonitor", "kind: PrometheusRule", "apiVersion: " + rhobsv1.SchemeGroupVersion.String()} {
+		if !strings.Contains(string(out), expected) {
+			t.Errorf("expected the output to contain %q:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(string(out), "creationTimestamp") {
+		t.Errorf("expected the output to leave out creationTimestamp:\n%s", out)
+	}
+}
+
+func TestRender_perRoute(t *testing.T) {
+	routeMonitor := &v1alpha1.RouteMonitor{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: KindRouteMonitor},
+		ObjectMeta: metav1.ObjectMeta{Name: "alertmanager", Namespace: "openshift-monitoring"},
+		Spec: v1alpha1.RouteMonitorSpec{Route: v1alpha1.RouteMonitorRouteSpec{
+			Name: "alertmanager-main", Namespace: "openshift-monitoring", Port: 8080, Suffix: "/-/healthy",
+		}},
+	}
+	objects, err := Render(routeMonitor, testContext, alert.Defaults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	serviceMonitor := objects[0].(*monitoringv1.ServiceMonitor)
+	if target := serviceMonitor.Spec.Endpoints[0].Params["target"]; len(target) != 1 || target[0] != "alertmanager-main-openshift-monitoring.apps.example.devshift.org:8080/-/healthy" {
+		t.Errorf("expected the host of the monitor's own Route, got %v", target)
+	}
+}
+
+func TestRender_withoutPrometheusRule(t *testing.T) {
+	routeMonitor := &v1alpha1.RouteMonitor{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: KindRouteMonitor},
+		ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"},
+		Spec:       v1alpha1.RouteMonitorSpec{Route: v1alpha1.RouteMonitorRouteSpec{Name: "console", Namespace: "openshift-console"}},
+	}
+	objects, err := Render(routeMonitor, testContext, alert.Defaults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Errorf("expected only a ServiceMonitor for a monitor without SLO, got %d objects", len(objects))
+	}
+
+	routeMonitor.Spec.Slo = v1alpha1.SloSpec{TargetAvailabilityPercent: "99.5"}
+	routeMonitor.Spec.SkipPrometheusRule = true
+	objects, err = Render(routeMonitor, testContext, alert.Defaults{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 1 {
+		t.Errorf("expected only a ServiceMonitor for a monitor skipping its PrometheusRule, got %d objects", len(objects))
+	}
+}
+
+func TestRender_errors(t *testing.T) {
+	tests := map[string]struct {
+		spec    v1alpha1.RouteMonitorSpec
+		context Context
+		err     string
+	}{
+		"invalid SLO": {
+			spec:    v1alpha1.RouteMonitorSpec{Slo: v1alpha1.SloSpec{TargetAvailabilityPercent: "100.5"}},
+			context: testContext,
+			err:     "invalid RawSlo",
+		},
+		"invalid probe": {
+			spec:    v1alpha1.RouteMonitorSpec{Probe: v1alpha1.ProbeSpec{Expect: &v1alpha1.ProbeExpectSpec{BodyRegex: "(unclosed"}}},
+			context: testContext,
+			err:     "probe.expect.bodyRegex",
+		},
+		"unknown ProbeExporter": {
+			spec:    v1alpha1.RouteMonitorSpec{ExporterRef: "external"},
+			context: testContext,
+			err:     "probeExporterNamespaces",
+		},
+		"missing route host": {
+			context: Context{ClusterID: "test-cluster-id", Routes: map[string]Route{"openshift-console/other": {Host: "other.example.com"}}},
+			err:     "no routes entry for openshift-console/console",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			routeMonitor := &v1alpha1.RouteMonitor{
+				TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.GroupVersion.String(), Kind: KindRouteMonitor},
+				ObjectMeta: metav1.ObjectMeta{Name: "console", Namespace: "openshift-console"},
+				Spec:       test.spec,
+			}
+			routeMonitor.Spec.Route = v1alpha1.RouteMonitorRouteSpec{Name: "console", Namespace: "openshift-console"}
+			_, err := Render(routeMonitor, test.context, alert.Defaults{})
+			if err == nil || !strings.Contains(strings.ToLower(err.Error()), strings.ToLower(test.err)) {
+				t.Errorf("expected an error containing %q, got %v", test.err, err)
+			}
+		})
+	}
+}
+
+func TestUnmarshalContext(t *testing.T) {
+	renderContext, err := UnmarshalContext([]byte("routes:\n  openshift-console/console:\n    host: console.example.com\nclusterID: abc\nprobeExporterNamespaces:\n  external: probes\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	exporterService, err := renderContext.exporterService("external")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exporterService.Namespace != "probes" {
+		t.Errorf("expected the ProbeExporter's namespace, got %v", exporterService)
+	}
+	if route := renderContext.Routes["openshift-console/console"]; route.Host != "console.example.com" {
+		t.Errorf("expected the host of the Route, got %v", route)
+	}
+
+	if _, err := UnmarshalContext([]byte("routeHost: console.example.com\n")); err == nil {
+		t.Error("expected unknown fields to be rejected")
+	}
+}
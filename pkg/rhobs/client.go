@@ -13,6 +13,8 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
 )
 
 const (
@@ -132,6 +134,13 @@ func NewClientWithOIDC(baseURL, tenant string, oidcConfig OIDCConfig, logger log
 	}
 }
 
+// WithTransport sets the transport of the client's requests, including the OIDC token requests, e.g. to send them
+// through the cluster-wide proxy
+func (c *Client) WithTransport(transport http.RoundTripper) *Client {
+	c.httpClient.Transport = transport
+	return c
+}
+
 // CreateProbe creates a new probe in RHOBS
 func (c *Client) CreateProbe(ctx context.Context, req ProbeRequest) (*ProbeResponse, error) {
 	url := c.buildProbesURL()
@@ -163,7 +172,7 @@ func (c *Client) CreateProbe(ctx context.Context, req ProbeRequest) (*ProbeRespo
 	c.logger.V(debugLogLevel).Info("Creating RHOBS probe", "method", "POST", "url", url, "static_url", req.StaticURL, "cluster_id", req.Labels["cluster-id"], "tenant", c.tenant, "username", username)
 	c.logger.Info("Sending RHOBS API request", "method", "POST", "url", url, "operation", "create-probe")
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.do("create-probe", httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
 	}
@@ -228,7 +237,7 @@ func (c *Client) GetProbe(ctx context.Context, clusterID string) (*ProbeResponse
 	c.logger.V(debugLogLevel).Info("Getting RHOBS probe", "method", "GET", "url", httpReq.URL.String(), "cluster_id", clusterID, "tenant", c.tenant, "username", username)
 	c.logger.Info("Sending RHOBS API request", "method", "GET", "url", httpReq.URL.String(), "operation", "get-probe")
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.do("get-probe", httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
 	}
@@ -324,7 +333,7 @@ func (c *Client) DeleteProbe(ctx context.Context, clusterID string) error {
 	c.logger.V(debugLogLevel).Info("Terminating RHOBS probe", "method", "PATCH", "url", url, "cluster_id", clusterID, "tenant", c.tenant, "username", username)
 	c.logger.Info("Sending RHOBS API request", "method", "PATCH", "url", url, "operation", "delete-probe")
 
-	resp, err := c.httpClient.Do(httpReq)
+	resp, err := c.do("delete-probe", httpReq)
 	if err != nil {
 		return fmt.Errorf("failed to send HTTP request: %w", err)
 	}
@@ -369,7 +378,7 @@ func (c *Client) GetAccessToken(ctx context.Context) (string, error) {
 }
 
 // refreshAccessToken obtains a new access token using client credentials flow
-func (c *Client) refreshAccessToken(ctx context.Context) (string, error) {
+func (c *Client) refreshAccessToken(ctx context.Context) (token string, err error) {
 	c.tokenMutex.Lock()
 	defer c.tokenMutex.Unlock()
 
@@ -377,6 +386,9 @@ func (c *Client) refreshAccessToken(ctx context.Context) (string, error) {
 	if c.accessToken != "" && time.Now().Before(c.tokenExpiry.Add(-30*time.Second)) {
 		return c.accessToken, nil
 	}
+	defer func() {
+		metrics.RecordOIDCTokenRefresh(err)
+	}()
 
 	// Handle both direct token endpoint URLs and issuer URLs that need /token appended
 	tokenURL := c.oidcConfig.IssuerURL
@@ -429,6 +441,18 @@ func (c *Client) refreshAccessToken(ctx context.Context) (string, error) {
 	return c.accessToken, nil
 }
 
+// do sends the HTTP request, recording the request's outcome and latency under the given operation
+func (c *Client) do(operation string, req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	metrics.ObserveAPIRequest(metrics.APIRHOBS, operation, start, statusCode, err)
+	return resp, err
+}
+
 // addAuthHeaders adds authentication headers to the request if OIDC is configured
 func (c *Client) addAuthHeaders(ctx context.Context, req *http.Request) error {
 	if c.oidcConfig == nil {
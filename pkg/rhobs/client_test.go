@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -1253,3 +1254,29 @@ func TestNewClusterProbeRequest(t *testing.T) {
 		t.Errorf("Expected private 'false', got %s", req2.Labels["private"])
 	}
 }
+
+func TestWithTransport(t *testing.T) {
+	// The proxy receives the requests for the unresolvable API host
+	proxied := false
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.Host == "rhobs.invalid"
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(ProbesListResponse{})
+	}))
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	if err != nil {
+		t.Fatalf("Failed to parse proxy URL: %v", err)
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyURL(proxyURL)
+
+	client := NewClient("http://rhobs.invalid", "test-tenant", testr.New(t)).WithTransport(transport)
+	if _, err := client.GetProbe(context.Background(), "test-cluster"); err != nil {
+		t.Fatalf("GetProbe failed: %v", err)
+	}
+	if !proxied {
+		t.Errorf("Expected the request to be sent through the proxy")
+	}
+}
@@ -9,9 +9,11 @@ import (
 	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 type ServiceMonitor struct {
@@ -33,64 +35,67 @@ const (
 	UrlLabelName         string = "probe_url"
 )
 
-func (u *ServiceMonitor) TemplateAndUpdateServiceMonitorDeployment(routeURL, blackBoxExporterNamespace string, namespacedName types.NamespacedName, clusterID string, isHCPMonitor bool, useInsecure bool, owner *metav1.OwnerReference) error {
-	module := "http_2xx"
-	if useInsecure {
-		module = "insecure_http_2xx"
-	}
-
-	params := map[string][]string{
-		"module": {module},
-		"target": {routeURL},
-	}
+// TemplateAndUpdateServiceMonitorDeployment creates or updates the ServiceMonitor probing routeURL with the given blackbox exporter module.
+// Only the name of the module is passed to the exporter, the credentials of a module never appear in the ServiceMonitor.
+// A non-empty proxyURL is the proxy the probes are scraped through
+func (u *ServiceMonitor) TemplateAndUpdateServiceMonitorDeployment(routeURL string, exporterService types.NamespacedName, namespacedName types.NamespacedName, clusterID string, isHCPMonitor bool, module, proxyURL string, owner *metav1.OwnerReference) (controllerutil.OperationResult, error) {
+	params := ProbeParams(routeURL, module)
 
 	if isHCPMonitor {
-		s := u.HyperShiftTemplateForServiceMonitorResource(routeURL, blackBoxExporterNamespace, params, namespacedName, clusterID, owner)
+		s := u.HyperShiftTemplateForServiceMonitorResource(routeURL, exporterService, params, namespacedName, clusterID, proxyURL, owner)
 		return u.HypershiftUpdateServiceMonitorDeployment(s)
 	}
-	s := u.TemplateForServiceMonitorResource(routeURL, blackBoxExporterNamespace, params, namespacedName, clusterID, owner)
+	s := u.TemplateForServiceMonitorResource(routeURL, exporterService, params, namespacedName, clusterID, proxyURL, owner)
 	return u.UpdateServiceMonitorDeployment(s)
 }
 
-// Creates or Updates Service Monitor Deployment according to the template
+// ProbeParams returns the params of the /probe endpoint, probing routeURL with the given blackbox exporter module
+func ProbeParams(routeURL, module string) map[string][]string {
+	return map[string][]string{
+		"module": {module},
+		"target": {routeURL},
+	}
+}
+
+// Creates or Updates Service Monitor Deployment according to the template, reporting which operation was performed
 
-func (u *ServiceMonitor) UpdateServiceMonitorDeployment(template monitoringv1.ServiceMonitor) error {
+func (u *ServiceMonitor) UpdateServiceMonitorDeployment(template monitoringv1.ServiceMonitor) (controllerutil.OperationResult, error) {
 	namespacedName := types.NamespacedName{Name: template.Name, Namespace: template.Namespace}
 	deployedServiceMonitor := &monitoringv1.ServiceMonitor{}
 	err := u.Client.Get(u.Ctx, namespacedName, deployedServiceMonitor)
 	if err != nil {
 		// No similar ServiceMonitor exists
 		if !k8serrors.IsNotFound(err) {
-			return err
+			return controllerutil.OperationResultNone, err
 		}
-		return u.Client.Create(u.Ctx, &template)
+		return controllerutil.OperationResultCreated, u.Client.Create(u.Ctx, &template)
 	}
 	if !u.Comparer.DeepEqual(deployedServiceMonitor.Spec, template.Spec) {
 		// Update existing ServiceMonitor for the case that the template changed
 		deployedServiceMonitor.Spec = template.Spec
-		return u.Client.Update(u.Ctx, deployedServiceMonitor)
+		return controllerutil.OperationResultUpdated, u.Client.Update(u.Ctx, deployedServiceMonitor)
 	}
-	return nil
+	return controllerutil.OperationResultNone, nil
 }
 
 // Creates or Updates Service Monitor Deployment according to the template if enable of the hypershift
-func (u *ServiceMonitor) HypershiftUpdateServiceMonitorDeployment(template rhobsv1.ServiceMonitor) error {
+func (u *ServiceMonitor) HypershiftUpdateServiceMonitorDeployment(template rhobsv1.ServiceMonitor) (controllerutil.OperationResult, error) {
 	namespacedName := types.NamespacedName{Name: template.Name, Namespace: template.Namespace}
 	deployedServiceMonitor := &rhobsv1.ServiceMonitor{}
 	err := u.Client.Get(u.Ctx, namespacedName, deployedServiceMonitor)
 	if err != nil {
 		// No similar ServiceMonitor exists
 		if !k8serrors.IsNotFound(err) {
-			return err
+			return controllerutil.OperationResultNone, err
 		}
-		return u.Client.Create(u.Ctx, &template)
+		return controllerutil.OperationResultCreated, u.Client.Create(u.Ctx, &template)
 	}
 	if !u.Comparer.DeepEqual(deployedServiceMonitor.Spec, template.Spec) {
 		// Update existing ServiceMonitor for the case that the template changed
 		deployedServiceMonitor.Spec = template.Spec
-		return u.Client.Update(u.Ctx, deployedServiceMonitor)
+		return controllerutil.OperationResultUpdated, u.Client.Update(u.Ctx, deployedServiceMonitor)
 	}
-	return nil
+	return controllerutil.OperationResultNone, nil
 }
 
 // Deletes the ServiceMonitor Deployment
@@ -101,19 +106,13 @@ func (u *ServiceMonitor) DeleteServiceMonitorDeployment(serviceMonitorRef v1alph
 	namespacedName := types.NamespacedName{Name: serviceMonitorRef.Name, Namespace: serviceMonitorRef.Namespace}
 
 	if isHCPMonitor {
-		resource := &rhobsv1.ServiceMonitor{}
-		// Does the resource already exist?
-		err := u.Client.Get(u.Ctx, namespacedName, resource)
-		if err != nil {
-			if !k8serrors.IsNotFound(err) {
-				// If this is an unknown error
-				return err
-			}
-			// Resource doesn't exist, nothing to do
-			return nil
+		// RHOBS ServiceMonitors are deleted without a lookup, as they are only cached on management clusters.
+		// Their CRD is only installed alongside the observability operator
+		resource := &rhobsv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Name: namespacedName.Name, Namespace: namespacedName.Namespace}}
+		if err := u.Client.Delete(u.Ctx, resource); err != nil && !k8serrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return err
 		}
-
-		return u.Client.Delete(u.Ctx, resource)
+		return nil
 	}
 	resource := &monitoringv1.ServiceMonitor{}
 	// Does the resource already exist?
@@ -130,8 +129,8 @@ func (u *ServiceMonitor) DeleteServiceMonitorDeployment(serviceMonitorRef v1alph
 	return u.Client.Delete(u.Ctx, resource)
 }
 
-// TemplateForServiceMonitorResource returns a ServiceMonitor
-func (u *ServiceMonitor) TemplateForServiceMonitorResource(routeURL, blackBoxExporterNamespace string, params map[string][]string, namespacedName types.NamespacedName, clusterID string, owner *metav1.OwnerReference) monitoringv1.ServiceMonitor {
+// TemplateForServiceMonitorResource returns a ServiceMonitor scraping the probes of the blackbox exporter behind exporterService
+func (u *ServiceMonitor) TemplateForServiceMonitorResource(routeURL string, exporterService types.NamespacedName, params map[string][]string, namespacedName types.NamespacedName, clusterID, proxyURL string, owner *metav1.OwnerReference) monitoringv1.ServiceMonitor {
 	return monitoringv1.ServiceMonitor{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            namespacedName.Name,
@@ -149,6 +148,7 @@ func (u *ServiceMonitor) TemplateForServiceMonitorResource(routeURL, blackBoxExp
 					Path:          "/probe",
 					Scheme:        "http",
 					Params:        params,
+					ProxyURL:      optionalString(proxyURL),
 					MetricRelabelConfigs: []*monitoringv1.RelabelConfig{
 						{
 							Replacement: routeURL,
@@ -161,11 +161,11 @@ func (u *ServiceMonitor) TemplateForServiceMonitorResource(routeURL, blackBoxExp
 					},
 				}},
 			Selector: metav1.LabelSelector{
-				MatchLabels: blackboxexporter.GenerateBlackBoxExporterLables(),
+				MatchLabels: blackboxexporter.GenerateLabelsForExporter(exporterService.Name),
 			},
 			NamespaceSelector: monitoringv1.NamespaceSelector{
 				MatchNames: []string{
-					blackBoxExporterNamespace,
+					exporterService.Namespace,
 				},
 			},
 		},
@@ -173,7 +173,7 @@ func (u *ServiceMonitor) TemplateForServiceMonitorResource(routeURL, blackBoxExp
 }
 
 // HyperShiftTemplateForServiceMonitorResource returns a ServiceMonitor for Hypershift
-func (u *ServiceMonitor) HyperShiftTemplateForServiceMonitorResource(routeURL, blackBoxExporterNamespace string, params map[string][]string, namespacedName types.NamespacedName, clusterID string, owner *metav1.OwnerReference) rhobsv1.ServiceMonitor {
+func (u *ServiceMonitor) HyperShiftTemplateForServiceMonitorResource(routeURL string, exporterService types.NamespacedName, params map[string][]string, namespacedName types.NamespacedName, clusterID, proxyURL string, owner *metav1.OwnerReference) rhobsv1.ServiceMonitor {
 	return rhobsv1.ServiceMonitor{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            namespacedName.Name,
@@ -191,6 +191,7 @@ func (u *ServiceMonitor) HyperShiftTemplateForServiceMonitorResource(routeURL, b
 					Path:          "/probe",
 					Scheme:        "http",
 					Params:        params,
+					ProxyURL:      optionalString(proxyURL),
 					MetricRelabelConfigs: []*rhobsv1.RelabelConfig{
 						{
 							Replacement: routeURL,
@@ -203,13 +204,21 @@ func (u *ServiceMonitor) HyperShiftTemplateForServiceMonitorResource(routeURL, b
 					},
 				}},
 			Selector: metav1.LabelSelector{
-				MatchLabels: blackboxexporter.GenerateBlackBoxExporterLables(),
+				MatchLabels: blackboxexporter.GenerateLabelsForExporter(exporterService.Name),
 			},
 			NamespaceSelector: rhobsv1.NamespaceSelector{
 				MatchNames: []string{
-					blackBoxExporterNamespace,
+					exporterService.Namespace,
 				},
 			},
 		},
 	}
 }
+
+// optionalString returns a pointer to s, or nil if it is empty
+func optionalString(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
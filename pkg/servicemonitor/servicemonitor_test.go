@@ -18,6 +18,7 @@ import (
 	rhobsv1 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 type ResourceComparerMockHelper struct {
@@ -41,6 +42,7 @@ var _ = Describe("CR Deployment Handling", func() {
 		serviceMonitor    monitoringv1.ServiceMonitor
 		sm                servicemonitor.ServiceMonitor
 		err               error
+		result            controllerutil.OperationResult
 	)
 	BeforeEach(func() {
 		mockCtrl = gomock.NewController(GinkgoT())
@@ -93,7 +95,7 @@ var _ = Describe("CR Deployment Handling", func() {
 			get.CalledTimes = 1
 		})
 		JustBeforeEach(func() {
-			err = sm.UpdateServiceMonitorDeployment(serviceMonitor)
+			result, err = sm.UpdateServiceMonitorDeployment(serviceMonitor)
 		})
 		When("The Client failed to fetch existing deployments", func() {
 			BeforeEach(func() {
@@ -110,6 +112,7 @@ var _ = Describe("CR Deployment Handling", func() {
 			})
 			It("tryies to creates one", func() {
 				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(controllerutil.OperationResultCreated))
 			})
 			When("an error appeared during the creation", func() {
 				BeforeEach(func() {
@@ -131,6 +134,7 @@ var _ = Describe("CR Deployment Handling", func() {
 				})
 				It("updates the existing deployment", func() {
 					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(controllerutil.OperationResultUpdated))
 				})
 				When("The Client failed to update the existing deployments", func() {
 					BeforeEach(func() {
@@ -147,6 +151,7 @@ var _ = Describe("CR Deployment Handling", func() {
 				})
 				It("does nothing", func() {
 					Expect(err).NotTo(HaveOccurred())
+					Expect(result).To(Equal(controllerutil.OperationResultNone))
 				})
 			})
 		})
@@ -213,13 +218,13 @@ var _ = Describe("CR Deployment Handling", func() {
 
 	Describe("TemplateAndUpdateServiceMonitorDeployment", func() {
 		var (
-			routeURL                  = "https://example.com"
-			blackBoxExporterNamespace = "test-namespace"
-			namespacedName            = serviceMonitorRef
-			clusterID                 = "test-cluster"
-			isHCPMonitor              = false
-			useInsecure               = false
-			owner                     *metav1.OwnerReference
+			routeURL        = "https://example.com"
+			exporterService = types.NamespacedName{Name: "blackbox-exporter", Namespace: "test-namespace"}
+			namespacedName  = serviceMonitorRef
+			clusterID       = "test-cluster"
+			isHCPMonitor    = false
+			module          = "http_2xx"
+			owner           *metav1.OwnerReference
 		)
 
 		BeforeEach(func() {
@@ -239,7 +244,7 @@ var _ = Describe("CR Deployment Handling", func() {
 			})
 			It("should use regular ServiceMonitor template", func() {
 				nsName := types.NamespacedName{Name: namespacedName.Name, Namespace: namespacedName.Namespace}
-				err := sm.TemplateAndUpdateServiceMonitorDeployment(routeURL, blackBoxExporterNamespace, nsName, clusterID, isHCPMonitor, useInsecure, owner)
+				_, err := sm.TemplateAndUpdateServiceMonitorDeployment(routeURL, exporterService, nsName, clusterID, isHCPMonitor, module, "", owner)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -253,21 +258,21 @@ var _ = Describe("CR Deployment Handling", func() {
 			})
 			It("should use HyperShift ServiceMonitor template", func() {
 				nsName := types.NamespacedName{Name: namespacedName.Name, Namespace: namespacedName.Namespace}
-				err := sm.TemplateAndUpdateServiceMonitorDeployment(routeURL, blackBoxExporterNamespace, nsName, clusterID, isHCPMonitor, useInsecure, owner)
+				_, err := sm.TemplateAndUpdateServiceMonitorDeployment(routeURL, exporterService, nsName, clusterID, isHCPMonitor, module, "", owner)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
 
-		When("useInsecure is true", func() {
+		When("the monitor uses a module of its own", func() {
 			BeforeEach(func() {
-				useInsecure = true
+				module = "routemonitor_test_test"
 				get.CalledTimes = 1
 				get.ErrorResponse = consterror.NotFoundErr
 				create.CalledTimes = 1
 			})
-			It("should use insecure module", func() {
+			It("should use the module", func() {
 				nsName := types.NamespacedName{Name: namespacedName.Name, Namespace: namespacedName.Namespace}
-				err := sm.TemplateAndUpdateServiceMonitorDeployment(routeURL, blackBoxExporterNamespace, nsName, clusterID, isHCPMonitor, useInsecure, owner)
+				_, err := sm.TemplateAndUpdateServiceMonitorDeployment(routeURL, exporterService, nsName, clusterID, isHCPMonitor, module, "", owner)
 				Expect(err).NotTo(HaveOccurred())
 			})
 		})
@@ -292,8 +297,9 @@ var _ = Describe("CR Deployment Handling", func() {
 				create.CalledTimes = 1
 			})
 			It("should create a new ServiceMonitor", func() {
-				err := sm.HypershiftUpdateServiceMonitorDeployment(template)
+				result, err := sm.HypershiftUpdateServiceMonitorDeployment(template)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(controllerutil.OperationResultCreated))
 			})
 		})
 
@@ -302,7 +308,7 @@ var _ = Describe("CR Deployment Handling", func() {
 				get.ErrorResponse = consterror.ErrCustomError
 			})
 			It("should return the error", func() {
-				err := sm.HypershiftUpdateServiceMonitorDeployment(template)
+				_, err := sm.HypershiftUpdateServiceMonitorDeployment(template)
 				Expect(err).To(Equal(consterror.ErrCustomError))
 			})
 		})
@@ -314,8 +320,9 @@ var _ = Describe("CR Deployment Handling", func() {
 				update.CalledTimes = 1
 			})
 			It("should update the ServiceMonitor", func() {
-				err := sm.HypershiftUpdateServiceMonitorDeployment(template)
+				result, err := sm.HypershiftUpdateServiceMonitorDeployment(template)
 				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(controllerutil.OperationResultUpdated))
 			})
 		})
 	})
@@ -323,7 +330,7 @@ var _ = Describe("CR Deployment Handling", func() {
 	Describe("TemplateForServiceMonitorResource", func() {
 		It("should create a properly configured ServiceMonitor", func() {
 			routeURL := "https://example.com"
-			blackBoxExporterNamespace := "test-namespace"
+			exporterService := types.NamespacedName{Name: "blackbox-exporter-zone-a", Namespace: "test-namespace"}
 			params := map[string][]string{"module": {"http_2xx"}, "target": {routeURL}}
 			namespacedName := types.NamespacedName{Name: "test", Namespace: "test"}
 			clusterID := "test-cluster"
@@ -333,20 +340,29 @@ var _ = Describe("CR Deployment Handling", func() {
 				Name:       "test-owner",
 			}
 
-			result := sm.TemplateForServiceMonitorResource(routeURL, blackBoxExporterNamespace, params, namespacedName, clusterID, owner)
+			result := sm.TemplateForServiceMonitorResource(routeURL, exporterService, params, namespacedName, clusterID, "", owner)
 
 			Expect(result.Name).To(Equal("test"))
 			Expect(result.Namespace).To(Equal("test"))
 			Expect(result.OwnerReferences).To(HaveLen(1))
 			Expect(result.Spec.Endpoints).To(HaveLen(1))
 			Expect(result.Spec.Endpoints[0].Params).To(Equal(params))
+			Expect(result.Spec.Endpoints[0].ProxyURL).To(BeNil())
+			Expect(result.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "blackbox-exporter-zone-a"}))
+			Expect(result.Spec.NamespaceSelector.MatchNames).To(Equal([]string{"test-namespace"}))
+		})
+		It("should scrape the probes through the monitor's proxy", func() {
+			owner := &metav1.OwnerReference{APIVersion: "v1", Kind: "Test", Name: "test-owner"}
+			result := sm.TemplateForServiceMonitorResource("https://example.com", types.NamespacedName{Name: "blackbox-exporter", Namespace: "test-namespace"},
+				nil, types.NamespacedName{Name: "test", Namespace: "test"}, "test-cluster", "http://proxy.example.com:3128", owner)
+			Expect(result.Spec.Endpoints[0].ProxyURL).To(HaveValue(Equal("http://proxy.example.com:3128")))
 		})
 	})
 
 	Describe("HyperShiftTemplateForServiceMonitorResource", func() {
 		It("should create a properly configured HyperShift ServiceMonitor", func() {
 			routeURL := "https://example.com"
-			blackBoxExporterNamespace := "test-namespace"
+			exporterService := types.NamespacedName{Name: "blackbox-exporter-zone-a", Namespace: "test-namespace"}
 			params := map[string][]string{"module": {"http_2xx"}, "target": {routeURL}}
 			namespacedName := types.NamespacedName{Name: "test", Namespace: "test"}
 			clusterID := "test-cluster"
@@ -356,13 +372,16 @@ var _ = Describe("CR Deployment Handling", func() {
 				Name:       "test-owner",
 			}
 
-			result := sm.HyperShiftTemplateForServiceMonitorResource(routeURL, blackBoxExporterNamespace, params, namespacedName, clusterID, owner)
+			result := sm.HyperShiftTemplateForServiceMonitorResource(routeURL, exporterService, params, namespacedName, clusterID, "http://proxy.example.com:3128", owner)
 
 			Expect(result.Name).To(Equal("test"))
 			Expect(result.Namespace).To(Equal("test"))
 			Expect(result.OwnerReferences).To(HaveLen(1))
 			Expect(result.Spec.Endpoints).To(HaveLen(1))
 			Expect(result.Spec.Endpoints[0].Params).To(Equal(params))
+			Expect(result.Spec.Endpoints[0].ProxyURL).To(HaveValue(Equal("http://proxy.example.com:3128")))
+			Expect(result.Spec.Selector.MatchLabels).To(Equal(map[string]string{"app": "blackbox-exporter-zone-a"}))
+			Expect(result.Spec.NamespaceSelector.MatchNames).To(Equal([]string{"test-namespace"}))
 		})
 	})
 })
@@ -0,0 +1,254 @@
+package slo
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	prometheus "github.com/prometheus/common/model"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/metrics"
+	"github.com/openshift/route-monitor-operator/pkg/servicemonitor"
+)
+
+const (
+	defaultHTTPTimeout = 30 * time.Second
+
+	// queryEndpointPath is the Prometheus HTTP API's instant query endpoint
+	queryEndpointPath = "/api/v1/query"
+
+	// burnRateWindow is the period over which the current burn rate is calculated
+	burnRateWindow = "1h"
+)
+
+// Config configures the optional Prometheus (or Thanos Querier) query client
+type Config struct {
+	// QueryURL is the base URL of the Prometheus HTTP API. SLO status is not reported when empty
+	QueryURL string
+	// BearerTokenFile is read on every query and sent as bearer token when set
+	BearerTokenFile string
+	// CAFile is used to verify the query endpoint's certificate when set
+	CAFile string
+	// Window is the period over which availability and the remaining error budget are calculated
+	Window string
+	// ResyncInterval is the wait period between queries for the same monitor
+	ResyncInterval time.Duration
+}
+
+// DefaultConfig returns the default query client configuration, with SLO status reporting disabled
+func DefaultConfig() Config {
+	return Config{
+		Window:         "28d",
+		ResyncInterval: 5 * time.Minute,
+	}
+}
+
+// Enabled returns whether SLO status should be reported
+func (c Config) Enabled() bool {
+	return c.QueryURL != ""
+}
+
+// Validate returns an error if the configuration is invalid
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if !strings.HasPrefix(c.QueryURL, "http://") && !strings.HasPrefix(c.QueryURL, "https://") {
+		return fmt.Errorf("SLO query URL must be a fully qualified URL starting with 'http://' or 'https://', got %q", c.QueryURL)
+	}
+	if _, err := prometheus.ParseDuration(c.Window); err != nil {
+		return fmt.Errorf("invalid SLO window %q: %w", c.Window, err)
+	}
+	if c.ResyncInterval <= 0 {
+		return fmt.Errorf("SLO resync interval must be positive, got %s", c.ResyncInterval)
+	}
+	return nil
+}
+
+// Client queries the service level observed by the blackbox exporter's probes
+type Client struct {
+	baseURL         string
+	httpClient      *http.Client
+	bearerTokenFile string
+	window          string
+	resyncInterval  time.Duration
+}
+
+// NewClient creates a new query client from a valid Config
+func NewClient(cfg Config) (*Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if cfg.CAFile != "" {
+		ca, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SLO query CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in SLO query CA file %s", cfg.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(cfg.QueryURL, "/"),
+		httpClient: &http.Client{
+			Timeout:   defaultHTTPTimeout,
+			Transport: transport,
+		},
+		bearerTokenFile: cfg.BearerTokenFile,
+		window:          cfg.Window,
+		resyncInterval:  cfg.ResyncInterval,
+	}, nil
+}
+
+// ResyncInterval returns the wait period between queries for the same monitor
+func (c *Client) ResyncInterval() time.Duration {
+	return c.resyncInterval
+}
+
+// ShouldResync returns whether the recorded status is older than the resync interval
+func (c *Client) ShouldResync(status *v1alpha1.SloStatus) bool {
+	return status == nil || time.Since(status.LastUpdateTime.Time) >= c.resyncInterval
+}
+
+// GetSLOStatus queries the service level observed for url against the target availability ratio (e.g. "0.995").
+// It returns nil if Prometheus holds no probe results for url
+func (c *Client) GetSLOStatus(ctx context.Context, url, target string) (*v1alpha1.SloStatus, error) {
+	targetRatio, err := strconv.ParseFloat(target, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target availability %q: %w", target, err)
+	}
+	selector := ProbeSelector(url)
+
+	availability, found, err := c.query(ctx, AvailabilityQuery(selector, c.window))
+	if err != nil || !found {
+		return nil, err
+	}
+	recentAvailability, found, err := c.query(ctx, AvailabilityQuery(selector, burnRateWindow))
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		// no probe results recently, the current burn rate is unknown
+		recentAvailability = math.NaN()
+	}
+	lastProbe, found, err := c.query(ctx, fmt.Sprintf("min(probe_success{%s})", selector))
+	if err != nil {
+		return nil, err
+	}
+
+	errorBudget := 1 - targetRatio
+	status := &v1alpha1.SloStatus{
+		Window:                      c.window,
+		AvailabilityPercent:         formatFloat(availability*100, 3),
+		ErrorBudgetRemainingPercent: formatFloat((1-(1-availability)/errorBudget)*100, 2),
+		LastUpdateTime:              metav1.Now(),
+	}
+	if !math.IsNaN(recentAvailability) {
+		status.BurnRate = formatFloat((1-recentAvailability)/errorBudget, 2)
+	}
+	if found {
+		success := lastProbe == 1
+		status.LastProbeSuccess = &success
+	}
+	return status, nil
+}
+
+// ProbeSelector returns the label selector matching the probe results for url
+func ProbeSelector(url string) string {
+	return fmt.Sprintf("%s=%s", servicemonitor.UrlLabelName, strconv.Quote(url))
+}
+
+// AvailabilityQuery returns the PromQL ratio of successful probes matching selector over the window
+func AvailabilityQuery(selector, window string) string {
+	return fmt.Sprintf("sum(sum_over_time(probe_success{%[1]s}[%[2]s])) / sum(count_over_time(probe_success{%[1]s}[%[2]s]))", selector, window)
+}
+
+func formatFloat(f float64, precision int) string {
+	return strconv.FormatFloat(f, 'f', precision, 64)
+}
+
+// queryResponse is the subset of the Prometheus HTTP API's instant query response used by the client
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		ResultType string `json:"resultType"`
+		Result     []struct {
+			Value []any `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// query runs an instant query expected to return a single sample, reporting whether a sample was found
+func (c *Client) query(ctx context.Context, query string) (float64, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+queryEndpointPath+"?"+url.Values{"query": {query}}.Encode(), nil)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to create request: %w", err)
+	}
+	if c.bearerTokenFile != "" {
+		token, err := os.ReadFile(c.bearerTokenFile)
+		if err != nil {
+			return 0, false, fmt.Errorf("failed to read bearer token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	statusCode := 0
+	if resp != nil {
+		statusCode = resp.StatusCode
+	}
+	metrics.ObserveAPIRequest(metrics.APIPrometheus, "query", start, statusCode, err)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to query Prometheus: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to read response: %w", err)
+	}
+	var result queryResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return 0, false, fmt.Errorf("query failed with status %d: failed to parse response: %w", resp.StatusCode, err)
+	}
+	if result.Status != "success" {
+		return 0, false, fmt.Errorf("query failed with status %d: %s", resp.StatusCode, result.Error)
+	}
+	if result.Data.ResultType != "vector" {
+		return 0, false, fmt.Errorf("unexpected result type %q", result.Data.ResultType)
+	}
+	if len(result.Data.Result) == 0 {
+		return 0, false, nil
+	}
+
+	sample := result.Data.Result[0].Value
+	if len(sample) != 2 {
+		return 0, false, fmt.Errorf("unexpected sample %v", sample)
+	}
+	value, ok := sample[1].(string)
+	if !ok {
+		return 0, false, fmt.Errorf("unexpected sample value %v", sample[1])
+	}
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to parse sample value: %w", err)
+	}
+	if math.IsNaN(f) {
+		return 0, false, nil
+	}
+	return f, true, nil
+}
@@ -0,0 +1,280 @@
+package slo
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift/route-monitor-operator/api/v1alpha1"
+)
+
+// newPrometheusServer returns a stand-in for the Prometheus HTTP API, answering instant queries
+// with the sample registered for the first window found in the query
+func newPrometheusServer(t *testing.T, samples map[string]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != queryEndpointPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query().Get("query")
+		if !strings.Contains(query, `probe_url="https://example.com/health"`) {
+			fmt.Fprint(w, `{"status":"error","errorType":"bad_data","error":"unexpected selector"}`)
+			return
+		}
+
+		key := "instant"
+		for _, window := range []string{"[28d]", "[1h]"} {
+			if strings.Contains(query, window) {
+				key = window
+				break
+			}
+		}
+		value, ok := samples[key]
+		if !ok {
+			fmt.Fprint(w, `{"status":"success","data":{"resultType":"vector","result":[]}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1700000000,"%s"]}]}}`, value)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestClient(t *testing.T, queryURL string) *Client {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("test-token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	cfg := DefaultConfig()
+	cfg.QueryURL = queryURL
+	cfg.BearerTokenFile = tokenFile
+	client, err := NewClient(cfg)
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
+
+func TestClient_GetSLOStatus(t *testing.T) {
+	success, failure := true, false
+	tests := []struct {
+		name    string
+		samples map[string]string
+		want    *v1alpha1.SloStatus
+		wantErr bool
+	}{
+		{
+			name: "healthy monitor",
+			samples: map[string]string{
+				"[28d]":   "0.999",
+				"[1h]":    "1",
+				"instant": "1",
+			},
+			want: &v1alpha1.SloStatus{
+				Window:                      "28d",
+				AvailabilityPercent:         "99.900",
+				ErrorBudgetRemainingPercent: "80.00",
+				BurnRate:                    "0.00",
+				LastProbeSuccess:            &success,
+			},
+		},
+		{
+			name: "exhausted error budget",
+			samples: map[string]string{
+				"[28d]":   "0.99",
+				"[1h]":    "0.9",
+				"instant": "0",
+			},
+			want: &v1alpha1.SloStatus{
+				Window:                      "28d",
+				AvailabilityPercent:         "99.000",
+				ErrorBudgetRemainingPercent: "-100.00",
+				BurnRate:                    "20.00",
+				LastProbeSuccess:            &failure,
+			},
+		},
+		{
+			name: "no recent probes",
+			samples: map[string]string{
+				"[28d]": "1",
+			},
+			want: &v1alpha1.SloStatus{
+				Window:                      "28d",
+				AvailabilityPercent:         "100.000",
+				ErrorBudgetRemainingPercent: "100.00",
+			},
+		},
+		{
+			name:    "no probe results",
+			samples: map[string]string{},
+			want:    nil,
+		},
+		{
+			name: "unparsable sample",
+			samples: map[string]string{
+				"[28d]": "not-a-number",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newPrometheusServer(t, tt.samples)
+			client := newTestClient(t, server.URL)
+
+			got, err := client.GetSLOStatus(context.Background(), "https://example.com/health", "0.995")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("expected no SLO status, got %+v", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatalf("expected SLO status %+v, got nil", tt.want)
+			}
+			if got.LastUpdateTime.IsZero() {
+				t.Errorf("expected LastUpdateTime to be set")
+			}
+			if got.Window != tt.want.Window || got.AvailabilityPercent != tt.want.AvailabilityPercent ||
+				got.ErrorBudgetRemainingPercent != tt.want.ErrorBudgetRemainingPercent || got.BurnRate != tt.want.BurnRate ||
+				derefBool(got.LastProbeSuccess) != derefBool(tt.want.LastProbeSuccess) {
+				t.Errorf("expected SLO status %+v (last probe %s), got %+v (last probe %s)",
+					*tt.want, derefBool(tt.want.LastProbeSuccess), *got, derefBool(got.LastProbeSuccess))
+			}
+		})
+	}
+}
+
+func derefBool(b *bool) string {
+	if b == nil {
+		return "<nil>"
+	}
+	return fmt.Sprint(*b)
+}
+
+func TestClient_GetSLOStatus_queryErrors(t *testing.T) {
+	server := newPrometheusServer(t, map[string]string{"[28d]": "1"})
+
+	t.Run("query rejected by Prometheus", func(t *testing.T) {
+		client := newTestClient(t, server.URL)
+		if _, err := client.GetSLOStatus(context.Background(), "https://other.example.com", "0.995"); err == nil {
+			t.Errorf("expected an error for a failed query")
+		}
+	})
+
+	t.Run("unauthorized", func(t *testing.T) {
+		cfg := DefaultConfig()
+		cfg.QueryURL = server.URL
+		client, err := NewClient(cfg)
+		if err != nil {
+			t.Fatalf("failed to create client: %v", err)
+		}
+		if _, err := client.GetSLOStatus(context.Background(), "https://example.com/health", "0.995"); err == nil {
+			t.Errorf("expected an error for an unauthorized query")
+		}
+	})
+}
+
+func TestClient_ShouldResync(t *testing.T) {
+	client, err := NewClient(Config{QueryURL: "http://localhost:9090", Window: "28d", ResyncInterval: 5 * time.Minute})
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		status *v1alpha1.SloStatus
+		want   bool
+	}{
+		{
+			name:   "never queried",
+			status: nil,
+			want:   true,
+		},
+		{
+			name:   "recently queried",
+			status: &v1alpha1.SloStatus{LastUpdateTime: metav1.NewTime(time.Now().Add(-time.Minute))},
+			want:   false,
+		},
+		{
+			name:   "queried before the resync interval",
+			status: &v1alpha1.SloStatus{LastUpdateTime: metav1.NewTime(time.Now().Add(-10 * time.Minute))},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.ShouldResync(tt.status); got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(cfg *Config)
+		wantErr bool
+	}{
+		{
+			name:    "disabled by default",
+			modify:  func(cfg *Config) {},
+			wantErr: false,
+		},
+		{
+			name:    "valid query URL",
+			modify:  func(cfg *Config) { cfg.QueryURL = "https://thanos-querier.openshift-monitoring.svc:9091" },
+			wantErr: false,
+		},
+		{
+			name:    "query URL without scheme",
+			modify:  func(cfg *Config) { cfg.QueryURL = "thanos-querier.openshift-monitoring.svc:9091" },
+			wantErr: true,
+		},
+		{
+			name: "invalid window",
+			modify: func(cfg *Config) {
+				cfg.QueryURL = "http://localhost:9090"
+				cfg.Window = "four weeks"
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero resync interval",
+			modify: func(cfg *Config) {
+				cfg.QueryURL = "http://localhost:9090"
+				cfg.ResyncInterval = 0
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			tt.modify(&cfg)
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
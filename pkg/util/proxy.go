@@ -0,0 +1,90 @@
+package util
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"golang.org/x/net/http/httpproxy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// ClusterProxyName is the name of the cluster-wide Proxy object
+const ClusterProxyName = "cluster"
+
+// GetClusterProxy returns the status of the cluster-wide Proxy, which holds the proxy settings in effect including the
+// cluster's own networks in NoProxy. An empty status is returned on clusters without a Proxy object
+func GetClusterProxy(ctx context.Context, kclient client.Reader) (configv1.ProxyStatus, error) {
+	proxy := configv1.Proxy{}
+	if err := kclient.Get(ctx, client.ObjectKey{Name: ClusterProxyName}, &proxy); err != nil {
+		if k8serrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return configv1.ProxyStatus{}, nil
+		}
+		return configv1.ProxyStatus{}, err
+	}
+	return proxy.Status, nil
+}
+
+// IsProxyConfigured reports whether the cluster-wide Proxy routes any requests through a proxy
+func IsProxyConfigured(status configv1.ProxyStatus) bool {
+	return status.HTTPProxy != "" || status.HTTPSProxy != ""
+}
+
+// ProxyEnvVars returns the HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables of the cluster-wide Proxy
+func ProxyEnvVars(status configv1.ProxyStatus) []corev1.EnvVar {
+	if !IsProxyConfigured(status) {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: "HTTP_PROXY", Value: status.HTTPProxy},
+		{Name: "HTTPS_PROXY", Value: status.HTTPSProxy},
+		{Name: "NO_PROXY", Value: status.NoProxy},
+	}
+}
+
+// ClusterProxyFunc returns a proxy function for an http.Transport, which routes each request through the cluster-wide Proxy
+// configured at the time of the request
+func ClusterProxyFunc(kclient client.Reader) func(*http.Request) (*url.URL, error) {
+	return func(req *http.Request) (*url.URL, error) {
+		status, err := GetClusterProxy(req.Context(), kclient)
+		if err != nil {
+			return nil, err
+		}
+		cfg := httpproxy.Config{HTTPProxy: status.HTTPProxy, HTTPSProxy: status.HTTPSProxy, NoProxy: status.NoProxy}
+		return cfg.ProxyFunc()(req.URL)
+	}
+}
+
+// ProxyTransport returns a copy of the default transport using the given proxy function
+func ProxyTransport(proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = proxy
+	return transport
+}
+
+// ClusterProxyChanged filters the updates of the cluster-wide Proxy which change the proxy settings in effect
+var ClusterProxyChanged = predicate.And(
+	predicate.NewPredicateFuncs(func(o client.Object) bool {
+		return o.GetName() == ClusterProxyName
+	}),
+	predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldProxy, ok := e.ObjectOld.(*configv1.Proxy)
+			if !ok {
+				return false
+			}
+			newProxy, ok := e.ObjectNew.(*configv1.Proxy)
+			if !ok {
+				return false
+			}
+			return !equality.Semantic.DeepEqual(oldProxy.Status, newProxy.Status)
+		},
+	},
+)
@@ -10,11 +10,12 @@
 package controllers
 
 import (
+	context "context"
 	reflect "reflect"
+	time "time"
 
 	v1beta1 "github.com/openshift/hypershift/api/hypershift/v1beta1"
 	v1alpha1 "github.com/openshift/route-monitor-operator/api/v1alpha1"
-	blackboxexporter "github.com/openshift/route-monitor-operator/pkg/consts/blackboxexporter"
 	reconcile "github.com/openshift/route-monitor-operator/pkg/util/reconcile"
 	v1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	v10 "github.com/rhobs/obo-prometheus-operator/pkg/apis/monitoring/v1"
@@ -22,6 +23,7 @@ import (
 	v11 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	types "k8s.io/apimachinery/pkg/types"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
+	controllerutil "sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 )
 
 // MockMonitorResourceHandler is a mock of MonitorResourceHandler interface.
@@ -150,12 +152,11 @@ func (mr *MockMonitorResourceHandlerMockRecorder) SetFinalizer(o, finalizerKey a
 }
 
 // SetResourceReference mocks base method.
-func (m *MockMonitorResourceHandler) SetResourceReference(reference *v1alpha1.NamespacedName, target types.NamespacedName) (bool, error) {
+func (m *MockMonitorResourceHandler) SetResourceReference(reference *v1alpha1.NamespacedName, target types.NamespacedName) bool {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SetResourceReference", reference, target)
 	ret0, _ := ret[0].(bool)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	return ret0
 }
 
 // SetResourceReference indicates an expected call of SetResourceReference.
@@ -232,11 +233,12 @@ func (mr *MockServiceMonitorHandlerMockRecorder) DeleteServiceMonitorDeployment(
 }
 
 // HypershiftUpdateServiceMonitorDeployment mocks base method.
-func (m *MockServiceMonitorHandler) HypershiftUpdateServiceMonitorDeployment(template v10.ServiceMonitor) error {
+func (m *MockServiceMonitorHandler) HypershiftUpdateServiceMonitorDeployment(template v10.ServiceMonitor) (controllerutil.OperationResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "HypershiftUpdateServiceMonitorDeployment", template)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(controllerutil.OperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // HypershiftUpdateServiceMonitorDeployment indicates an expected call of HypershiftUpdateServiceMonitorDeployment.
@@ -246,25 +248,27 @@ func (mr *MockServiceMonitorHandlerMockRecorder) HypershiftUpdateServiceMonitorD
 }
 
 // TemplateAndUpdateServiceMonitorDeployment mocks base method.
-func (m *MockServiceMonitorHandler) TemplateAndUpdateServiceMonitorDeployment(url, blackBoxExporterNamespace string, namespacedName types.NamespacedName, clusterID string, hcp, useInsecure bool, owner *v11.OwnerReference) error {
+func (m *MockServiceMonitorHandler) TemplateAndUpdateServiceMonitorDeployment(url string, exporterService, namespacedName types.NamespacedName, clusterID string, hcp bool, module, proxyURL string, owner *v11.OwnerReference) (controllerutil.OperationResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "TemplateAndUpdateServiceMonitorDeployment", url, blackBoxExporterNamespace, namespacedName, clusterID, hcp, useInsecure, owner)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "TemplateAndUpdateServiceMonitorDeployment", url, exporterService, namespacedName, clusterID, hcp, module, proxyURL, owner)
+	ret0, _ := ret[0].(controllerutil.OperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // TemplateAndUpdateServiceMonitorDeployment indicates an expected call of TemplateAndUpdateServiceMonitorDeployment.
-func (mr *MockServiceMonitorHandlerMockRecorder) TemplateAndUpdateServiceMonitorDeployment(url, blackBoxExporterNamespace, namespacedName, clusterID, hcp, useInsecure, owner any) *gomock.Call {
+func (mr *MockServiceMonitorHandlerMockRecorder) TemplateAndUpdateServiceMonitorDeployment(url, exporterService, namespacedName, clusterID, hcp, module, proxyURL, owner any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TemplateAndUpdateServiceMonitorDeployment", reflect.TypeOf((*MockServiceMonitorHandler)(nil).TemplateAndUpdateServiceMonitorDeployment), url, blackBoxExporterNamespace, namespacedName, clusterID, hcp, useInsecure, owner)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TemplateAndUpdateServiceMonitorDeployment", reflect.TypeOf((*MockServiceMonitorHandler)(nil).TemplateAndUpdateServiceMonitorDeployment), url, exporterService, namespacedName, clusterID, hcp, module, proxyURL, owner)
 }
 
 // UpdateServiceMonitorDeployment mocks base method.
-func (m *MockServiceMonitorHandler) UpdateServiceMonitorDeployment(template v1.ServiceMonitor) error {
+func (m *MockServiceMonitorHandler) UpdateServiceMonitorDeployment(template v1.ServiceMonitor) (controllerutil.OperationResult, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateServiceMonitorDeployment", template)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(controllerutil.OperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateServiceMonitorDeployment indicates an expected call of UpdateServiceMonitorDeployment.
@@ -297,31 +301,98 @@ func (m *MockPrometheusRuleHandler) EXPECT() *MockPrometheusRuleHandlerMockRecor
 }
 
 // DeletePrometheusRuleDeployment mocks base method.
-func (m *MockPrometheusRuleHandler) DeletePrometheusRuleDeployment(prometheusRuleRef v1alpha1.NamespacedName) error {
+func (m *MockPrometheusRuleHandler) DeletePrometheusRuleDeployment(prometheusRuleRef v1alpha1.NamespacedName, isHCPMonitor bool) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeletePrometheusRuleDeployment", prometheusRuleRef)
+	ret := m.ctrl.Call(m, "DeletePrometheusRuleDeployment", prometheusRuleRef, isHCPMonitor)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeletePrometheusRuleDeployment indicates an expected call of DeletePrometheusRuleDeployment.
-func (mr *MockPrometheusRuleHandlerMockRecorder) DeletePrometheusRuleDeployment(prometheusRuleRef any) *gomock.Call {
+func (mr *MockPrometheusRuleHandlerMockRecorder) DeletePrometheusRuleDeployment(prometheusRuleRef, isHCPMonitor any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePrometheusRuleDeployment", reflect.TypeOf((*MockPrometheusRuleHandler)(nil).DeletePrometheusRuleDeployment), prometheusRuleRef)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePrometheusRuleDeployment", reflect.TypeOf((*MockPrometheusRuleHandler)(nil).DeletePrometheusRuleDeployment), prometheusRuleRef, isHCPMonitor)
 }
 
 // UpdatePrometheusRuleDeployment mocks base method.
-func (m *MockPrometheusRuleHandler) UpdatePrometheusRuleDeployment(template v1.PrometheusRule) error {
+func (m *MockPrometheusRuleHandler) UpdatePrometheusRuleDeployment(template v1.PrometheusRule, isHCPMonitor bool) (controllerutil.OperationResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePrometheusRuleDeployment", template)
+	ret := m.ctrl.Call(m, "UpdatePrometheusRuleDeployment", template, isHCPMonitor)
+	ret0, _ := ret[0].(controllerutil.OperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePrometheusRuleDeployment indicates an expected call of UpdatePrometheusRuleDeployment.
+func (mr *MockPrometheusRuleHandlerMockRecorder) UpdatePrometheusRuleDeployment(template, isHCPMonitor any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrometheusRuleDeployment", reflect.TypeOf((*MockPrometheusRuleHandler)(nil).UpdatePrometheusRuleDeployment), template, isHCPMonitor)
+}
+
+// MockDashboardHandler is a mock of DashboardHandler interface.
+type MockDashboardHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockDashboardHandlerMockRecorder
+}
+
+// MockDashboardHandlerMockRecorder is the mock recorder for MockDashboardHandler.
+type MockDashboardHandlerMockRecorder struct {
+	mock *MockDashboardHandler
+}
+
+// NewMockDashboardHandler creates a new mock instance.
+func NewMockDashboardHandler(ctrl *gomock.Controller) *MockDashboardHandler {
+	mock := &MockDashboardHandler{ctrl: ctrl}
+	mock.recorder = &MockDashboardHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDashboardHandler) EXPECT() *MockDashboardHandlerMockRecorder {
+	return m.recorder
+}
+
+// DeleteDashboardDeployment mocks base method.
+func (m *MockDashboardHandler) DeleteDashboardDeployment(dashboardRef v1alpha1.NamespacedName) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteDashboardDeployment", dashboardRef)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// UpdatePrometheusRuleDeployment indicates an expected call of UpdatePrometheusRuleDeployment.
-func (mr *MockPrometheusRuleHandlerMockRecorder) UpdatePrometheusRuleDeployment(template any) *gomock.Call {
+// DeleteDashboardDeployment indicates an expected call of DeleteDashboardDeployment.
+func (mr *MockDashboardHandlerMockRecorder) DeleteDashboardDeployment(dashboardRef any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDashboardDeployment", reflect.TypeOf((*MockDashboardHandler)(nil).DeleteDashboardDeployment), dashboardRef)
+}
+
+// Enabled mocks base method.
+func (m *MockDashboardHandler) Enabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Enabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Enabled indicates an expected call of Enabled.
+func (mr *MockDashboardHandlerMockRecorder) Enabled() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePrometheusRuleDeployment", reflect.TypeOf((*MockPrometheusRuleHandler)(nil).UpdatePrometheusRuleDeployment), template)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Enabled", reflect.TypeOf((*MockDashboardHandler)(nil).Enabled))
+}
+
+// TemplateAndUpdateDashboardDeployment mocks base method.
+func (m *MockDashboardHandler) TemplateAndUpdateDashboardDeployment(url, percent string, namespacedName types.NamespacedName, owner *v11.OwnerReference) (controllerutil.OperationResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TemplateAndUpdateDashboardDeployment", url, percent, namespacedName, owner)
+	ret0, _ := ret[0].(controllerutil.OperationResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TemplateAndUpdateDashboardDeployment indicates an expected call of TemplateAndUpdateDashboardDeployment.
+func (mr *MockDashboardHandlerMockRecorder) TemplateAndUpdateDashboardDeployment(url, percent, namespacedName, owner any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TemplateAndUpdateDashboardDeployment", reflect.TypeOf((*MockDashboardHandler)(nil).TemplateAndUpdateDashboardDeployment), url, percent, namespacedName, owner)
 }
 
 // MockBlackBoxExporterHandler is a mock of BlackBoxExporterHandler interface.
@@ -347,59 +418,97 @@ func (m *MockBlackBoxExporterHandler) EXPECT() *MockBlackBoxExporterHandlerMockR
 	return m.recorder
 }
 
-// EnsureBlackBoxExporterResourcesAbsent mocks base method.
-func (m *MockBlackBoxExporterHandler) EnsureBlackBoxExporterResourcesAbsent() error {
+// GetExporterService mocks base method.
+func (m *MockBlackBoxExporterHandler) GetExporterService(exporterRef string) (types.NamespacedName, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EnsureBlackBoxExporterResourcesAbsent")
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetExporterService", exporterRef)
+	ret0, _ := ret[0].(types.NamespacedName)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// EnsureBlackBoxExporterResourcesAbsent indicates an expected call of EnsureBlackBoxExporterResourcesAbsent.
-func (mr *MockBlackBoxExporterHandlerMockRecorder) EnsureBlackBoxExporterResourcesAbsent() *gomock.Call {
+// GetExporterService indicates an expected call of GetExporterService.
+func (mr *MockBlackBoxExporterHandlerMockRecorder) GetExporterService(exporterRef any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureBlackBoxExporterResourcesAbsent", reflect.TypeOf((*MockBlackBoxExporterHandler)(nil).EnsureBlackBoxExporterResourcesAbsent))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetExporterService", reflect.TypeOf((*MockBlackBoxExporterHandler)(nil).GetExporterService), exporterRef)
 }
 
-// EnsureBlackBoxExporterResourcesExist mocks base method.
-func (m *MockBlackBoxExporterHandler) EnsureBlackBoxExporterResourcesExist() error {
+// ValidateProbeReferences mocks base method.
+func (m *MockBlackBoxExporterHandler) ValidateProbeReferences(namespace string, probe v1alpha1.ProbeSpec) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "EnsureBlackBoxExporterResourcesExist")
+	ret := m.ctrl.Call(m, "ValidateProbeReferences", namespace, probe)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// EnsureBlackBoxExporterResourcesExist indicates an expected call of EnsureBlackBoxExporterResourcesExist.
-func (mr *MockBlackBoxExporterHandlerMockRecorder) EnsureBlackBoxExporterResourcesExist() *gomock.Call {
+// ValidateProbeReferences indicates an expected call of ValidateProbeReferences.
+func (mr *MockBlackBoxExporterHandlerMockRecorder) ValidateProbeReferences(namespace, probe any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureBlackBoxExporterResourcesExist", reflect.TypeOf((*MockBlackBoxExporterHandler)(nil).EnsureBlackBoxExporterResourcesExist))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ValidateProbeReferences", reflect.TypeOf((*MockBlackBoxExporterHandler)(nil).ValidateProbeReferences), namespace, probe)
+}
+
+// MockSLOStatusHandler is a mock of SLOStatusHandler interface.
+type MockSLOStatusHandler struct {
+	ctrl     *gomock.Controller
+	recorder *MockSLOStatusHandlerMockRecorder
+}
+
+// MockSLOStatusHandlerMockRecorder is the mock recorder for MockSLOStatusHandler.
+type MockSLOStatusHandlerMockRecorder struct {
+	mock *MockSLOStatusHandler
 }
 
-// GetBlackBoxExporterNamespace mocks base method.
-func (m *MockBlackBoxExporterHandler) GetBlackBoxExporterNamespace() string {
+// NewMockSLOStatusHandler creates a new mock instance.
+func NewMockSLOStatusHandler(ctrl *gomock.Controller) *MockSLOStatusHandler {
+	mock := &MockSLOStatusHandler{ctrl: ctrl}
+	mock.recorder = &MockSLOStatusHandlerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSLOStatusHandler) EXPECT() *MockSLOStatusHandlerMockRecorder {
+	return m.recorder
+}
+
+// GetSLOStatus mocks base method.
+func (m *MockSLOStatusHandler) GetSLOStatus(ctx context.Context, url, target string) (*v1alpha1.SloStatus, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetBlackBoxExporterNamespace")
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetSLOStatus", ctx, url, target)
+	ret0, _ := ret[0].(*v1alpha1.SloStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSLOStatus indicates an expected call of GetSLOStatus.
+func (mr *MockSLOStatusHandlerMockRecorder) GetSLOStatus(ctx, url, target any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSLOStatus", reflect.TypeOf((*MockSLOStatusHandler)(nil).GetSLOStatus), ctx, url, target)
+}
+
+// ResyncInterval mocks base method.
+func (m *MockSLOStatusHandler) ResyncInterval() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResyncInterval")
+	ret0, _ := ret[0].(time.Duration)
 	return ret0
 }
 
-// GetBlackBoxExporterNamespace indicates an expected call of GetBlackBoxExporterNamespace.
-func (mr *MockBlackBoxExporterHandlerMockRecorder) GetBlackBoxExporterNamespace() *gomock.Call {
+// ResyncInterval indicates an expected call of ResyncInterval.
+func (mr *MockSLOStatusHandlerMockRecorder) ResyncInterval() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBlackBoxExporterNamespace", reflect.TypeOf((*MockBlackBoxExporterHandler)(nil).GetBlackBoxExporterNamespace))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResyncInterval", reflect.TypeOf((*MockSLOStatusHandler)(nil).ResyncInterval))
 }
 
-// ShouldDeleteBlackBoxExporterResources mocks base method.
-func (m *MockBlackBoxExporterHandler) ShouldDeleteBlackBoxExporterResources() (blackboxexporter.ShouldDeleteBlackBoxExporter, error) {
+// ShouldResync mocks base method.
+func (m *MockSLOStatusHandler) ShouldResync(status *v1alpha1.SloStatus) bool {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ShouldDeleteBlackBoxExporterResources")
-	ret0, _ := ret[0].(blackboxexporter.ShouldDeleteBlackBoxExporter)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "ShouldResync", status)
+	ret0, _ := ret[0].(bool)
+	return ret0
 }
 
-// ShouldDeleteBlackBoxExporterResources indicates an expected call of ShouldDeleteBlackBoxExporterResources.
-func (mr *MockBlackBoxExporterHandlerMockRecorder) ShouldDeleteBlackBoxExporterResources() *gomock.Call {
+// ShouldResync indicates an expected call of ShouldResync.
+func (mr *MockSLOStatusHandlerMockRecorder) ShouldResync(status any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldDeleteBlackBoxExporterResources", reflect.TypeOf((*MockBlackBoxExporterHandler)(nil).ShouldDeleteBlackBoxExporterResources))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ShouldResync", reflect.TypeOf((*MockSLOStatusHandler)(nil).ShouldResync), status)
 }
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	rmov1alpha1 "github.com/openshift/route-monitor-operator/api/v1alpha1"
+	"github.com/openshift/route-monitor-operator/pkg/alert"
+	"github.com/openshift/route-monitor-operator/pkg/openslo"
+	"github.com/openshift/route-monitor-operator/pkg/render"
+	"github.com/openshift/route-monitor-operator/pkg/slo"
+)
+
+// subcommands are run instead of the operator when their name is passed as first argument
+var subcommands = map[string]func(args []string) error{
+	"openslo": runOpenSLO,
+	"render": func(args []string) error {
+		return runRender(args, os.Stdin, os.Stdout)
+	},
+}
+
+const opensloUsage = `Usage:
+  manager openslo export [--namespace <namespace>] [--window <window>] [--cluster-domain <domain>]
+      Prints the SLOs of all RouteMonitors and ClusterUrlMonitors as OpenSLO documents
+  manager openslo import [-f <file>] [--namespace <namespace>] [--cluster-domain <domain>]
+      Prints the RouteMonitors and ClusterUrlMonitors described by OpenSLO documents`
+
+func runOpenSLO(args []string) error {
+	if len(args) == 0 {
+		return errors.New(opensloUsage)
+	}
+	switch args[0] {
+	case "export":
+		return runOpenSLOExport(args[1:], os.Stdout, os.Stderr)
+	case "import":
+		return runOpenSLOImport(args[1:], os.Stdin, os.Stdout)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], opensloUsage)
+	}
+}
+
+func runOpenSLOExport(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("openslo export", flag.ContinueOnError)
+	namespace := flags.String("namespace", "", "Only export monitors in this namespace. Defaults to all namespaces.")
+	window := flags.String("window", slo.DefaultConfig().Window, "Rolling time window of the exported SLOs.")
+	clusterDomain := flags.String("cluster-domain", "", "Domain used to build the URLs of ClusterUrlMonitors. Defaults to the domain of the cluster's API server.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		return err
+	}
+	routeMonitors := rmov1alpha1.RouteMonitorList{}
+	if err := c.List(ctx, &routeMonitors, client.InNamespace(*namespace)); err != nil {
+		return fmt.Errorf("failed to list RouteMonitors: %w", err)
+	}
+	clusterUrlMonitors := rmov1alpha1.ClusterUrlMonitorList{}
+	if err := c.List(ctx, &clusterUrlMonitors, client.InNamespace(*namespace)); err != nil {
+		return fmt.Errorf("failed to list ClusterUrlMonitors: %w", err)
+	}
+
+	docs := []openslo.Document{}
+	export := func(monitor client.Object, url string) {
+		sloDoc, sliDoc, err := openslo.Export(monitor, url, *window)
+		if err != nil {
+			fmt.Fprintf(stderr, "skipping %s/%s: %v\n", monitor.GetNamespace(), monitor.GetName(), err)
+			return
+		}
+		docs = append(docs, sloDoc, sliDoc)
+	}
+	for i := range routeMonitors.Items {
+		routeMonitor := &routeMonitors.Items[i]
+		export(routeMonitor, routeMonitor.Status.RouteURL)
+	}
+	for i := range clusterUrlMonitors.Items {
+		clusterUrlMonitor := &clusterUrlMonitors.Items[i]
+		// the domains of hosted clusters are not known outside of their management cluster
+		if clusterUrlMonitor.Spec.DomainRef == rmov1alpha1.ClusterDomainRefHCP {
+			fmt.Fprintf(stderr, "skipping %s/%s: HostedControlPlane ClusterUrlMonitors are not supported\n", clusterUrlMonitor.Namespace, clusterUrlMonitor.Name)
+			continue
+		}
+		if *clusterDomain == "" {
+			if *clusterDomain, err = getInfraClusterDomain(ctx, c); err != nil {
+				return err
+			}
+		}
+		export(clusterUrlMonitor, clusterUrlMonitor.Spec.URL(*clusterDomain))
+	}
+
+	out, err := openslo.Marshal(docs)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(out)
+	return err
+}
+
+// getInfraClusterDomain returns the cluster's domain based on the API server URL of its infrastructure object
+func getInfraClusterDomain(ctx context.Context, c client.Client) (string, error) {
+	clusterInfra := configv1.Infrastructure{}
+	if err := c.Get(ctx, types.NamespacedName{Name: "cluster"}, &clusterInfra); err != nil {
+		return "", fmt.Errorf("failed to get the cluster domain: %w", err)
+	}
+	u, err := url.Parse(clusterInfra.Status.APIServerURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the cluster domain: %w", err)
+	}
+	return strings.TrimPrefix(u.Hostname(), "api."), nil
+}
+
+func runOpenSLOImport(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("openslo import", flag.ContinueOnError)
+	filename := flags.String("f", "-", "File containing the OpenSLO documents, '-' reads from stdin.")
+	namespace := flags.String("namespace", "", "Namespace of monitors whose SLO does not record one.")
+	clusterDomain := flags.String("cluster-domain", "", "Domain of the cluster, needed to import the SLOs not exported by the operator as ClusterUrlMonitors.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	in := stdin
+	if *filename != "-" {
+		f, err := os.Open(*filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	slos, slis, err := openslo.Unmarshal(in)
+	if err != nil {
+		return err
+	}
+
+	monitors := []client.Object{}
+	for _, sloDoc := range slos {
+		var sliDoc *openslo.SLI
+		if sli, ok := slis[sloDoc.Spec.IndicatorRef]; ok {
+			sliDoc = &sli
+		}
+		monitor, err := openslo.Import(sloDoc, sliDoc, *namespace, *clusterDomain)
+		if err != nil {
+			return err
+		}
+		monitors = append(monitors, monitor)
+	}
+	// only print the fields needed to apply the monitors
+	out, err := render.Marshal(monitors)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(out)
+	return err
+}
+
+const renderUsage = `Usage:
+  manager render -f <file> --context <file> [--alert-runbook-url <template>] [--alert-summary <template>] [--alert-description <template>]
+      Prints the ServiceMonitors and PrometheusRules the operator generates for RouteMonitors and ClusterUrlMonitors,
+      without a cluster. The context file provides what the operator looks up in the cluster:
+        routes:
+          openshift-console/console:
+            host: console-openshift-console.apps.example.devshift.org
+            insecure: false
+        clusterID: 1a2b3c4d
+        clusterDomain: example.devshift.org`
+
+func runRender(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(flags.Output(), renderUsage) }
+	filename := flags.String("f", "-", "File containing the RouteMonitors and ClusterUrlMonitors, '-' reads from stdin.")
+	contextFile := flags.String("context", "", "File describing the cluster the monitors are rendered for.")
+	alertDefaults := alert.Defaults{}
+	flags.StringVar(&alertDefaults.RunbookURL, "alert-runbook-url", "", "Default runbook_url annotation of the alerts, as configured on the operator.")
+	flags.StringVar(&alertDefaults.Summary, "alert-summary", "", "Default summary annotation of the alerts, as configured on the operator.")
+	flags.StringVar(&alertDefaults.Description, "alert-description", "", "Default description annotation of the alerts, as configured on the operator.")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *contextFile == "" {
+		return errors.New(renderUsage)
+	}
+	if err := alertDefaults.Validate(); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*contextFile)
+	if err != nil {
+		return err
+	}
+	renderContext, err := render.UnmarshalContext(data)
+	if err != nil {
+		return err
+	}
+	in := stdin
+	if *filename != "-" {
+		f, err := os.Open(*filename)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	monitors, err := render.Unmarshal(in)
+	if err != nil {
+		return err
+	}
+
+	objects := []client.Object{}
+	for _, monitor := range monitors {
+		rendered, err := render.Render(monitor, renderContext, alertDefaults)
+		if err != nil {
+			return fmt.Errorf("failed to render %s %s/%s: %w", monitor.GetObjectKind().GroupVersionKind().Kind, monitor.GetNamespace(), monitor.GetName(), err)
+		}
+		objects = append(objects, rendered...)
+	}
+	out, err := render.Marshal(objects)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(out)
+	return err
+}
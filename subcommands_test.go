@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testRenderContext = `routes:
+  openshift-console/console:
+    host: console-openshift-console.apps.example.devshift.org
+  openshift-monitoring/alertmanager-main:
+    host: alertmanager-main-openshift-monitoring.apps.example.devshift.org
+    insecure: true
+clusterID: test-cluster-id
+clusterDomain: example.devshift.org
+`
+
+const testRenderMonitors = `apiVersion: monitoring.openshift.io/v1alpha1
+kind: RouteMonitor
+metadata:
+  name: console
+  namespace: openshift-console
+spec:
+  route:
+    name: console
+    namespace: openshift-console
+    suffix: /health
+  slo:
+    targetAvailabilityPercent: "99.5"
+---
+apiVersion: monitoring.openshift.io/v1alpha1
+kind: RouteMonitor
+metadata:
+  name: alertmanager
+  namespace: openshift-monitoring
+spec:
+  route:
+    name: alertmanager-main
+    namespace: openshift-monitoring
+`
+
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunRender(t *testing.T) {
+	contextFile := writeTestFile(t, "context.yaml", testRenderContext)
+	stdout := &bytes.Buffer{}
+	if err := runRender([]string{"--context", contextFile}, strings.NewReader(testRenderMonitors), stdout); err != nil {
+		t.Fatal(err)
+	}
+	out := stdout.String()
+	for _, expected := range []string{
+		"https://console-openshift-console.apps.example.devshift.org/health",
+		"alertmanager-main-openshift-monitoring.apps.example.devshift.org",
+		"kind: PrometheusRule",
+	} {
+		if !strings.Contains(out, expected) {
+			t.Errorf("expected the output to contain %q:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "https://alertmanager-main") {
+		t.Errorf("expected the insecure Route to be probed over http:\n%s", out)
+	}
+}
+
+func TestRunRender_errors(t *testing.T) {
+	contextFile := writeTestFile(t, "context.yaml", "clusterID: test-cluster-id\n")
+	if err := runRender([]string{}, strings.NewReader(""), &bytes.Buffer{}); err == nil || !strings.Contains(err.Error(), "Usage") {
+		t.Errorf("expected the usage without a context file, got %v", err)
+	}
+	err := runRender([]string{"--context", contextFile}, strings.NewReader(testRenderMonitors), &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "openshift-console/console") {
+		t.Errorf("expected an error naming the Route missing from the context, got %v", err)
+	}
+}